@@ -0,0 +1,557 @@
+package tools
+
+// 泛型类型转换：Convert[T] 统一替代 ToInt/ToIntErr/ToUint32/ToFloat64 等一族函数。
+//
+// 统一规则：
+//   - 输入：各宽度整数/浮点数、bool、string/[]byte/[]rune、json.Number、time.Time、time.Duration、
+//     error 和 fmt.Stringer、以上类型的指针，以及以它们为底层类型的自定义类型
+//     （实现了 fmt.Stringer 的整数枚举：转为 string/[]byte 时取 String()，转为数字时取数值）
+//   - 溢出：超出目标类型范围一律返回 ErrConvertOverflow，不会静默截断或回绕
+//   - 取整：浮点数转整数向零截断（与 Go 的类型转换和原 ToX 函数一致），如 3.9 -> 3，-3.9 -> -3
+//   - NaN/Inf：可以原样转为浮点数；转为整数、bool、time.Duration 时返回 ErrConvertNaN
+//   - 字符串：去除首尾空白后按十进制整数、浮点数解析，空字符串返回 ErrConvertSyntax；
+//     默认只接受普通十进制写法，千分位、货币符号、百分号、全角和中文数字需通过 ConvertWith 开启（见 ConvertOptions）
+//   - 大数：Decimal、*big.Int、*big.Rat、*big.Float 可作为输入和目标类型，字符串与它们之间精确互转（见 decimal.go）
+//   - 时间：time.Time 转数字为 Unix 秒；字符串转 time.Time 按 ParseTime 自动识别格式，数字视为 Unix 时间戳
+//     （按数值大小识别秒、毫秒、微秒、纳秒）；time.Duration 与数字互转以纳秒为单位
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrConvertNil         = errors.New("convert: nil value")
+	ErrConvertUnsupported = errors.New("convert: unsupported type")
+	ErrConvertSyntax      = errors.New("convert: invalid syntax")
+	ErrConvertOverflow    = errors.New("convert: value out of range")
+	ErrConvertNaN         = errors.New("convert: NaN or Inf")
+)
+
+// ConvertError 描述一次失败的转换，Err 为上面的哨兵错误之一，可用 errors.Is 判断。
+type ConvertError struct {
+	Value  any    // 原始输入
+	Target string // 目标类型名
+	Err    error
+}
+
+func (e *ConvertError) Error() string {
+	return fmt.Sprintf("convert %T(%v) to %s: %v", e.Value, e.Value, e.Target, e.Err)
+}
+
+// Unwrap 支持 errors.Is 判断具体原因。
+func (e *ConvertError) Unwrap() error { return e.Err }
+
+// Convertible 是 Convert 支持的目标类型。time.Duration 属于 ~int64，按时长规则转换。
+type Convertible interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~bool | ~string | ~[]byte | time.Time |
+		Decimal | *big.Int | *big.Rat | *big.Float
+}
+
+// Convert 把任意值转换为 T，无法转换或超出范围时返回 *ConvertError。数字字符串按 DefaultConvertOptions 解析。
+//
+// 使用示例：
+//
+//	n, err := Convert[int32]("  42 ")      // 42, nil
+//	u, err := Convert[uint8](300)          // 0, ErrConvertOverflow
+//	d, err := Convert[time.Duration]("1m") // time.Minute, nil
+//	f, err := Convert[float64]("¥1,200.5") // 0, ErrConvertSyntax（需要 ConvertWith 与 LenientConvertOptions）
+func Convert[T Convertible](v any) (T, error) {
+	return ConvertWith[T](v, DefaultConvertOptions)
+}
+
+// ConvertWith 与 Convert 相同，但数字字符串按 opts 解析。
+//
+// 使用示例：
+//
+//	eu := ConvertOptions{ThousandSeps: ". ", DecimalSep: ','}
+//	f, err := ConvertWith[float64]("1.234,56", eu)                 // 1234.56, nil
+//	w, err := ConvertWith[int]("一万二千", LenientConvertOptions) // 12000, nil
+func ConvertWith[T Convertible](v any, opts ConvertOptions) (T, error) {
+	var zero T
+	rt := reflect.TypeFor[T]()
+
+	// 转为文本时优先使用 error/fmt.Stringer 的文本，转为其他类型时优先使用底层数值
+	textual := rt.Kind() == reflect.String || rt.Kind() == reflect.Slice
+	src, err := normalizeConvertSource(v, textual)
+	if err != nil {
+		return zero, &ConvertError{Value: v, Target: rt.String(), Err: err}
+	}
+
+	var out any
+	switch any(zero).(type) {
+	case time.Duration:
+		out, err = convertToDuration(src)
+	case time.Time:
+		out, err = convertToTime(src)
+	case Decimal:
+		out, err = convertToDecimal(src, opts)
+	case *big.Int:
+		out, err = convertToBigInt(src, opts)
+	case *big.Rat:
+		out, err = convertToBigRat(src, opts)
+	case *big.Float:
+		out, err = convertToBigFloat(src, opts)
+	default:
+		switch rt.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			// 数字字符串先按选项解析为数值，再统一做范围检查
+			switch val := src.(type) {
+			case string:
+				src, err = parseNumberString(val, opts)
+			case []byte:
+				src, err = parseNumberString(string(val), opts)
+			case Decimal, *big.Rat, *big.Float:
+				// 目标为整数时先精确截断，避免经过 float64 丢失精度
+				intTarget := rt.Kind() != reflect.Float32 && rt.Kind() != reflect.Float64
+				src, err = bigNumberSource(val, intTarget)
+			}
+		}
+		if err != nil {
+			break
+		}
+		switch rt.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out, err = convertToInt(src, rt.Bits())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			out, err = convertToUint(src, rt.Bits())
+		case reflect.Float32, reflect.Float64:
+			out, err = convertToFloat(src, rt.Bits())
+		case reflect.Bool:
+			out, err = convertToBool(src)
+		case reflect.String:
+			out, err = convertToString(src)
+		case reflect.Slice: // ~[]byte
+			out, err = convertToBytes(src)
+		}
+	}
+	if err != nil {
+		return zero, &ConvertError{Value: v, Target: rt.String(), Err: err}
+	}
+
+	if t, ok := out.(T); ok {
+		return t, nil
+	}
+	// 目标是自定义类型（如 type Level int），按底层类型转换
+	return reflect.ValueOf(out).Convert(rt).Interface().(T), nil
+}
+
+// MustConvert 与 Convert 相同，但转换失败时 panic。适用于输入确定合法的场景（如常量、配置默认值）。
+func MustConvert[T Convertible](v any) T {
+	t, err := Convert[T](v)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ConvertOr 与 Convert 相同，但转换失败时返回 def。
+func ConvertOr[T Convertible](v any, def T) T {
+	t, err := Convert[T](v)
+	if err != nil {
+		return def
+	}
+	return t
+}
+
+// =============================================================================
+// 输入归一化
+// =============================================================================
+
+// normalizeConvertSource 把输入归一化为以下类型之一：
+// bool、int64、uint64、float64、string、[]byte、time.Time、time.Duration，
+// 或无法归一化的原值（仅 string/[]byte 目标可能支持，如 map、切片）。
+//
+// 实现了 error/fmt.Stringer 的自定义类型（如以整数为底层类型的枚举）：textual 为 true 时取其文本，
+// 否则按底层类型取值。
+func normalizeConvertSource(v any, textual bool) (any, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, ErrConvertNil
+	case bool, int64, uint64, float64, string, []byte, time.Time, time.Duration:
+		return val, nil
+	case int:
+		return int64(val), nil
+	case int8:
+		return int64(val), nil
+	case int16:
+		return int64(val), nil
+	case int32:
+		return int64(val), nil
+	case uint:
+		return uint64(val), nil
+	case uint8:
+		return uint64(val), nil
+	case uint16:
+		return uint64(val), nil
+	case uint32:
+		return uint64(val), nil
+	case uintptr:
+		return uint64(val), nil
+	case float32:
+		return float64(val), nil
+	case []rune:
+		return string(val), nil
+	case json.Number:
+		return string(val), nil
+	case json.RawMessage:
+		return []byte(val), nil
+	case Decimal, *big.Rat, *big.Float:
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, ErrConvertNil
+		}
+		return val, nil
+	case *big.Int:
+		if val == nil {
+			return nil, ErrConvertNil
+		}
+		return new(big.Rat).SetInt(val), nil
+	}
+
+	if textual {
+		if s, ok := convertText(v); ok {
+			return s, nil
+		}
+	}
+
+	// 自定义类型按底层类型处理
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	}
+
+	if s, ok := convertText(v); ok {
+		return s, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, ErrConvertNil
+		}
+		return normalizeConvertSource(rv.Elem().Interface(), textual)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+	}
+	return v, nil
+}
+
+// convertText 返回 error 或 fmt.Stringer 的文本。nil 指针不调用方法，避免 panic。
+func convertText(v any) (string, bool) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return "", false
+	}
+	switch val := v.(type) {
+	case error:
+		return val.Error(), true
+	case fmt.Stringer:
+		return val.String(), true
+	}
+	return "", false
+}
+
+// parseConvertNumber 把字符串解析为 int64、uint64 或 float64（依次尝试）。
+func parseConvertNumber(s string) (any, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrConvertSyntax
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, ErrConvertOverflow
+		}
+		return nil, ErrConvertSyntax
+	}
+	return f, nil
+}
+
+// numericSource 把归一化后的输入转为数字（int64、uint64 或 float64）。
+func numericSource(src any) (any, error) {
+	switch val := src.(type) {
+	case int64, uint64, float64:
+		return val, nil
+	case bool:
+		if val {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case time.Duration:
+		return int64(val), nil
+	case time.Time:
+		return val.Unix(), nil
+	case string:
+		return parseConvertNumber(val)
+	case []byte:
+		return parseConvertNumber(string(val))
+	case Decimal, *big.Rat, *big.Float:
+		return bigNumberSource(val, false)
+	}
+	return nil, ErrConvertUnsupported
+}
+
+// =============================================================================
+// 各目标类型
+// =============================================================================
+
+// convertToInt 转换为 bits 位有符号整数，结果以 int64 返回（已做范围检查）。
+func convertToInt(src any, bits int) (int64, error) {
+	n, err := numericSource(src)
+	if err != nil {
+		return 0, err
+	}
+	lo, hi := int64(-1)<<(bits-1), int64(1)<<(bits-1)-1
+
+	switch val := n.(type) {
+	case int64:
+		if val < lo || val > hi {
+			return 0, ErrConvertOverflow
+		}
+		return val, nil
+	case uint64:
+		if val > uint64(hi) {
+			return 0, ErrConvertOverflow
+		}
+		return int64(val), nil
+	default:
+		f := n.(float64)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, ErrConvertNaN
+		}
+		// 向零截断；float64(hi)+1 恰为 2^(bits-1)，可精确表示
+		t := math.Trunc(f)
+		if t < float64(lo) || t >= float64(hi)+1 {
+			return 0, ErrConvertOverflow
+		}
+		return int64(t), nil
+	}
+}
+
+// convertToUint 转换为 bits 位无符号整数，结果以 uint64 返回（已做范围检查）。
+func convertToUint(src any, bits int) (uint64, error) {
+	n, err := numericSource(src)
+	if err != nil {
+		return 0, err
+	}
+	hi := uint64(math.MaxUint64) >> (64 - bits)
+
+	switch val := n.(type) {
+	case int64:
+		if val < 0 || uint64(val) > hi {
+			return 0, ErrConvertOverflow
+		}
+		return uint64(val), nil
+	case uint64:
+		if val > hi {
+			return 0, ErrConvertOverflow
+		}
+		return val, nil
+	default:
+		f := n.(float64)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, ErrConvertNaN
+		}
+		t := math.Trunc(f)
+		// float64(hi)+1 恰为 2^bits，可精确表示
+		if t < 0 || t >= float64(hi)+1 {
+			return 0, ErrConvertOverflow
+		}
+		return uint64(t), nil
+	}
+}
+
+// convertToFloat 转换为 bits 位浮点数，结果以 float64 返回。
+// 有限值超出 float32 范围时返回 ErrConvertOverflow；NaN/Inf 原样保留。
+func convertToFloat(src any, bits int) (float64, error) {
+	n, err := numericSource(src)
+	if err != nil {
+		return 0, err
+	}
+	var f float64
+	switch val := n.(type) {
+	case int64:
+		f = float64(val)
+	case uint64:
+		f = float64(val)
+	default:
+		f = n.(float64)
+	}
+	if bits == 32 && !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+		return 0, ErrConvertOverflow
+	}
+	return f, nil
+}
+
+// convertToBool 转换为 bool：数字非 0 为 true；字符串接受 true/false、1/0、yes/no、y/n、on/off（不区分大小写）。
+func convertToBool(src any) (bool, error) {
+	switch val := src.(type) {
+	case bool:
+		return val, nil
+	case string, []byte:
+		s, _ := val.(string)
+		if b, ok := val.([]byte); ok {
+			s = string(b)
+		}
+		b, err := parseBoolStr(s)
+		if err != nil {
+			return false, ErrConvertSyntax
+		}
+		return b, nil
+	case time.Time:
+		return false, ErrConvertUnsupported
+	}
+
+	n, err := numericSource(src)
+	if err != nil {
+		return false, err
+	}
+	switch val := n.(type) {
+	case int64:
+		return val != 0, nil
+	case uint64:
+		return val != 0, nil
+	default:
+		f := n.(float64)
+		if math.IsNaN(f) {
+			return false, ErrConvertNaN
+		}
+		return f != 0, nil
+	}
+}
+
+// convertToString 转换为 string，格式与 ToStrErr 一致：
+// 浮点数用最短表示且不使用科学计数法，time.Time 用 RFC3339Nano，time.Duration 用 String()，
+// []string 以逗号连接，map/结构体/其他切片编码为 JSON。
+func convertToString(src any) (string, error) {
+	switch val := src.(type) {
+	case string:
+		return val, nil
+	case []byte:
+		return string(val), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case uint64:
+		return strconv.FormatUint(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case time.Time:
+		return val.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return val.String(), nil
+	case []string:
+		return strings.Join(val, ","), nil
+	}
+	if s, ok := bigText(src); ok {
+		return s, nil
+	}
+
+	switch reflect.ValueOf(src).Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		b, err := json.Marshal(src)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrConvertUnsupported, err)
+		}
+		return string(b), nil
+	}
+	return "", ErrConvertUnsupported
+}
+
+// convertToBytes 转换为 []byte：[]byte 原样返回，其他类型按 convertToString 的格式转换。
+func convertToBytes(src any) ([]byte, error) {
+	if b, ok := src.([]byte); ok {
+		return b, nil
+	}
+	s, err := convertToString(src)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// convertToTime 转换为 time.Time：字符串按 ParseTime 的规则解析（不含时区时按本地时区），
+// 数字视为 Unix 时间戳并按数值大小识别秒、毫秒、微秒、纳秒。
+func convertToTime(src any) (time.Time, error) {
+	switch val := src.(type) {
+	case time.Time:
+		return val, nil
+	case time.Duration:
+		return time.Time{}, ErrConvertUnsupported
+	case string, []byte:
+		s, _ := val.(string)
+		if b, ok := val.([]byte); ok {
+			s = string(b)
+		}
+		t, err := ParseTime(s)
+		if err != nil {
+			return time.Time{}, ErrConvertSyntax
+		}
+		return t, nil
+	}
+
+	n, err := numericSource(src)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch val := n.(type) {
+	case int64:
+		return unixTime(val), nil
+	case uint64:
+		if val > math.MaxInt64 {
+			return time.Time{}, ErrConvertOverflow
+		}
+		return unixTime(int64(val)), nil
+	default:
+		return unixFloatTime(n.(float64))
+	}
+}
+
+// convertToDuration 转换为 time.Duration：字符串先按 time.ParseDuration 解析（如 "1h30m"），
+// 其次按数字解析；数字以纳秒为单位，浮点数向零截断。
+func convertToDuration(src any) (time.Duration, error) {
+	switch val := src.(type) {
+	case time.Duration:
+		return val, nil
+	case time.Time:
+		return 0, ErrConvertUnsupported
+	case string, []byte:
+		s, _ := val.(string)
+		if b, ok := val.([]byte); ok {
+			s = string(b)
+		}
+		if d, err := time.ParseDuration(strings.TrimSpace(s)); err == nil {
+			return d, nil
+		}
+	}
+	n, err := convertToInt(src, 64)
+	return time.Duration(n), err
+}
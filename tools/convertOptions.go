@@ -0,0 +1,368 @@
+package tools
+
+// 转换选项：按数据来源的书写习惯解析数字字符串，供 ConvertWith 使用。
+// Convert 与 ToInt/ToFloat64 等函数使用严格的 DefaultConvertOptions，宽松写法需要显式开启。
+//
+// 支持的写法（均可单独开关，LenientConvertOptions 全部开启）：
+//   - 千分位与小数点："1,234.56"；欧洲写法 "1.234,56" 需设置 ThousandSeps="." DecimalSep=','
+//   - 货币符号与代码："¥1,200"、"$3.5"、"1200元"、"RMB 99"
+//   - 百分号与千分号："12%" -> 0.12、"5‰" -> 0.005
+//   - 全角字符："１２３．４５"、"－１，２００"
+//   - 中文数字："一万二千" -> 12000、"两百零五"、"壹仟贰佰"、"三点一四"、"1.5亿"、"负五"
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ConvertOptions 配置数字字符串的解析方式，只对转为整数、浮点数、Decimal 和 big 类型的字符串输入生效。
+// 零值表示只接受普通的十进制写法。
+type ConvertOptions struct {
+	// ThousandSeps 是千分位分隔符集合（可包含多个字符，如 ", '"）。
+	// 出现分隔符时整数部分必须按每 3 位分组，否则视为格式错误（避免 "1,5" 被误读为 15）。
+	ThousandSeps string
+
+	// DecimalSep 是小数点字符，0 表示 '.'。
+	DecimalSep rune
+
+	// Currency 为 true 时去除首尾的货币符号和代码（¥ ￥ $ € £ 元 CNY RMB USD 等）。
+	Currency bool
+
+	// Percent 为 true 时支持 "%"（除以 100）和 "‰"（除以 1000）后缀，结果为浮点数。
+	Percent bool
+
+	// FullWidth 为 true 时先把全角数字、符号和空格转为半角。
+	FullWidth bool
+
+	// ChineseNumerals 为 true 时支持中文数字（小写、大写以及与阿拉伯数字混写，如 "1.2万"）。
+	ChineseNumerals bool
+}
+
+// DefaultConvertOptions 是 Convert、Decode 及 ToInt/ToFloat64 等函数使用的默认选项：
+// 只接受普通的十进制写法（"." 为小数点，不含千分位、货币符号、百分号、全角和中文数字），
+// 与这些函数原有的行为一致。需要宽松解析时使用 ConvertWith 并传入 LenientConvertOptions 或自定义选项。
+//
+// 可在程序启动时整体替换以适配数据源，运行期间修改不是并发安全的。
+var DefaultConvertOptions = ConvertOptions{DecimalSep: '.'}
+
+// LenientConvertOptions 以 "," 为千分位、"." 为小数点，开启全部宽松解析，供 ConvertWith 按需使用。
+//
+// 使用示例：
+//
+//	n, err := ConvertWith[int]("¥1,200", LenientConvertOptions) // 1200, nil
+var LenientConvertOptions = ConvertOptions{
+	ThousandSeps:    ",",
+	DecimalSep:      '.',
+	Currency:        true,
+	Percent:         true,
+	FullWidth:       true,
+	ChineseNumerals: true,
+}
+
+// currencyAffixes 是 Currency 选项去除的货币符号和代码，较长的写在前面以优先匹配。
+var currencyAffixes = []string{
+	"US$", "HK$", "NT$", "CNY", "RMB", "USD", "EUR", "GBP", "JPY", "HKD",
+	"人民币", "美元", "元", "¥", "￥", "$", "€", "£",
+}
+
+// parseNumberString 按 opts 把字符串解析为 int64、uint64 或 float64。
+func parseNumberString(s string, opts ConvertOptions) (any, error) {
+	c, err := canonicalNumber(s, opts)
+	if err != nil {
+		return nil, err
+	}
+	return parseConvertNumber(c)
+}
+
+// canonicalNumber 按 opts 把字符串规整为普通十进制写法（如 "-1234.56"，百分比为 "12e-2"），
+// 不经过浮点数，供 strconv 和 Decimal/big.Rat 精确解析。
+func canonicalNumber(s string, opts ConvertOptions) (string, error) {
+	s = strings.TrimSpace(s)
+	if opts.FullWidth {
+		s = strings.TrimSpace(toHalfWidth(s))
+	}
+
+	neg := false
+	takeSign := func() {
+		if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+			neg = neg != (s[0] == '-')
+			s = strings.TrimSpace(s[1:])
+		}
+	}
+	takeSign()
+
+	if opts.Currency {
+		s = stripCurrency(s)
+		takeSign() // "¥-1,200" 的写法
+	}
+
+	exp := 0
+	if opts.Percent {
+		if rest, ok := strings.CutSuffix(s, "%"); ok {
+			s, exp = strings.TrimSpace(rest), -2
+		} else if rest, ok := strings.CutSuffix(s, "‰"); ok {
+			s, exp = strings.TrimSpace(rest), -3
+		}
+	}
+
+	var c string
+	if opts.ChineseNumerals && hasChineseNumeral(s) {
+		var err error
+		if c, err = parseChineseNumber(s, opts); err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		if c, err = parseSeparatedNumber(s, opts); err != nil {
+			return "", err
+		}
+	}
+
+	if exp != 0 {
+		if strings.ContainsAny(c, "eE") {
+			return "", ErrConvertSyntax
+		}
+		c += "e" + strconv.Itoa(exp)
+	}
+	if neg {
+		if rest, ok := strings.CutPrefix(c, "-"); ok {
+			c = rest // "-负五"
+		} else {
+			c = "-" + c
+		}
+	}
+	return c, nil
+}
+
+// parseSeparatedNumber 去除千分位并把小数点换为 '.'，返回普通十进制写法。符号已由调用方处理。
+func parseSeparatedNumber(s string, opts ConvertOptions) (string, error) {
+	dec := opts.DecimalSep
+	if dec == 0 {
+		dec = '.'
+	}
+	intPart, frac, hasFrac := strings.Cut(s, string(dec))
+
+	if opts.ThousandSeps != "" && strings.ContainsAny(intPart, opts.ThousandSeps) {
+		var groups []string
+		start := 0
+		for i, r := range intPart {
+			if strings.ContainsRune(opts.ThousandSeps, r) {
+				groups = append(groups, intPart[start:i])
+				start = i + utf8.RuneLen(r)
+			}
+		}
+		groups = append(groups, intPart[start:])
+		// 首组 1-3 位、其余每组恰好 3 位
+		for i, g := range groups {
+			if g == "" || (i == 0 && len(g) > 3) || (i > 0 && len(g) != 3) {
+				return "", ErrConvertSyntax
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	if hasFrac {
+		if dec != '.' && strings.Contains(intPart, ".") {
+			return "", ErrConvertSyntax
+		}
+		s = intPart + "." + frac
+	} else {
+		s = intPart
+	}
+	if s == "" || strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return "", ErrConvertSyntax // 符号只允许出现一次，且在最前面
+	}
+	return s, nil
+}
+
+// stripCurrency 去除首尾的货币符号和代码（代码不区分大小写）。
+func stripCurrency(s string) string {
+	for changed := true; changed; {
+		changed = false
+		for _, c := range currencyAffixes {
+			if len(s) >= len(c) && strings.EqualFold(s[:len(c)], c) {
+				s, changed = strings.TrimSpace(s[len(c):]), true
+			}
+			if len(s) >= len(c) && strings.EqualFold(s[len(s)-len(c):], c) {
+				s, changed = strings.TrimSpace(s[:len(s)-len(c)]), true
+			}
+		}
+	}
+	return s
+}
+
+// toHalfWidth 把全角 ASCII 字符（U+FF01-U+FF5E）和全角空格转为半角，中文句号"。"不做转换。
+func toHalfWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xFEE0
+		}
+		return r
+	}, s)
+}
+
+// =============================================================================
+// 中文数字
+// =============================================================================
+
+var chineseDigits = map[rune]int{
+	'零': 0, '〇': 0,
+	'一': 1, '壹': 1, '幺': 1,
+	'二': 2, '贰': 2, '貳': 2, '两': 2, '兩': 2,
+	'三': 3, '叁': 3, '參': 3,
+	'四': 4, '肆': 4,
+	'五': 5, '伍': 5,
+	'六': 6, '陆': 6, '陸': 6,
+	'七': 7, '柒': 7,
+	'八': 8, '捌': 8,
+	'九': 9, '玖': 9,
+}
+
+var chineseSmallUnits = map[rune]int64{'十': 10, '拾': 10, '百': 100, '佰': 100, '千': 1000, '仟': 1000}
+
+var chineseBigUnits = map[rune]int64{'万': 1e4, '萬': 1e4, '亿': 1e8, '億': 1e8}
+
+// hasChineseNumeral 判断字符串是否包含中文数字、单位或"负""点"。
+func hasChineseNumeral(s string) bool {
+	for _, r := range s {
+		if _, ok := chineseDigits[r]; ok {
+			return true
+		}
+		if _, ok := chineseSmallUnits[r]; ok {
+			return true
+		}
+		if _, ok := chineseBigUnits[r]; ok {
+			return true
+		}
+		if r == '负' || r == '負' || r == '点' || r == '點' {
+			return true
+		}
+	}
+	return false
+}
+
+// parseChineseNumber 解析中文数字，可与阿拉伯数字混写（如 "1.2万"、"3千5百"），返回精确的十进制文本。
+// 计算全程使用 big.Rat，"九千九百九十九万九千九百九十九亿……" 这样超过 float64 精度的数也不会失真。
+func parseChineseNumber(s string, opts ConvertOptions) (string, error) {
+	neg := false
+	if rest, ok := strings.CutPrefix(s, "负"); ok {
+		neg, s = true, rest
+	} else if rest, ok := strings.CutPrefix(s, "負"); ok {
+		neg, s = true, rest
+	}
+
+	var (
+		total, section = new(big.Rat), new(big.Rat) // 已确定的部分（亿/万之上）、当前万以内的小节
+		number         = new(big.Rat)               // 尚未乘单位的数字
+		hasNumber      bool                         // number 是否来自刚读到的数字
+		fracScale      *big.Rat                     // 不为 nil 表示正在读小数部分，值为当前位的权重
+		empty          = true
+		tmp            = new(big.Rat)
+	)
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		// "点" 之后的阿拉伯数字与中文数字一样逐位作为小数（"3点5"）
+		if r >= '0' && r <= '9' && fracScale != nil {
+			number.Add(number, tmp.Mul(tmp.SetInt64(int64(r-'0')), fracScale))
+			fracScale.Quo(fracScale, big.NewRat(10, 1))
+			continue
+		}
+		// 阿拉伯数字串（可带小数点和千分位）整体解析
+		if r >= '0' && r <= '9' {
+			if hasNumber {
+				return "", ErrConvertSyntax // "五5" 无法确定数位
+			}
+			j := i
+			for j < len(runes) && (runes[j] >= '0' && runes[j] <= '9' || runes[j] == '.' ||
+				runes[j] == opts.DecimalSep || strings.ContainsRune(opts.ThousandSeps, runes[j])) {
+				j++
+			}
+			c, err := parseSeparatedNumber(string(runes[i:j]), opts)
+			if err != nil {
+				return "", err
+			}
+			if _, ok := number.SetString(c); !ok {
+				return "", ErrConvertSyntax
+			}
+			hasNumber, empty = true, false
+			i = j - 1
+			continue
+		}
+
+		if d, ok := chineseDigits[r]; ok {
+			switch {
+			case fracScale != nil:
+				number.Add(number, tmp.Mul(tmp.SetInt64(int64(d)), fracScale))
+				fracScale.Quo(fracScale, big.NewRat(10, 1))
+			case hasNumber:
+				number.Add(number.Mul(number, big.NewRat(10, 1)), tmp.SetInt64(int64(d)))
+			default:
+				number.SetInt64(int64(d))
+			}
+			hasNumber, empty = true, false
+			continue
+		}
+		if fracScale != nil {
+			return "", ErrConvertSyntax // 小数部分之后只允许数字
+		}
+
+		if u, ok := chineseSmallUnits[r]; ok {
+			if !hasNumber {
+				number.SetInt64(1) // "十二" 中的十
+			}
+			section.Add(section, number.Mul(number, tmp.SetInt64(u)))
+			number.SetInt64(0)
+			hasNumber, empty = false, false
+			continue
+		}
+		if u, ok := chineseBigUnits[r]; ok {
+			if empty {
+				return "", ErrConvertSyntax
+			}
+			section.Add(section, number)
+			if u == 1e8 {
+				total.Add(total, section)
+				total.Mul(total, tmp.SetInt64(u))
+			} else {
+				total.Add(total, section.Mul(section, tmp.SetInt64(u)))
+			}
+			section, number = new(big.Rat), new(big.Rat)
+			hasNumber = false
+			continue
+		}
+		if r == '点' || r == '點' {
+			// "十二点五"：小数点前的小节并入 number
+			number.Add(number, section)
+			section = new(big.Rat)
+			fracScale, hasNumber = big.NewRat(1, 10), true
+			continue
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		return "", ErrConvertSyntax
+	}
+	if empty {
+		return "", ErrConvertSyntax
+	}
+
+	total.Add(total, section)
+	total.Add(total, number)
+	d, err := ratToDecimal(total) // 只含十进制小数和整数单位，必然可以精确表示
+	if err != nil {
+		return "", ErrConvertSyntax
+	}
+	c := d.String()
+	if neg && d.Sign() != 0 {
+		c = "-" + c
+	}
+	return c, nil
+}
@@ -0,0 +1,277 @@
+package tools
+
+// 类型转换示例，覆盖以下场景：
+//   - 示例1：溢出、NaN/Inf、浮点数截断与自定义类型
+//   - 示例2：时间与时长的转换规则
+//   - 示例3：ToX 兼容函数、ConvertOr 与 MustConvert
+//   - 示例4：按地区解析数字（千分位、小数点、货币、百分号、全角与中文数字）
+//   - 示例5：Decimal 的各种舍入模式与运算
+//   - 示例6：字符串与 Decimal、*big.Int、*big.Rat、*big.Float 之间的精确往返
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// =============================================================================
+// 示例 1：溢出、NaN/Inf、浮点数截断与自定义类型
+// =============================================================================
+
+// Level 是以 int 为底层类型的自定义类型，Convert 按底层类型转换。
+type Level int
+
+func Example_convertRules() {
+	// 超出目标类型范围时返回 ErrConvertOverflow，不会回绕
+	_, err := Convert[uint8](300)
+	fmt.Println("uint8(300):", errors.Is(err, ErrConvertOverflow))
+	_, err = Convert[uint32](-1)
+	fmt.Println("uint32(-1):", errors.Is(err, ErrConvertOverflow))
+	_, err = Convert[int8]("128")
+	fmt.Println(`int8("128"):`, errors.Is(err, ErrConvertOverflow))
+
+	// NaN/Inf 可以原样转为浮点数，转为整数或 bool 时报错
+	f, _ := Convert[float64](math.Inf(1))
+	fmt.Println("float64(+Inf):", f)
+	_, err = Convert[int](math.NaN())
+	fmt.Println("int(NaN):", errors.Is(err, ErrConvertNaN))
+
+	// 浮点数转整数向零截断
+	a, _ := Convert[int](3.9)
+	b, _ := Convert[int]("-3.9")
+	fmt.Println("截断:", a, b)
+
+	// 字符串去除首尾空白；空字符串和非法字符串返回 ErrConvertSyntax
+	n, _ := Convert[int32]("  42 ")
+	_, err = Convert[int]("")
+	fmt.Println("空白:", n, errors.Is(err, ErrConvertSyntax))
+
+	// 自定义类型与 *ConvertError
+	lv, _ := Convert[Level]("3")
+	_, err = Convert[Level]("high")
+	var ce *ConvertError
+	fmt.Println("Level:", lv, errors.As(err, &ce), ce.Target)
+
+	// Output:
+	// uint8(300): true
+	// uint32(-1): true
+	// int8("128"): true
+	// float64(+Inf): +Inf
+	// int(NaN): true
+	// 截断: 3 -3
+	// 空白: 42 true
+	// Level: 3 true tools.Level
+}
+
+// =============================================================================
+// 示例 2：时间与时长的转换规则
+// =============================================================================
+
+func Example_convertTime() {
+	// 数字视为 Unix 时间戳，按数值大小识别秒、毫秒、微秒、纳秒
+	for _, ts := range []int64{1700000000, 1700000000123, 1700000000123456} {
+		t, _ := Convert[time.Time](ts)
+		fmt.Println(t.UTC().Format(time.RFC3339Nano))
+	}
+
+	// 字符串按 ParseTime 识别格式；带时区的字符串保留其时区
+	t, _ := Convert[time.Time]("2024-01-02T03:04:05+08:00")
+	fmt.Println(t.Format(time.RFC3339))
+
+	// time.Time 转数字为 Unix 秒，转字符串为 RFC3339Nano
+	sec, _ := Convert[int64](t)
+	s, _ := Convert[string](t)
+	fmt.Println(sec, s)
+
+	// time.Duration：字符串按 time.ParseDuration 解析，数字以纳秒为单位
+	d1, _ := Convert[time.Duration]("1h30m")
+	d2, _ := Convert[time.Duration](1500)
+	_, err := Convert[time.Duration](math.Inf(1))
+	fmt.Println(d1, d2, errors.Is(err, ErrConvertNaN))
+
+	// Output:
+	// 2023-11-14T22:13:20Z
+	// 2023-11-14T22:13:20.123Z
+	// 2023-11-14T22:13:20.123456Z
+	// 2024-01-02T03:04:05+08:00
+	// 1704135845 2024-01-02T03:04:05+08:00
+	// 1h30m0s 1.5µs true
+}
+
+// =============================================================================
+// 示例 3：ToX 兼容函数、ConvertOr 与 MustConvert
+// =============================================================================
+
+func Example_convertHelpers() {
+	// ToX 无法转换时返回零值，ToXErr 返回错误
+	fmt.Println(ToInt("12"), ToInt("abc"), ToUint32(-5))
+	_, err := ToUint32Err(-5)
+	fmt.Println(errors.Is(err, ErrConvertOverflow))
+
+	// bool 接受 true/false、1/0、yes/no、on/off
+	fmt.Println(ToBool("yes"), ToBool("off"), ToBool(2))
+
+	// ConvertOr 失败时返回默认值；MustConvert 适用于确定合法的输入，失败时 panic
+	fmt.Println(ConvertOr[int]("n/a", -1), MustConvert[float64]("2.5"))
+
+	// 转为 string：浮点数不使用科学计数法
+	fmt.Println(ToStr(1e21), ToStr(0.000001))
+
+	// Output:
+	// 12 0 0
+	// true
+	// true false true
+	// -1 2.5
+	// 1000000000000000000000 0.000001
+}
+
+// =============================================================================
+// 示例 4：按地区解析数字（千分位、小数点、货币、百分号、全角与中文数字）
+// =============================================================================
+
+func Example_convertOptions() {
+	// LenientConvertOptions：千分位 ","、小数点 "."，并开启货币、百分号、全角和中文数字
+	for _, s := range []string{"1,234.56", "¥1,200", "12%", "１２３．５", "一万二千", "负三点五"} {
+		f, err := ConvertWith[float64](s, LenientConvertOptions)
+		fmt.Printf("%s -> %v %v\n", s, f, err)
+	}
+
+	// 千分位必须每 3 位分组，避免 "1,5" 被误读为 15
+	_, err := ConvertWith[float64]("1,5", LenientConvertOptions)
+	fmt.Println(`"1,5":`, errors.Is(err, ErrConvertSyntax))
+
+	// 欧洲写法："." 或空格为千分位、"," 为小数点
+	eu := ConvertOptions{ThousandSeps: ". ", DecimalSep: ','}
+	for _, s := range []string{"1.234,56", "1 234,5"} {
+		f, err := ConvertWith[float64](s, eu)
+		fmt.Printf("%s -> %v %v\n", s, f, err)
+	}
+
+	// Convert 与 ToX 使用严格的 DefaultConvertOptions，只接受普通十进制写法
+	_, err = Convert[int]("¥1,200")
+	fmt.Println("严格模式:", errors.Is(err, ErrConvertSyntax), ToInt("二"), ToInt64("1,234"), ToFloat64("12%"))
+
+	// Output:
+	// 1,234.56 -> 1234.56 <nil>
+	// ¥1,200 -> 1200 <nil>
+	// 12% -> 0.12 <nil>
+	// １２３．５ -> 123.5 <nil>
+	// 一万二千 -> 12000 <nil>
+	// 负三点五 -> -3.5 <nil>
+	// "1,5": true
+	// 1.234,56 -> 1234.56 <nil>
+	// 1 234,5 -> 1234.5 <nil>
+	// 严格模式: true 0 0 0
+}
+
+// =============================================================================
+// 示例 5：Decimal 的舍入模式与运算
+// =============================================================================
+
+func Example_decimalRounding() {
+	modes := []struct {
+		name string
+		mode RoundingMode
+	}{
+		{"HalfUp", RoundHalfUp}, {"HalfEven", RoundHalfEven}, {"HalfDown", RoundHalfDown},
+		{"Up", RoundUp}, {"Down", RoundDown}, {"Ceiling", RoundCeiling}, {"Floor", RoundFloor},
+	}
+	inputs := []string{"2.5", "3.5", "2.51", "-2.5", "-2.1"}
+
+	fmt.Printf("%-9s", "")
+	for _, in := range inputs {
+		fmt.Printf("%6s", in)
+	}
+	fmt.Println()
+	for _, m := range modes {
+		fmt.Printf("%-9s", m.name)
+		for _, in := range inputs {
+			fmt.Printf("%6s", MustParseDecimal(in).Round(0, m.mode))
+		}
+		fmt.Println()
+	}
+
+	// 运算结果精确，Add/Sub 保留较多的小数位数，Mul 的小数位数为两者之和
+	price, qty := MustParseDecimal("19.99"), MustParseDecimal("3")
+	total, _ := price.Mul(qty)
+	fmt.Println(total, MustParseDecimal("0.1").Add(MustParseDecimal("0.20")))
+
+	// Div 需要指定位数和舍入模式；除以 0 返回错误
+	share, _ := MustParseDecimal("100").Div(qty, 2, RoundHalfEven)
+	_, err := share.Div(Decimal{}, 2, RoundHalfUp)
+	fmt.Println(share, errors.Is(err, ErrDivisionByZero))
+
+	// Round 可以补 0；Cmp 忽略小数位数
+	fmt.Println(MustParseDecimal("1.5").Round(2, RoundHalfUp), MustParseDecimal("12.30").Cmp(MustParseDecimal("12.3")))
+
+	// Output:
+	//             2.5   3.5  2.51  -2.5  -2.1
+	// HalfUp        3     4     3    -3    -2
+	// HalfEven      2     4     3    -2    -2
+	// HalfDown      2     3     3    -2    -2
+	// Up            3     4     3    -3    -3
+	// Down          2     3     2    -2    -2
+	// Ceiling       3     4     3    -2    -2
+	// Floor         2     3     2    -3    -3
+	// 59.97 0.30
+	// 33.33 true
+	// 1.50 0
+}
+
+// =============================================================================
+// 示例 6：精确往返
+// =============================================================================
+
+func Example_decimalRoundTrip() {
+	// 保留原文的小数位数，超过 float64 精度的数字也不会失真
+	for _, s := range []string{"12.30", "-0.000000000000000001", "123456789012345678901234567890.123456789"} {
+		d, _ := ParseDecimal(s)
+		text, _ := d.MarshalText()
+		var back Decimal
+		_ = back.UnmarshalText(text)
+		fmt.Println(d, back.Cmp(d) == 0 && back.Scale() == d.Scale())
+	}
+
+	// 16 位以上的中文金额按十进制精确计算
+	amount, _ := ConvertWith[Decimal]("九千九百九十九万九千九百九十九亿九千九百九十九万九千九百九十九点九九元", LenientConvertOptions)
+	fmt.Println(amount)
+
+	// JSON 字符串只接受 String 输出的写法，JSON 数字可以带指数
+	var j Decimal
+	fmt.Println(json.Unmarshal([]byte(`"12.5%"`), &j) != nil, json.Unmarshal([]byte(`"二"`), &j) != nil)
+	_ = json.Unmarshal([]byte(`1.25e2`), &j)
+	fmt.Println(j)
+
+	// 与 math/big 类型互相转换
+	bi, _ := Convert[*big.Int]("123456789012345678901234567890")
+	s1, _ := Convert[string](bi)
+	r, _ := Convert[*big.Rat]("1/8")
+	d1, _ := Convert[Decimal](r)
+	f, _ := Convert[*big.Float]("0.1")
+	d2, _ := Convert[Decimal](f)
+	fmt.Println(s1, d1, d2)
+
+	// 1/3 无法用有限位小数表示，需要 DecimalFromRat 指定位数
+	_, err := Convert[Decimal](big.NewRat(1, 3))
+	fmt.Println(errors.Is(err, ErrConvertInexact), DecimalFromRat(big.NewRat(1, 3), 4, RoundHalfUp))
+
+	// float64 按最短十进制表示转换；超出目标范围返回 ErrConvertOverflow
+	d3, _ := Convert[Decimal](0.1)
+	_, err = Convert[int64](MustParseDecimal("9223372036854775808"))
+	_, err2 := ParseDecimal("1e100000")
+	fmt.Println(d3, errors.Is(err, ErrConvertOverflow), errors.Is(err2, ErrConvertOverflow))
+
+	// Output:
+	// 12.30 true
+	// -0.000000000000000001 true
+	// 123456789012345678901234567890.123456789 true
+	// 9999999999999999.99
+	// true true
+	// 125
+	// 123456789012345678901234567890 0.125 0.1
+	// true 0.3333
+	// 0.1 true true
+}
@@ -0,0 +1,359 @@
+package tools
+
+// 流式 CSV 读取：按 RFC 4180 处理引号（字段内可含分隔符、引号和换行），逐行读取，内存占用与文件大小无关。
+//
+//   - 编码：有 BOM 时按 BOM（UTF-8、UTF-16LE/BE）解码并去除 BOM；否则合法 UTF-8 直接读取，
+//     其余用与 FileToUTF8 相同的 chardet 检测（GBK、Big5、Shift-JIS 等），也可用 Encoding 指定
+//   - 分隔符：默认从前几行自动识别 ',' '\t' ';' '|'，也可用 Delimiter 指定
+//   - 表头：第一行作为表头，记录可按列名取值（CSVRecord.Get）或解码到结构体（CSVRecord.Decode）
+//   - 错误：格式错误的行返回 *csv.ParseError，其中带行号和列号；列数与表头不一致也会报告行号
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// csvSniffSize 是检测编码和分隔符时预读的字节数。
+const csvSniffSize = 64 * 1024
+
+// csvDelimiterCandidates 是自动识别的分隔符，按优先级排列。
+var csvDelimiterCandidates = []rune{',', '\t', ';', '|'}
+
+// CSVReaderOptions 配置 CSV 读取。
+type CSVReaderOptions struct {
+	// Delimiter 是字段分隔符。
+	// 默认值：0，从前几行自动识别 ',' '\t' ';' '|'，无法识别时为 ','。
+	Delimiter rune
+
+	// Encoding 是源编码名称（如 "GBK"、"UTF-16LE"，见 EncodeConvert 支持的编码）。
+	// 默认值：""，按 BOM 和内容自动检测。
+	Encoding string
+
+	// NoHeader 为 true 时第一行也作为数据，此时只能按下标取值。
+	NoHeader bool
+
+	// TrimSpace 为 true 时去除每个字段（含表头）的首尾空白。
+	TrimSpace bool
+
+	// LazyQuotes 为 true 时容忍不规范的引号（非引号字段中出现引号、引号字段中出现未转义的引号）。
+	LazyQuotes bool
+
+	// AllowRagged 为 true 时允许各行列数不同（缺失的列按空值处理）；
+	// 默认列数与第一行不一致时返回 *csv.ParseError（Err 为 csv.ErrFieldCount），记录仍会返回，可以继续读取。
+	AllowRagged bool
+
+	// Comment 不为 0 时，以该字符开头的行视为注释跳过。
+	Comment rune
+}
+
+// CSVReader 是流式 CSV 读取器，不是并发安全的。
+type CSVReader struct {
+	r         *csv.Reader
+	closer    io.Closer // OpenCSV 打开的文件
+	header    []string
+	index     map[string]int
+	delimiter rune
+	encoding  string
+	trim      bool
+	pending   *CSVRecord // 无表头时用于识别的第一行
+}
+
+// CSVRecord 是一行数据。
+type CSVRecord struct {
+	Line   int      // 该行在文件中的起始行号（从 1 开始，表头为第 1 行）
+	Fields []string // 各列的值
+
+	header []string
+	index  map[string]int
+}
+
+// NewCSVReader 创建流式读取器并读取表头（NoHeader 为 false 时）。
+// 空输入返回 io.EOF。
+//
+// 使用示例：
+//
+//	cr, err := NewCSVReader(resp.Body, CSVReaderOptions{})
+//	if err != nil { ... }
+//	for rec, err := range cr.Records() {
+//	    if err != nil { log.Println(err); continue } // 如 "record on line 12: wrong number of fields"
+//	    fmt.Println(rec.Get("name"), rec.Get("age"))
+//	}
+func NewCSVReader(r io.Reader, opts CSVReaderOptions) (*CSVReader, error) {
+	decoded, encName, err := newCSVDecodeReader(r, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(decoded, csvSniffSize)
+	delim := opts.Delimiter
+	if delim == 0 {
+		sample, _ := br.Peek(csvSniffSize)
+		delim = sniffCSVDelimiter(sample)
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = delim
+	cr.LazyQuotes = opts.LazyQuotes
+	cr.Comment = opts.Comment
+	if opts.AllowRagged {
+		cr.FieldsPerRecord = -1
+	}
+
+	c := &CSVReader{r: cr, delimiter: delim, encoding: encName, trim: opts.TrimSpace}
+	first, err := c.next()
+	if err != nil && first == nil {
+		return nil, err
+	}
+	if opts.NoHeader {
+		c.pending = first
+		return c, err
+	}
+
+	c.header = first.Fields
+	c.index = csvHeaderIndex(c.header)
+	return c, nil
+}
+
+// csvHeaderIndex 建立列名到下标的映射，重复的列名按第一次出现的位置取值。
+func csvHeaderIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, dup := index[name]; !dup {
+			index[name] = i
+		}
+	}
+	return index
+}
+
+// OpenCSV 打开文件并创建流式读取器，用完需调用 Close。
+func OpenCSV(path string, opts CSVReaderOptions) (*CSVReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCSVReader(f, opts)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("read csv %s: %w", path, err)
+	}
+	c.closer = f
+	return c, nil
+}
+
+// Close 关闭 OpenCSV 打开的文件；NewCSVReader 创建的读取器不关闭底层 io.Reader。
+func (c *CSVReader) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+// Header 返回表头（NoHeader 时为 nil）。调用方不得修改。
+func (c *CSVReader) Header() []string { return c.header }
+
+// Delimiter 返回使用的分隔符（指定的或自动识别的）。
+func (c *CSVReader) Delimiter() rune { return c.delimiter }
+
+// Encoding 返回源编码名称，如 "UTF-8"、"UTF-16LE"、"GB-18030"。
+func (c *CSVReader) Encoding() string { return c.encoding }
+
+// Read 读取下一行，读完返回 io.EOF。
+// 列数与第一行不一致时同时返回记录和 *csv.ParseError；其他格式错误只返回错误，可以继续读取下一行。
+func (c *CSVReader) Read() (*CSVRecord, error) {
+	if rec := c.pending; rec != nil {
+		c.pending = nil
+		return rec, nil
+	}
+	return c.next()
+}
+
+// Records 以迭代器逐行产出记录和错误，读完（io.EOF）时结束；错误不会终止迭代，由调用方决定是否 break。
+func (c *CSVReader) Records() iter.Seq2[*CSVRecord, error] {
+	return func(yield func(*CSVRecord, error) bool) {
+		for {
+			rec, err := c.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(rec, err) {
+				return
+			}
+			// 非格式错误（如底层读取失败）无法继续
+			var pe *csv.ParseError
+			if err != nil && !errors.As(err, &pe) {
+				return
+			}
+		}
+	}
+}
+
+// next 从 csv.Reader 读取一行并填充行号。
+func (c *CSVReader) next() (*CSVRecord, error) {
+	fields, err := c.r.Read()
+	if fields == nil || (err != nil && !errors.Is(err, csv.ErrFieldCount)) {
+		return nil, err // 不完整的行不返回，避免误用残缺数据
+	}
+	line, _ := c.r.FieldPos(0)
+	if c.trim {
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+	}
+	return &CSVRecord{Line: line, Fields: fields, header: c.header, index: c.index}, err
+}
+
+// Header 返回该行对应的表头（NoHeader 时为 nil）。调用方不得修改。
+func (rec *CSVRecord) Header() []string { return rec.header }
+
+// Lookup 按列名取值，列不存在或该行缺少该列时 ok 为 false。
+func (rec *CSVRecord) Lookup(name string) (value string, ok bool) {
+	i, ok := rec.index[name]
+	if !ok || i >= len(rec.Fields) {
+		return "", false
+	}
+	return rec.Fields[i], true
+}
+
+// Get 按列名取值，列不存在时返回空字符串。
+func (rec *CSVRecord) Get(name string) string {
+	v, _ := rec.Lookup(name)
+	return v
+}
+
+// Map 返回列名到值的映射（缺失的列不包含在内）。
+func (rec *CSVRecord) Map() map[string]string {
+	m := make(map[string]string, len(rec.index))
+	for name := range rec.index {
+		if v, ok := rec.Lookup(name); ok {
+			m[name] = v
+		}
+	}
+	return m
+}
+
+// Decode 按表头把该行解码到结构体，规则见 DecodeCSVRow。
+func (rec *CSVRecord) Decode(dst any) error {
+	if err := DecodeCSVRow(rec.Fields, rec.index, dst); err != nil {
+		return fmt.Errorf("line %d: %w", rec.Line, err)
+	}
+	return nil
+}
+
+// newCSVDecodeReader 返回解码为 UTF-8（并去除 BOM）的 Reader 和源编码名称。
+func newCSVDecodeReader(r io.Reader, name string) (io.Reader, string, error) {
+	if name != "" {
+		enc := detectEncodingByName(name)
+		if enc == nil {
+			return nil, "", fmt.Errorf("unsupported encoding: %s", name)
+		}
+		return transform.NewReader(r, xunicode.BOMOverride(enc.NewDecoder())), strings.ToUpper(name), nil
+	}
+
+	br := bufio.NewReaderSize(r, csvSniffSize)
+	sample, err := br.Peek(csvSniffSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, "", err
+	}
+	if len(sample) == 0 {
+		return nil, "", io.EOF
+	}
+
+	var enc encoding.Encoding = encoding.Nop
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		name = "UTF-8"
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		name = "UTF-16LE"
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		name = "UTF-16BE"
+	case validUTF8Prefix(sample):
+		name = "UTF-8"
+	default:
+		if enc, name, err = detectEncoding(sample); err != nil {
+			return nil, name, err
+		}
+	}
+	// BOMOverride 遇到 BOM 时按 BOM 解码并去除，否则使用检测到的编码
+	return transform.NewReader(br, xunicode.BOMOverride(enc.NewDecoder())), name, nil
+}
+
+// validUTF8Prefix 判断预读的内容是否为合法 UTF-8（末尾被截断的不完整字符不算错误）。
+func validUTF8Prefix(b []byte) bool {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		if utf8.Valid(b) {
+			return true
+		}
+		if r, _ := utf8.DecodeLastRune(b); r != utf8.RuneError {
+			return false
+		}
+		b = b[:len(b)-1]
+	}
+	return len(b) == 0
+}
+
+// sniffCSVDelimiter 根据前 10 行识别分隔符：优先选每行出现次数相同且大于 0 的候选，
+// 其次选总次数最多的候选，都没有时返回 ','。引号内的字符不计数。
+func sniffCSVDelimiter(sample []byte) rune {
+	counts := make(map[rune][]int, len(csvDelimiterCandidates))
+	inQuote := false
+	line := make(map[rune]int)
+	lines := 0
+	flush := func() {
+		for _, d := range csvDelimiterCandidates {
+			counts[d] = append(counts[d], line[d])
+		}
+		clear(line)
+		lines++
+	}
+	for _, r := range string(sample) {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == '\n' && !inQuote:
+			flush()
+		case !inQuote:
+			line[r]++
+		}
+		if lines >= 10 {
+			break
+		}
+	}
+	if lines < 10 && len(line) > 0 {
+		flush() // 最后一行没有换行符
+	}
+	if lines == 0 {
+		return ','
+	}
+
+	best, bestTotal := ',', 0
+	consistentBest, consistentMax := rune(0), 0
+	for _, d := range csvDelimiterCandidates {
+		total, consistent := 0, true
+		for _, n := range counts[d] {
+			total += n
+			consistent = consistent && n > 0 && n == counts[d][0]
+		}
+		if consistent && counts[d][0] > consistentMax {
+			consistentBest, consistentMax = d, counts[d][0]
+		}
+		if total > bestTotal {
+			best, bestTotal = d, total
+		}
+	}
+	if consistentBest != 0 {
+		return consistentBest
+	}
+	return best
+}
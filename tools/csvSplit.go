@@ -0,0 +1,405 @@
+package tools
+
+// CSV 拆分与合并：用于把大文件拆成小块、把多个采集节点的分片合并成一个文件。
+//
+//   - 拆分：按行数、字节数或某列的值拆分，每个分片都重复写入表头，可以单独打开
+//   - 合并：按列名对齐各文件的表头，输出列为所有列的并集（按第一次出现的顺序，或按指定顺序），
+//     某文件缺少的列写为空值；输入可以由 ListAllFilesByModTime 按目录和通配符收集
+//   - 流式：只保存当前行，按列值拆分时同时打开的文件数有上限
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CSVSplitOptions 配置拆分。
+type CSVSplitOptions struct {
+	// OutDir 是分片的输出目录，不存在时自动创建。
+	// 默认值：""，与源文件相同的目录。
+	OutDir string
+
+	// Reader 配置源文件的读取（编码、分隔符等），NoHeader 会被忽略。
+	Reader CSVReaderOptions
+
+	// Writer 配置分片的写出（分隔符、换行符、BOM 等）。
+	Writer CSVWriterOptions
+
+	// MaxOpenFiles 是按列值拆分时同时打开的最大文件数，超过时关闭最早打开的文件，之后再以追加方式打开。
+	// 默认值：64。
+	MaxOpenFiles int
+}
+
+// setDefaults 为未设置的字段填充默认值。
+func (o *CSVSplitOptions) setDefaults(path string) {
+	if o.OutDir == "" {
+		o.OutDir = filepath.Dir(path)
+	}
+	o.Reader.NoHeader = false
+	o.Writer.setDefaults()
+	if o.MaxOpenFiles <= 0 {
+		o.MaxOpenFiles = 64
+	}
+}
+
+// SplitCSVByRows 把 CSV 文件按每个分片最多 rows 行数据拆分，分片命名为 "源文件名_part0001.csv"。
+// 返回已创建的分片路径；出错时也返回出错前已创建的分片。
+//
+// 使用示例：
+//
+//	parts, err := SplitCSVByRows("export.csv", 1000000, CSVSplitOptions{OutDir: "parts"})
+func SplitCSVByRows(path string, rows int, opts CSVSplitOptions) ([]string, error) {
+	if rows <= 0 {
+		return nil, errors.New("csv: split rows must be positive")
+	}
+	return splitCSVSequential(path, opts, func(p *csvPart, line []byte) bool {
+		return p.rows >= rows
+	})
+}
+
+// SplitCSVBySize 把 CSV 文件按每个分片不超过 maxBytes 字节（含表头）拆分，分片命名同 SplitCSVByRows。
+// 单行超过 maxBytes 时该行独占一个分片。
+func SplitCSVBySize(path string, maxBytes int64, opts CSVSplitOptions) ([]string, error) {
+	if maxBytes <= 0 {
+		return nil, errors.New("csv: split size must be positive")
+	}
+	return splitCSVSequential(path, opts, func(p *csvPart, line []byte) bool {
+		return p.rows > 0 && p.size+int64(len(line)) > maxBytes
+	})
+}
+
+// SplitCSVByColumn 按 column 列的值拆分，值相同的行写入同一个分片，分片命名为 "源文件名_值.csv"
+// （文件名中不允许的字符替换为 '_'，替换后相同的值写入同一分片；空值为 "_empty"，以 '_' 开头的值会再加一个 '_'，
+// 因此不会与空值冲突）。仅大小写不同的值（"BJ" 与 "bj"）写入同一分片，以第一次出现的写法命名，
+// 避免在不区分大小写的文件系统（Windows、macOS）上互相覆盖。
+// 返回的分片路径按首次出现的顺序排列。
+// 输出目录中已存在的同名分片会被覆盖。
+//
+// 使用示例：
+//
+//	parts, err := SplitCSVByColumn("orders.csv", "province", CSVSplitOptions{Writer: CSVWriterOptions{BOM: true}})
+func SplitCSVByColumn(path, column string, opts CSVSplitOptions) ([]string, error) {
+	opts.setDefaults(path)
+	cr, err := OpenCSV(path, opts.Reader)
+	if err != nil {
+		return nil, err
+	}
+	defer cr.Close()
+
+	col := slices.Index(cr.Header(), column)
+	if col < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrCSVColumnNotFound, column)
+	}
+	if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+		return nil, err
+	}
+
+	// 以下 map 都以小写的分片路径为键
+	var (
+		parts    []string
+		open     = make(map[string]*csvPart) // 打开的分片
+		openList []string                    // 按打开顺序，用于关闭最早打开的文件
+		created  = make(map[string]string)   // 已创建的分片 → 实际路径（第一次出现的写法）
+		line     []byte
+	)
+	closeAll := func() error {
+		var errs []error
+		for _, p := range open {
+			errs = append(errs, p.close())
+		}
+		return errors.Join(errs...)
+	}
+
+	for rec, err := range cr.Records() {
+		if err != nil {
+			return parts, errors.Join(fmt.Errorf("%s: %w", path, err), closeAll())
+		}
+		value := ""
+		if col < len(rec.Fields) {
+			value = rec.Fields[col]
+		}
+		name := filepath.Join(opts.OutDir, csvPartName(path, csvFileNameValue(value)))
+		key := strings.ToLower(name)
+
+		p, ok := open[key]
+		if !ok {
+			if len(open) >= opts.MaxOpenFiles {
+				oldest := openList[0]
+				openList = openList[1:]
+				err := open[oldest].close()
+				delete(open, oldest)
+				if err != nil {
+					return parts, errors.Join(err, closeAll())
+				}
+			}
+			existing, reopen := created[key]
+			if reopen {
+				name = existing
+			}
+			if p, err = createCSVPart(name, cr.Header(), opts.Writer, reopen); err != nil {
+				return parts, errors.Join(err, closeAll())
+			}
+			if !reopen {
+				created[key] = name
+				parts = append(parts, name)
+			}
+			open[key] = p
+			openList = append(openList, key)
+		}
+		line = appendCSVLine(line[:0], rec.Fields, opts.Writer)
+		if err := p.write(line); err != nil {
+			return parts, errors.Join(err, closeAll())
+		}
+	}
+	return parts, closeAll()
+}
+
+// splitCSVSequential 顺序拆分：full 返回 true 时在写入 line 之前换一个新分片。
+func splitCSVSequential(path string, opts CSVSplitOptions, full func(p *csvPart, line []byte) bool) ([]string, error) {
+	opts.setDefaults(path)
+	cr, err := OpenCSV(path, opts.Reader)
+	if err != nil {
+		return nil, err
+	}
+	defer cr.Close()
+	if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var (
+		parts []string
+		p     *csvPart
+		line  []byte
+	)
+	for rec, err := range cr.Records() {
+		if err != nil {
+			err = fmt.Errorf("%s: %w", path, err)
+			if p != nil {
+				err = errors.Join(err, p.close())
+			}
+			return parts, err
+		}
+		line = appendCSVLine(line[:0], rec.Fields, opts.Writer)
+		if p != nil && full(p, line) {
+			if err := p.close(); err != nil {
+				return parts, err
+			}
+			p = nil
+		}
+		if p == nil {
+			name := filepath.Join(opts.OutDir, csvPartName(path, fmt.Sprintf("part%04d", len(parts)+1)))
+			if p, err = createCSVPart(name, cr.Header(), opts.Writer, false); err != nil {
+				return parts, err
+			}
+			parts = append(parts, name)
+		}
+		if err := p.write(line); err != nil {
+			return parts, errors.Join(err, p.close())
+		}
+	}
+	if p != nil {
+		return parts, p.close()
+	}
+	return parts, nil
+}
+
+// csvPart 是正在写入的分片。
+type csvPart struct {
+	f    *os.File
+	w    *bufio.Writer
+	rows int   // 已写入的数据行数
+	size int64 // 已写入的字节数（含 BOM 和表头）
+}
+
+// createCSVPart 创建分片并写入 BOM 和表头；reopen 为 true 时以追加方式重新打开已写过表头的分片。
+func createCSVPart(name string, header []string, opts CSVWriterOptions, reopen bool) (*csvPart, error) {
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if reopen {
+		flag = os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(name, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	p := &csvPart{f: f, w: bufio.NewWriter(f)}
+	if reopen {
+		return p, nil
+	}
+
+	var head []byte
+	if opts.BOM {
+		head = append(head, 0xEF, 0xBB, 0xBF)
+	}
+	head = appendCSVLine(head, header, opts)
+	if _, err := p.w.Write(head); err != nil {
+		f.Close()
+		return nil, err
+	}
+	p.size = int64(len(head))
+	return p, nil
+}
+
+// write 写入一行数据。
+func (p *csvPart) write(line []byte) error {
+	if _, err := p.w.Write(line); err != nil {
+		return err
+	}
+	p.rows++
+	p.size += int64(len(line))
+	return nil
+}
+
+// close 刷新缓冲并关闭文件。
+func (p *csvPart) close() error {
+	err := p.w.Flush()
+	if cerr := p.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// csvPartName 返回分片文件名："源文件名_suffix.扩展名"（无扩展名时为 .csv）。
+func csvPartName(path, suffix string) string {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	if ext == "" {
+		ext = ".csv"
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_" + suffix + ext
+}
+
+// csvFileNameValue 把列值转为可用作文件名的字符串。空值为 "_empty"；
+// 以 '_' 开头的结果再加一个 '_'，因此非空值不会得到 "_empty"。
+func csvFileNameValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "_empty"
+	}
+	value = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "." || value == ".." {
+		value = strings.ReplaceAll(value, ".", "_")
+	}
+	if strings.HasPrefix(value, "_") {
+		value = "_" + value
+	}
+	if len(value) > 100 {
+		value = strings.ToValidUTF8(value[:100], "") // 避免超过文件名长度限制，截断处不留半个字符
+	}
+	return value
+}
+
+// CSVMergeOptions 配置合并。
+type CSVMergeOptions struct {
+	// Columns 指定输出列及顺序，输入中不在 Columns 里的列被丢弃。
+	// 默认值：nil，输出所有输入列的并集，按第一次出现的顺序排列。
+	Columns []string
+
+	// SourceColumn 不为空时在末尾增加一列，值为该行来源文件的文件名。
+	SourceColumn string
+
+	// Reader 配置各输入文件的读取，NoHeader 会被忽略；各文件的编码和分隔符分别自动检测。
+	Reader CSVReaderOptions
+
+	// Writer 配置输出文件的写出。
+	Writer CSVWriterOptions
+}
+
+// MergeCSVFiles 用 ListAllFilesByModTime 收集 root 下匹配 patterns 的文件（如 ".csv"、"shard_*.csv"），
+// 按修改时间从早到晚合并到 out。out 自身位于 root 下时会被排除。
+//
+// 使用示例：
+//
+//	err := MergeCSVFiles("shards", []string{".csv"}, "all.csv", CSVMergeOptions{SourceColumn: "source"})
+func MergeCSVFiles(root string, patterns []string, out string, opts CSVMergeOptions) error {
+	files, err := ListAllFilesByModTime(root, patterns)
+	if err != nil {
+		return err
+	}
+	outAbs, _ := filepath.Abs(out)
+	files = slices.DeleteFunc(files, func(path string) bool {
+		abs, _ := filepath.Abs(path)
+		return abs == outAbs
+	})
+	return MergeCSV(files, out, opts)
+}
+
+// MergeCSV 按顺序合并 files 到 out，各文件按列名对齐（规则见 CSVMergeOptions）。
+// 输出先写入临时文件，成功后再替换 out，见 CSVStream.WriteFile。
+func MergeCSV(files []string, out string, opts CSVMergeOptions) error {
+	if len(files) == 0 {
+		return errors.New("csv: no files to merge")
+	}
+	opts.Reader.NoHeader = false
+
+	// 第一遍只读表头，确定输出列
+	header := slices.Clone(opts.Columns)
+	if header == nil {
+		for _, path := range files {
+			cr, err := OpenCSV(path, opts.Reader)
+			if err != nil {
+				return err
+			}
+			for _, name := range cr.Header() {
+				if !slices.Contains(header, name) {
+					header = append(header, name)
+				}
+			}
+			cr.Close()
+		}
+	}
+	if opts.SourceColumn != "" {
+		header = append(header, opts.SourceColumn)
+	}
+	if err := checkCSVHeader(header); err != nil {
+		return err
+	}
+
+	s := newCSVStream(header, nil)
+	s.seq = func(yield func(*CSVRecord, error) bool) {
+		for _, path := range files {
+			cr, err := OpenCSV(path, opts.Reader)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			// cols[i] 是输出第 i 列在该文件中的下标，-1 表示该文件没有这一列
+			cols := make([]int, len(header))
+			for i, name := range header {
+				cols[i] = slices.Index(cr.Header(), name)
+			}
+			source := filepath.Base(path)
+
+			for rec, err := range cr.Records() {
+				if err != nil {
+					cr.Close()
+					yield(nil, fmt.Errorf("%s: %w", path, err))
+					return
+				}
+				fields := make([]string, len(header))
+				for i, j := range cols {
+					if j >= 0 && j < len(rec.Fields) {
+						fields[i] = rec.Fields[j]
+					}
+				}
+				if opts.SourceColumn != "" {
+					fields[len(fields)-1] = source
+				}
+				if !yield(s.record(rec.Line, fields), nil) {
+					cr.Close()
+					return
+				}
+			}
+			cr.Close()
+		}
+	}
+	return s.WriteFile(out, opts.Writer)
+}
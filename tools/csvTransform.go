@@ -0,0 +1,764 @@
+package tools
+
+// CSV 流式处理：在 CSVReader/CSVWriter 之上按列名组合选列、改名、过滤、去重、排序和连接。
+//
+//   - 流式：除 Dedupe 需要记住已出现的键、Sort 需要按块缓存外，各步骤逐行处理，不读入整个文件
+//   - 惰性：Select、Filter 等只组装处理步骤，直到 Records、WriteCSV 或 WriteFile 时才读取数据
+//   - 表头：每一步都维护输出表头，列名在组装时校验，不存在的列立即返回 ErrCSVColumnNotFound
+//   - 外部排序：超过 MaxRows 行时分块排序写入临时文件，再多路归并，内存占用与文件大小无关
+//   - 连接：两侧按键外部排序后归并连接，支持内连接和左连接
+//
+// 使用示例：
+//
+//	s, err := OpenCSVStream("orders.csv", CSVReaderOptions{})
+//	if err != nil { ... }
+//	s, err = s.Filter(func(rec *CSVRecord) bool { return rec.Get("status") == "paid" }).
+//	    Select("order_id", "user_id", "amount")
+//	if err != nil { ... }
+//	s, err = s.Sort(CSVSortOptions{Keys: []CSVSortKey{{Column: "amount", Numeric: true, Desc: true}}})
+//	if err != nil { ... }
+//	err = s.WriteFile("paid.csv", CSVWriterOptions{BOM: true})
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrCSVColumnNotFound 表示引用的列不在表头中。
+var ErrCSVColumnNotFound = errors.New("csv: column not found")
+
+// CSVStream 是带表头的惰性行序列，由 OpenCSVStream 或 NewCSVStream 创建，
+// 各处理方法返回新的 CSVStream，不修改原对象。
+type CSVStream struct {
+	header []string
+	index  map[string]int
+	seq    iter.Seq2[*CSVRecord, error]
+}
+
+// OpenCSVStream 读取文件表头并创建流；每次迭代都重新打开文件，因此同一个流可以多次读取。
+func OpenCSVStream(path string, opts CSVReaderOptions) (*CSVStream, error) {
+	cr, err := OpenCSV(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	header := cr.Header()
+	cr.Close()
+	if opts.NoHeader {
+		return nil, errors.New("csv: stream requires a header row")
+	}
+
+	return newCSVStream(header, func(yield func(*CSVRecord, error) bool) {
+		cr, err := OpenCSV(path, opts)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer cr.Close()
+		for rec, err := range cr.Records() {
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}), nil
+}
+
+// NewCSVStream 以 CSVReader 的剩余行创建流，只能读取一次。cr 必须有表头。
+func NewCSVStream(cr *CSVReader) *CSVStream {
+	return newCSVStream(cr.Header(), cr.Records())
+}
+
+// newCSVStream 用表头和行序列创建流，表头索引与 CSVReader 相同（重复列名按第一次出现的位置）。
+func newCSVStream(header []string, seq iter.Seq2[*CSVRecord, error]) *CSVStream {
+	return &CSVStream{header: header, index: csvHeaderIndex(header), seq: seq}
+}
+
+// Header 返回输出表头。调用方不得修改。
+func (s *CSVStream) Header() []string { return s.header }
+
+// Records 以迭代器逐行产出记录，遇到错误时产出 (nil, err) 或 (rec, *csv.ParseError)，由调用方决定是否继续。
+func (s *CSVStream) Records() iter.Seq2[*CSVRecord, error] { return s.seq }
+
+// Select 按给定顺序保留列，未列出的列被丢弃；可用 "原列名:新列名" 同时改名。
+//
+// 使用示例：
+//
+//	s, err = s.Select("id", "user_name:name", "age")
+func (s *CSVStream) Select(columns ...string) (*CSVStream, error) {
+	header := make([]string, len(columns))
+	src := make([]string, len(columns))
+	for i, c := range columns {
+		src[i], header[i] = c, c
+		if from, to, ok := strings.Cut(c, ":"); ok {
+			src[i], header[i] = from, to
+		}
+	}
+	idx, err := s.columnIndexes(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCSVHeader(header); err != nil {
+		return nil, err
+	}
+
+	out := newCSVStream(header, nil)
+	out.seq = func(yield func(*CSVRecord, error) bool) {
+		for rec, err := range s.seq {
+			if err != nil {
+				if !yield(nil, err) {
+					return
+				}
+				continue
+			}
+			fields := make([]string, len(idx))
+			for i, j := range idx {
+				if j < len(rec.Fields) {
+					fields[i] = rec.Fields[j]
+				}
+			}
+			if !yield(out.record(rec.Line, fields), nil) {
+				return
+			}
+		}
+	}
+	return out, nil
+}
+
+// Rename 按 names（原列名→新列名）重命名列，其余列不变。
+func (s *CSVStream) Rename(names map[string]string) (*CSVStream, error) {
+	header := slices.Clone(s.header)
+	for _, from := range slices.Sorted(maps.Keys(names)) {
+		i, ok := s.index[from]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrCSVColumnNotFound, from)
+		}
+		header[i] = names[from]
+	}
+	if err := checkCSVHeader(header); err != nil {
+		return nil, err
+	}
+
+	out := newCSVStream(header, nil)
+	out.seq = func(yield func(*CSVRecord, error) bool) {
+		for rec, err := range s.seq {
+			if rec != nil {
+				rec = out.record(rec.Line, rec.Fields)
+			}
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}
+	return out, nil
+}
+
+// Filter 只保留 keep 返回 true 的行。
+func (s *CSVStream) Filter(keep func(rec *CSVRecord) bool) *CSVStream {
+	return &CSVStream{header: s.header, index: s.index, seq: func(yield func(*CSVRecord, error) bool) {
+		for rec, err := range s.seq {
+			if err == nil && !keep(rec) {
+				continue
+			}
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}}
+}
+
+// Map 对每行调用 fn，fn 可以用 CSVRecord.Set 修改字段；fn 返回错误时该错误代替记录产出。
+//
+// 使用示例：
+//
+//	s = s.Map(func(rec *CSVRecord) error {
+//	    return rec.Set("phone", MaskPhone(rec.Get("phone")))
+//	})
+func (s *CSVStream) Map(fn func(rec *CSVRecord) error) *CSVStream {
+	return &CSVStream{header: s.header, index: s.index, seq: func(yield func(*CSVRecord, error) bool) {
+		for rec, err := range s.seq {
+			if err == nil {
+				if ferr := fn(rec); ferr != nil {
+					err = fmt.Errorf("line %d: %w", rec.Line, ferr)
+					rec = nil
+				}
+			}
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}}
+}
+
+// Dedupe 按 keys 列去重，保留第一次出现的行；keys 为空时按整行去重。
+// 已出现的键保存在内存中，内存占用与不同键的数量成正比。
+func (s *CSVStream) Dedupe(keys ...string) (*CSVStream, error) {
+	idx, err := s.columnIndexes(keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		idx = make([]int, len(s.header))
+		for i := range idx {
+			idx[i] = i
+		}
+	}
+
+	return &CSVStream{header: s.header, index: s.index, seq: func(yield func(*CSVRecord, error) bool) {
+		seen := make(map[string]struct{})
+		for rec, err := range s.seq {
+			if err == nil {
+				key := csvKeyString(rec, idx)
+				if _, dup := seen[key]; dup {
+					continue
+				}
+				seen[key] = struct{}{}
+			}
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}}, nil
+}
+
+// CSVSortKey 是一个排序列。
+type CSVSortKey struct {
+	Column  string // 列名
+	Desc    bool   // 为 true 时降序
+	Numeric bool   // 为 true 时按数值比较（见 ParseDecimal），非数值按字符串比较，升序时排在数值之后
+}
+
+// CSVSortOptions 配置排序。
+type CSVSortOptions struct {
+	// Keys 是排序列，按顺序比较；值相同的行保持原有顺序。
+	Keys []CSVSortKey
+
+	// MaxRows 是内存中一次排序的最大行数，超过时分块写入临时文件后归并。
+	// 默认值：100000。
+	MaxRows int
+
+	// TempDir 是临时文件目录，排序结束（或迭代中止）后临时文件会被删除。
+	// 默认值：""，即 os.TempDir()。
+	TempDir string
+}
+
+// setDefaults 为未设置的字段填充默认值。
+func (o *CSVSortOptions) setDefaults() {
+	if o.MaxRows <= 0 {
+		o.MaxRows = 100000
+	}
+}
+
+// Sort 按 opts.Keys 稳定排序。行数不超过 MaxRows 时在内存中排序，否则外部排序。
+// 读取源数据出错时中止并产出该错误。
+func (s *CSVStream) Sort(opts CSVSortOptions) (*CSVStream, error) {
+	opts.setDefaults()
+	if len(opts.Keys) == 0 {
+		return nil, errors.New("csv: sort requires at least one key")
+	}
+	cols := make([]string, len(opts.Keys))
+	for i, k := range opts.Keys {
+		cols[i] = k.Column
+	}
+	idx, err := s.columnIndexes(cols)
+	if err != nil {
+		return nil, err
+	}
+	// 排序键在行进入缓冲区（或从临时文件读回）时解析一次，比较时不再重复解析数值
+	newRow := func(rec *CSVRecord) csvSortRow {
+		keys := make([]csvSortValue, len(idx))
+		for i, k := range opts.Keys {
+			keys[i] = newCSVSortValue(csvField(rec, idx[i]), k.Numeric)
+		}
+		return csvSortRow{rec: rec, keys: keys}
+	}
+	cmp := func(a, b csvSortRow) int {
+		for i, k := range opts.Keys {
+			c := compareCSVSortValue(a.keys[i], b.keys[i])
+			if c != 0 {
+				if k.Desc {
+					return -c
+				}
+				return c
+			}
+		}
+		return 0
+	}
+
+	return &CSVStream{header: s.header, index: s.index, seq: func(yield func(*CSVRecord, error) bool) {
+		var chunks []string
+		defer func() {
+			for _, path := range chunks {
+				os.Remove(path)
+			}
+		}()
+
+		buf := make([]csvSortRow, 0, min(opts.MaxRows, 1024))
+		for rec, err := range s.seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			buf = append(buf, newRow(rec))
+			if len(buf) < opts.MaxRows {
+				continue
+			}
+			slices.SortStableFunc(buf, cmp)
+			path, err := writeCSVSortChunk(buf, opts.TempDir)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			chunks = append(chunks, path)
+			buf = buf[:0]
+		}
+		slices.SortStableFunc(buf, cmp)
+
+		if len(chunks) == 0 {
+			for _, row := range buf {
+				if !yield(row.rec, nil) {
+					return
+				}
+			}
+			return
+		}
+		if len(buf) > 0 {
+			path, err := writeCSVSortChunk(buf, opts.TempDir)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			chunks = append(chunks, path)
+		}
+		mergeCSVSortChunks(chunks, s, newRow, cmp, yield)
+	}}, nil
+}
+
+// CSVJoinType 是连接方式。
+type CSVJoinType int
+
+const (
+	CSVJoinInner CSVJoinType = iota // 只输出两侧都有的键
+	CSVJoinLeft                     // 输出左侧所有行，右侧没有匹配时右侧列为空
+)
+
+// CSVJoinOptions 配置连接。
+type CSVJoinOptions struct {
+	// On 是左侧的键列。
+	On []string
+
+	// RightOn 是右侧的键列，与 On 一一对应。
+	// 默认值：nil，与 On 相同。
+	RightOn []string
+
+	// Type 是连接方式。
+	// 默认值：CSVJoinInner。
+	Type CSVJoinType
+
+	// RightPrefix 加在与左侧列名冲突的右侧列名前。
+	// 默认值："right."。
+	RightPrefix string
+
+	// Sort 配置两侧外部排序的 MaxRows 和 TempDir，Keys 会被忽略。
+	Sort CSVSortOptions
+}
+
+// setDefaults 为未设置的字段填充默认值。
+func (o *CSVJoinOptions) setDefaults() {
+	if o.RightOn == nil {
+		o.RightOn = o.On
+	}
+	if o.RightPrefix == "" {
+		o.RightPrefix = "right."
+	}
+}
+
+// Join 按键把 s（左侧）与 right 连接。输出表头为左侧全部列加右侧的非键列，输出按键排序；
+// 一个键在两侧各有多行时输出笛卡尔积，此时只有右侧同一个键的行会缓存在内存中。
+// 键按字符串精确比较，空值也参与匹配。
+//
+// 使用示例：
+//
+//	orders, _ := OpenCSVStream("orders.csv", CSVReaderOptions{})
+//	users, _ := OpenCSVStream("users.csv", CSVReaderOptions{})
+//	joined, err := orders.Join(users, CSVJoinOptions{On: []string{"user_id"}, RightOn: []string{"id"}})
+func (s *CSVStream) Join(right *CSVStream, opts CSVJoinOptions) (*CSVStream, error) {
+	opts.setDefaults()
+	if len(opts.On) == 0 || len(opts.On) != len(opts.RightOn) {
+		return nil, errors.New("csv: join requires the same number of left and right keys")
+	}
+	lidx, err := s.columnIndexes(opts.On)
+	if err != nil {
+		return nil, err
+	}
+	ridx, err := right.columnIndexes(opts.RightOn)
+	if err != nil {
+		return nil, err
+	}
+
+	lsorted, err := s.Sort(csvSortByColumns(opts.Sort, opts.On))
+	if err != nil {
+		return nil, err
+	}
+	rsorted, err := right.Sort(csvSortByColumns(opts.Sort, opts.RightOn))
+	if err != nil {
+		return nil, err
+	}
+
+	// 输出表头：左侧全部列 + 右侧非键列（冲突时加前缀）
+	header := slices.Clone(s.header)
+	var rcols []int
+	for i, name := range right.header {
+		if slices.Contains(ridx, i) {
+			continue
+		}
+		if slices.Contains(header, name) {
+			name = opts.RightPrefix + name
+		}
+		header = append(header, name)
+		rcols = append(rcols, i)
+	}
+	if err := checkCSVHeader(header); err != nil {
+		return nil, err
+	}
+
+	out := newCSVStream(header, nil)
+	join := func(l, r *CSVRecord) *CSVRecord {
+		fields := make([]string, len(header))
+		copy(fields, l.Fields[:min(len(l.Fields), len(s.header))]) // 不规则行多出的列不能落入右侧列
+		if r != nil {
+			for i, j := range rcols {
+				fields[len(s.header)+i] = csvField(r, j)
+			}
+		}
+		return out.record(l.Line, fields)
+	}
+
+	out.seq = func(yield func(*CSVRecord, error) bool) {
+		lnext, lstop := iter.Pull2(lsorted.seq)
+		defer lstop()
+		rnext, rstop := iter.Pull2(rsorted.seq)
+		defer rstop()
+
+		l, lerr, lok := lnext()
+		r, rerr, rok := rnext()
+		var group []*CSVRecord
+		var groupKey []string
+		for lok {
+			if lerr != nil {
+				yield(nil, lerr)
+				return
+			}
+			lkey := csvKeyFields(l, lidx)
+
+			// 右侧前进到不小于左侧键的位置，并缓存与左侧键相等的一组
+			if groupKey == nil || slices.Compare(groupKey, lkey) != 0 {
+				group, groupKey = group[:0], lkey
+				for rok {
+					if rerr != nil {
+						yield(nil, rerr)
+						return
+					}
+					c := slices.Compare(csvKeyFields(r, ridx), lkey)
+					if c > 0 {
+						break
+					}
+					if c == 0 {
+						group = append(group, r)
+					}
+					r, rerr, rok = rnext()
+				}
+			}
+
+			if len(group) == 0 && opts.Type == CSVJoinLeft {
+				if !yield(join(l, nil), nil) {
+					return
+				}
+			}
+			for _, g := range group {
+				if !yield(join(l, g), nil) {
+					return
+				}
+			}
+			l, lerr, lok = lnext()
+		}
+	}
+	return out, nil
+}
+
+// WriteCSV 把流写到 w（先写表头），返回写出的数据行数。遇到第一个错误时停止。
+func (s *CSVStream) WriteCSV(w io.Writer, opts CSVWriterOptions) (rows int, err error) {
+	cw := NewCSVWriter(w, opts)
+	if err = cw.Write(s.header); err != nil {
+		return 0, err
+	}
+	for rec, err := range s.seq {
+		if err != nil {
+			return rows, err
+		}
+		if err = cw.Write(rec.Fields); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	return rows, cw.Flush()
+}
+
+// WriteFile 把流写入文件：先写到同目录的临时文件，成功后再替换目标文件，
+// 因此输出文件可以与输入文件相同，出错时原文件保持不变。与 WriteToFile 共用文件锁。
+// 替换已有文件时保留其权限，新文件的权限为 0644。
+func (s *CSVStream) WriteFile(path string, opts CSVWriterOptions) error {
+	lock := getFileLock(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// os.CreateTemp 以 0600 创建文件，改名前需改为目标文件应有的权限
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = s.WriteCSV(f, opts)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Set 按列名修改该行的值（通过 CSVFieldEditStrict），列不存在或该行缺少该列时返回错误。
+func (rec *CSVRecord) Set(name, value string) error {
+	_, err := CSVFieldEditStrict(rec.Fields, rec.index, name, value)
+	return err
+}
+
+// record 创建属于该流表头的记录。
+func (s *CSVStream) record(line int, fields []string) *CSVRecord {
+	return &CSVRecord{Line: line, Fields: fields, header: s.header, index: s.index}
+}
+
+// columnIndexes 返回列名对应的下标，列不存在时返回 ErrCSVColumnNotFound。
+func (s *CSVStream) columnIndexes(columns []string) ([]int, error) {
+	idx := make([]int, len(columns))
+	for i, name := range columns {
+		j, ok := s.index[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrCSVColumnNotFound, name)
+		}
+		idx[i] = j
+	}
+	return idx, nil
+}
+
+// checkCSVHeader 检查输出表头没有重复列名。
+func checkCSVHeader(header []string) error {
+	seen := make(map[string]struct{}, len(header))
+	for _, name := range header {
+		if _, dup := seen[name]; dup {
+			return fmt.Errorf("csv: duplicate column %q", name)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
+// csvField 返回第 i 列的值，该行缺少该列时返回空字符串。
+func csvField(rec *CSVRecord, i int) string {
+	if i < len(rec.Fields) {
+		return rec.Fields[i]
+	}
+	return ""
+}
+
+// csvKeyFields 取出键列的值。
+func csvKeyFields(rec *CSVRecord, idx []int) []string {
+	key := make([]string, len(idx))
+	for i, j := range idx {
+		key[i] = csvField(rec, j)
+	}
+	return key
+}
+
+// csvKeyString 把键列编码为 map 键，每个值前加长度，避免值中含分隔字符时冲突。
+func csvKeyString(rec *CSVRecord, idx []int) string {
+	var b []byte
+	for _, j := range idx {
+		v := csvField(rec, j)
+		b = strconv.AppendInt(b, int64(len(v)), 10)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	return string(b)
+}
+
+// csvSortRow 是排序中的一行及其预先解析的排序键。
+type csvSortRow struct {
+	rec  *CSVRecord
+	keys []csvSortValue
+}
+
+// csvSortValue 是一个排序键的值；Numeric 列能解析为数值时 isNum 为 true。
+type csvSortValue struct {
+	text  string
+	num   Decimal
+	isNum bool
+}
+
+// newCSVSortValue 解析排序键，numeric 时尝试按 ParseDecimal 解析。
+func newCSVSortValue(text string, numeric bool) csvSortValue {
+	if numeric {
+		if d, err := ParseDecimal(strings.TrimSpace(text)); err == nil {
+			return csvSortValue{text: text, num: d, isNum: true}
+		}
+	}
+	return csvSortValue{text: text}
+}
+
+// compareCSVSortValue 比较两个排序键：数值排在非数值前面并按数值比较，其余按字符串比较。
+func compareCSVSortValue(a, b csvSortValue) int {
+	switch {
+	case a.isNum && b.isNum:
+		return a.num.Cmp(b.num)
+	case a.isNum:
+		return -1
+	case b.isNum:
+		return 1
+	}
+	return strings.Compare(a.text, b.text)
+}
+
+// csvSortByColumns 返回按 columns 升序（字符串比较）排序的选项，用于连接。
+func csvSortByColumns(opts CSVSortOptions, columns []string) CSVSortOptions {
+	opts.Keys = make([]CSVSortKey, len(columns))
+	for i, c := range columns {
+		opts.Keys[i] = CSVSortKey{Column: c}
+	}
+	return opts
+}
+
+// writeCSVSortChunk 把已排序的一块写入临时文件，第一列是原始行号，无表头。
+func writeCSVSortChunk(rows []csvSortRow, dir string) (string, error) {
+	f, err := os.CreateTemp(dir, "csvsort-*.csv")
+	if err != nil {
+		return "", err
+	}
+	cw := NewCSVWriter(f, CSVWriterOptions{LineEnding: "\n"})
+	var fields []string
+	for _, row := range rows {
+		fields = append(append(fields[:0], strconv.Itoa(row.rec.Line)), row.rec.Fields...)
+		if err = cw.Write(fields); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = cw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// csvSortHead 是归并时某个临时文件的当前行。
+type csvSortHead struct {
+	row   csvSortRow
+	chunk int // 块序号，相等的行按块序号排列以保持稳定
+	next  func() (csvSortRow, error, bool)
+}
+
+// csvSortHeap 是按比较函数排列的最小堆。
+type csvSortHeap struct {
+	heads []*csvSortHead
+	cmp   func(a, b csvSortRow) int
+}
+
+func (h *csvSortHeap) Len() int { return len(h.heads) }
+func (h *csvSortHeap) Less(i, j int) bool {
+	if c := h.cmp(h.heads[i].row, h.heads[j].row); c != 0 {
+		return c < 0
+	}
+	return h.heads[i].chunk < h.heads[j].chunk
+}
+func (h *csvSortHeap) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+func (h *csvSortHeap) Push(x any)    { h.heads = append(h.heads, x.(*csvSortHead)) }
+func (h *csvSortHeap) Pop() any {
+	x := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return x
+}
+
+// mergeCSVSortChunks 多路归并已排序的临时文件并产出记录，读回的行用 newRow 解析排序键。
+func mergeCSVSortChunks(chunks []string, s *CSVStream, newRow func(*CSVRecord) csvSortRow,
+	cmp func(a, b csvSortRow) int, yield func(*CSVRecord, error) bool) {
+	h := &csvSortHeap{cmp: cmp}
+	for i, path := range chunks {
+		cr, err := OpenCSV(path, CSVReaderOptions{Delimiter: ',', Encoding: "UTF-8", NoHeader: true, AllowRagged: true})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer cr.Close()
+
+		next, stop := iter.Pull2(cr.Records())
+		defer stop()
+		head := &csvSortHead{chunk: i, next: func() (csvSortRow, error, bool) {
+			rec, err, ok := next()
+			if !ok || err != nil {
+				return csvSortRow{}, err, ok
+			}
+			line, _ := strconv.Atoi(rec.Fields[0])
+			return newRow(s.record(line, rec.Fields[1:])), nil, true
+		}}
+		row, err, ok := head.next()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if ok {
+			head.row = row
+			h.heads = append(h.heads, head)
+		}
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		head := h.heads[0]
+		if !yield(head.row.rec, nil) {
+			return
+		}
+		row, err, ok := head.next()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if ok {
+			head.row = row
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+}
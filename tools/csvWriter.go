@@ -0,0 +1,403 @@
+package tools
+
+// CSV 写出：按 RFC 4180 转义，不丢失数据（替代会删除逗号、引号、换行的 CSVStringsToLine）。
+//
+//   - 转义：字段含分隔符、双引号、换行或首尾空白时整体加引号，字段内的双引号写成两个
+//   - 格式：分隔符、换行符（默认 "\r\n"）、是否写 UTF-8 BOM（Excel 打开中文不乱码）均可配置
+//   - 结构体：表头取自字段标签（conv 标签、json 标签、字段名，与 Decode 一致），
+//     嵌套结构体展开为 "addr.city" 形式的列，写出的文件可以用 CSVRecord.Decode 原样读回
+//   - 并发：AppendCSVFile/AppendCSVStructs 与 WriteToFile 共用按文件路径划分的锁，
+//     多个 goroutine 同时追加同一文件时行不会交错，新文件的表头只写一次
+
+import (
+	"bufio"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// CSVWriterOptions 配置 CSV 写出。
+type CSVWriterOptions struct {
+	// Delimiter 是字段分隔符。
+	// 默认值：','。
+	Delimiter rune
+
+	// LineEnding 是行尾换行符。
+	// 默认值："\r\n"（RFC 4180），可设为 "\n"。
+	LineEnding string
+
+	// BOM 为 true 时在文件开头写入 UTF-8 BOM；追加到已有内容的文件时不写。
+	BOM bool
+
+	// QuoteAll 为 true 时所有字段都加引号，否则只在需要时加引号。
+	QuoteAll bool
+
+	// TagName 是结构体字段名标签，未设置该标签的字段继续尝试 json 标签。
+	// 默认值："conv"（与 DecodeOptions 一致）。
+	TagName string
+}
+
+// setDefaults 为未设置的字段填充默认值。
+func (o *CSVWriterOptions) setDefaults() {
+	if o.Delimiter == 0 {
+		o.Delimiter = ','
+	}
+	if o.LineEnding == "" {
+		o.LineEnding = "\r\n"
+	}
+	if o.TagName == "" {
+		o.TagName = "conv"
+	}
+}
+
+// CSVWriter 是带缓冲的 CSV 写出器，写完需调用 Flush。不是并发安全的，并发追加文件请使用 AppendCSVFile。
+type CSVWriter struct {
+	w       *bufio.Writer
+	opts    CSVWriterOptions
+	columns map[reflect.Type][]csvColumn
+	line    []byte
+	err     error // 写 BOM 时的错误，在第一次写入时返回
+}
+
+// csvColumn 是结构体展开后的一列。
+type csvColumn struct {
+	name  string
+	index []int // reflect.Value.FieldByIndex 的路径
+}
+
+// NewCSVWriter 创建写出器，BOM 为 true 时立即写入 BOM（调用方应确保 w 位于文件开头）。
+//
+// 使用示例：
+//
+//	cw := NewCSVWriter(f, CSVWriterOptions{BOM: true})
+//	_ = cw.WriteHeader(User{})
+//	for _, u := range users {
+//	    if err := cw.WriteStruct(u); err != nil { ... }
+//	}
+//	if err := cw.Flush(); err != nil { ... }
+func NewCSVWriter(w io.Writer, opts CSVWriterOptions) *CSVWriter {
+	opts.setDefaults()
+	cw := &CSVWriter{w: bufio.NewWriter(w), opts: opts, columns: make(map[reflect.Type][]csvColumn)}
+	if opts.BOM {
+		_, cw.err = cw.w.Write([]byte{0xEF, 0xBB, 0xBF})
+	}
+	return cw
+}
+
+// Write 写出一行。
+func (cw *CSVWriter) Write(fields []string) error {
+	if cw.err != nil {
+		return cw.err
+	}
+	cw.line = appendCSVLine(cw.line[:0], fields, cw.opts)
+	_, err := cw.w.Write(cw.line)
+	return err
+}
+
+// WriteAll 写出多行并 Flush。
+func (cw *CSVWriter) WriteAll(rows [][]string) error {
+	for _, row := range rows {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+// WriteHeader 按结构体 v（结构体、结构体指针或其零值）的字段标签写出表头。
+func (cw *CSVWriter) WriteHeader(v any) error {
+	cols, err := cw.structColumns(reflect.TypeOf(v))
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	return cw.Write(header)
+}
+
+// WriteStruct 按 WriteHeader 的列顺序写出结构体 v 的一行。
+// 字段值按 Convert[string] 的规则转换（time.Time 为 RFC3339Nano，Decimal 保留小数位数），
+// 实现 encoding.TextMarshaler 的类型使用 MarshalText，nil 指针写为空值。
+func (cw *CSVWriter) WriteStruct(v any) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return errors.New("csv: cannot write nil")
+	}
+	cols, err := cw.structColumns(rv.Type())
+	if err != nil {
+		return err
+	}
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return fmt.Errorf("csv: cannot write nil %v", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if !rv.CanAddr() {
+		// 复制到可寻址的值，以便调用指针接收者的 MarshalText
+		addressable := reflect.New(rv.Type()).Elem()
+		addressable.Set(rv)
+		rv = addressable
+	}
+
+	fields := make([]string, len(cols))
+	for i, c := range cols {
+		if fields[i], err = csvFieldString(rv, c.index); err != nil {
+			return fmt.Errorf("csv: column %q: %w", c.name, err)
+		}
+	}
+	return cw.Write(fields)
+}
+
+// Flush 把缓冲写入底层 io.Writer。
+func (cw *CSVWriter) Flush() error {
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.Flush()
+}
+
+// FormatCSVLine 把字段按 RFC 4180 转义后拼成一行（含行尾换行符），不会删除或改写任何字符。
+//
+// 使用示例：
+//
+//	FormatCSVLine([]string{"1", `say "hi"`, "a,b"}, CSVWriterOptions{}) // 1,"say ""hi""","a,b"\r\n
+func FormatCSVLine(fields []string, opts CSVWriterOptions) []byte {
+	opts.setDefaults()
+	return appendCSVLine(nil, fields, opts)
+}
+
+// AppendCSVFile 把 rows 追加到 CSV 文件，与 WriteToFile 共用文件锁，可多个 goroutine 并发调用。
+// 文件不存在或为空时先写 BOM（opts.BOM 为 true 时）和 header（header 为 nil 时不写）。
+func AppendCSVFile(path string, header []string, rows [][]string, opts CSVWriterOptions) error {
+	return appendCSV(path, opts, func(cw *CSVWriter, empty bool) error {
+		if empty && header != nil {
+			if err := cw.Write(header); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AppendCSVStructs 把结构体切片追加到 CSV 文件，文件不存在或为空时先写出由 T 的字段标签生成的表头。
+// 与 AppendCSVFile 一样可并发调用。
+//
+// 使用示例：
+//
+//	type Item struct {
+//	    ID    int     `conv:"id"`
+//	    Price Decimal `conv:"price"`
+//	}
+//	err := AppendCSVStructs("items.csv", items, CSVWriterOptions{BOM: true})
+func AppendCSVStructs[T any](path string, rows []T, opts CSVWriterOptions) error {
+	return appendCSV(path, opts, func(cw *CSVWriter, empty bool) error {
+		if empty {
+			if err := cw.WriteHeader(*new(T)); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			if err := cw.WriteStruct(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WriteCSVStructs 覆盖写出 CSV 文件：表头加全部结构体。与 WriteToFile 共用文件锁。
+func WriteCSVStructs[T any](path string, rows []T, opts CSVWriterOptions) error {
+	lock := getFileLock(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	cw := NewCSVWriter(f, opts)
+	if err = cw.WriteHeader(*new(T)); err == nil {
+		for _, row := range rows {
+			if err = cw.WriteStruct(row); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = cw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// appendCSV 在文件锁内以追加模式打开文件，empty 表示写入前文件为空。
+func appendCSV(path string, opts CSVWriterOptions, write func(cw *CSVWriter, empty bool) error) error {
+	lock := getFileLock(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	empty := info.Size() == 0
+	opts.BOM = opts.BOM && empty
+
+	cw := NewCSVWriter(f, opts)
+	err = write(cw, empty)
+	if err == nil {
+		err = cw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// appendCSVLine 把转义后的一行追加到 dst。
+func appendCSVLine(dst []byte, fields []string, opts CSVWriterOptions) []byte {
+	for i, field := range fields {
+		if i > 0 {
+			dst = append(dst, string(opts.Delimiter)...)
+		}
+		if !opts.QuoteAll && !csvFieldNeedsQuotes(field, opts.Delimiter) {
+			dst = append(dst, field...)
+			continue
+		}
+		dst = append(dst, '"')
+		dst = append(dst, strings.ReplaceAll(field, `"`, `""`)...)
+		dst = append(dst, '"')
+	}
+	return append(dst, opts.LineEnding...)
+}
+
+// csvFieldNeedsQuotes 判断字段是否需要加引号：含分隔符、引号、换行，或有首尾空白（避免读取时被裁剪）。
+// 单独的 `\.` 也需要加引号，否则会被 PostgreSQL COPY 当作结束标记。
+func csvFieldNeedsQuotes(field string, delim rune) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, delim) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	return field[0] == ' ' || field[0] == '\t' || field[len(field)-1] == ' ' || field[len(field)-1] == '\t'
+}
+
+// structColumns 返回结构体类型展开后的列，结果按类型缓存。
+func (cw *CSVWriter) structColumns(t reflect.Type) ([]csvColumn, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: %v is not a struct", t)
+	}
+	if cols, ok := cw.columns[t]; ok {
+		return cols, nil
+	}
+	cols, err := appendCSVColumns(nil, t, nil, "", cw.opts.TagName, make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, err
+	}
+	cw.columns[t] = cols
+	return cols, nil
+}
+
+// appendCSVColumns 展开结构体字段：匿名嵌入结构体提升到当前层级，嵌套结构体加 "key." 前缀，规则与 Decode 一致。
+// path 记录当前展开路径上的类型；自引用的类型（如 Next *Node）无法展开为有限的列，返回错误。
+func appendCSVColumns(cols []csvColumn, t reflect.Type, index []int, prefix, tagName string, path map[reflect.Type]bool) ([]csvColumn, error) {
+	if path[t] {
+		return nil, fmt.Errorf("csv: recursive struct type %v at %q cannot be flattened into columns", t, strings.TrimSuffix(prefix, "."))
+	}
+	path[t] = true
+	defer delete(path, t)
+
+	var err error
+	for i := range t.NumField() {
+		f := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+
+		if f.Anonymous && f.Tag.Get(tagName) == "" && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				if cols, err = appendCSVColumns(cols, ft, idx, prefix, tagName, path); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		key, _, skip := structFieldKey(f, tagName)
+		if skip {
+			continue
+		}
+		if isNestedStruct(f.Type) {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if cols, err = appendCSVColumns(cols, ft, idx, prefix+key+".", tagName, path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		cols = append(cols, csvColumn{name: prefix + key, index: idx})
+	}
+	return cols, nil
+}
+
+// csvFieldString 取出 index 路径上的字段并转为字符串，途经 nil 指针时返回空字符串。v 必须可寻址。
+func csvFieldString(v reflect.Value, index []int) (string, error) {
+	for _, i := range index {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch t := v.Type(); {
+	case t == bigIntType || t == bigRatType || t == bigFloatType:
+		return Convert[string](v.Addr().Interface()) // Convert 接受 *big.Int 等指针
+	case isConvertStruct(t):
+		return Convert[string](v.Interface())
+	case v.CanAddr() && reflect.PointerTo(t).Implements(textMarshalerType):
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	return Convert[string](v.Interface())
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
@@ -0,0 +1,324 @@
+package tools
+
+// CSV 示例，覆盖以下场景：
+//   - 示例1：流式读取（引号内的逗号和换行、BOM、GBK、分隔符识别、格式错误的行号）
+//   - 示例2：写出（转义、BOM 与换行符、结构体表头、并发追加同一文件）
+//   - 示例3：流式处理（选列与改名、去重、小 MaxRows 的外部排序、内连接与左连接、写回文件）
+//   - 示例4：按行数、大小、列值拆分（每个分片重复表头）与按表头并集合并
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// =============================================================================
+// 示例 1：流式读取
+// =============================================================================
+
+func Example_csvReader() {
+	// 引号内可以包含分隔符、转义的引号和换行；Line 是记录在文件中的起始行号
+	src := "\ufeffid,name,note\n" + // UTF-8 BOM 会被去除
+		"1,Tom,\"hello, world\"\n" +
+		"2,Ann,\"第一行\n第二行\"\n" +
+		"3,\"Bob \"\"B\"\"\",ok\n"
+	cr, _ := NewCSVReader(strings.NewReader(src), CSVReaderOptions{})
+	fmt.Printf("%q %q %s\n", cr.Header(), cr.Delimiter(), cr.Encoding())
+	for rec, err := range cr.Records() {
+		fmt.Printf("line %d: %q %q %v\n", rec.Line, rec.Get("name"), rec.Get("note"), err)
+	}
+
+	// GBK 编码按内容自动检测，也可以用 Encoding 指定
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String("编号;城市;备注\n1;北京;首都，政治文化中心\n2;上海;直辖市，经济中心\n")
+	cr, _ = NewCSVReader(strings.NewReader(gbk), CSVReaderOptions{})
+	rec, _ := cr.Read()
+	fmt.Printf("%s %q %q %q\n", cr.Encoding(), cr.Delimiter(), cr.Header(), rec.Map()["城市"])
+	cr, _ = NewCSVReader(strings.NewReader(gbk), CSVReaderOptions{Encoding: "GBK"})
+	rec, _ = cr.Read()
+	fmt.Println(rec.Get("备注"))
+
+	// 分隔符从前几行识别：这里是制表符
+	cr, _ = NewCSVReader(strings.NewReader("a\tb\n1,5\t2\n"), CSVReaderOptions{})
+	rec, _ = cr.Read()
+	fmt.Printf("%q %q\n", cr.Delimiter(), rec.Fields)
+
+	// 格式错误的行返回带行号的 *csv.ParseError，之后的行仍可继续读取
+	bad := "id,name\n1,Tom\n2,Ann,extra\n3,\"unterminated\n"
+	cr, _ = NewCSVReader(strings.NewReader(bad), CSVReaderOptions{})
+	for rec, err := range cr.Records() {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			fmt.Println("error on line", pe.Line, pe.Err)
+			continue
+		}
+		fmt.Println("ok", rec.Line, rec.Fields)
+	}
+
+	// Output:
+	// ["id" "name" "note"] ',' UTF-8
+	// line 2: "Tom" "hello, world" <nil>
+	// line 3: "Ann" "第一行\n第二行" <nil>
+	// line 5: "Bob \"B\"" "ok" <nil>
+	// GB-18030 ';' ["编号" "城市" "备注"] "北京"
+	// 首都，政治文化中心
+	// '\t' ["1,5" "2"]
+	// ok 2 [1 Tom]
+	// error on line 3 wrong number of fields
+	// error on line 4 extraneous or missing " in quoted-field
+}
+
+// =============================================================================
+// 示例 2：写出
+// =============================================================================
+
+// CSVOrder 演示结构体写出：嵌套结构体展开为 "addr.city" 形式的列。
+type CSVOrder struct {
+	ID     int           `conv:"id"`
+	Amount Decimal       `conv:"amount"`
+	Note   string        `json:"note"`
+	Addr   DecodeAddress `conv:"addr"`
+	Secret string        `conv:"-"`
+}
+
+// CSVNode 引用自身，无法展开为有限的列。
+type CSVNode struct {
+	ID   int
+	Next *CSVNode
+}
+
+func Example_csvWriter() {
+	// 含分隔符、引号、换行或首尾空白的字段加引号，引号写成两个，不删除任何字符
+	fmt.Printf("%q\n", FormatCSVLine([]string{"1", `say "hi"`, "a,b", "x\ny", " pad"}, CSVWriterOptions{}))
+	fmt.Printf("%q\n", FormatCSVLine([]string{"a", "b;c"}, CSVWriterOptions{Delimiter: ';', LineEnding: "\n", QuoteAll: true}))
+
+	// BOM 写在最前面，方便 Excel 识别 UTF-8
+	var buf bytes.Buffer
+	cw := NewCSVWriter(&buf, CSVWriterOptions{BOM: true, LineEnding: "\n"})
+	_ = cw.WriteAll([][]string{{"城市"}, {"北京"}})
+	fmt.Printf("%q\n", buf.String())
+
+	// 结构体：表头取自标签，写出的内容可以用 CSVRecord.Decode 读回
+	buf.Reset()
+	cw = NewCSVWriter(&buf, CSVWriterOptions{LineEnding: "\n"})
+	_ = cw.WriteHeader(CSVOrder{})
+	_ = cw.WriteStruct(CSVOrder{ID: 1, Amount: MustParseDecimal("12.30"), Note: "a,b", Addr: DecodeAddress{City: "上海"}})
+	_ = cw.WriteStruct(&CSVOrder{ID: 2, Amount: MustParseDecimal("-0.5")})
+	_ = cw.Flush()
+	fmt.Print(buf.String())
+
+	cr, _ := NewCSVReader(&buf, CSVReaderOptions{})
+	rec, _ := cr.Read()
+	var back CSVOrder
+	_ = rec.Decode(&back)
+	fmt.Printf("%+v\n", back)
+
+	// 自引用的类型返回错误，而不是无限展开
+	err := NewCSVWriter(&buf, CSVWriterOptions{}).WriteHeader(CSVNode{})
+	fmt.Println(err)
+
+	// 多个 goroutine 并发追加同一文件：行不会交错，表头只写一次
+	dir, _ := os.MkdirTemp("", "csv-example")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.csv")
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 25 {
+				row := []string{fmt.Sprint(g), fmt.Sprint(i), strings.Repeat("x", 100)}
+				_ = AppendCSVFile(path, []string{"worker", "seq", "payload"}, [][]string{row}, CSVWriterOptions{})
+			}
+		})
+	}
+	wg.Wait()
+
+	cr, _ = OpenCSV(path, CSVReaderOptions{})
+	defer cr.Close()
+	rows := 0
+	for rec, err := range cr.Records() {
+		if err != nil || len(rec.Get("payload")) != 100 {
+			fmt.Println("corrupted row", rec, err)
+		}
+		rows++
+	}
+	fmt.Println(cr.Header(), rows)
+
+	// Output:
+	// "1,\"say \"\"hi\"\"\",\"a,b\",\"x\ny\",\" pad\"\r\n"
+	// "\"a\";\"b;c\"\n"
+	// "\ufeff城市\n北京\n"
+	// id,amount,note,addr.city,addr.zip
+	// 1,12.30,"a,b",上海,0
+	// 2,-0.5,,,0
+	// {ID:1 Amount:12.30 Note:a,b Addr:{City:上海 Zip:0} Secret:}
+	// csv: recursive struct type tools.CSVNode at "Next" cannot be flattened into columns
+	// [worker seq payload] 200
+}
+
+// =============================================================================
+// 示例 3：流式处理
+// =============================================================================
+
+// csvExampleStream 从字符串创建 CSVStream。
+func csvExampleStream(src string) *CSVStream {
+	cr, err := NewCSVReader(strings.NewReader(src), CSVReaderOptions{})
+	if err != nil {
+		panic(err)
+	}
+	return NewCSVStream(cr)
+}
+
+// printCSVStream 打印表头和每一行。
+func printCSVStream(s *CSVStream, err error) {
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(s.Header())
+	for rec, err := range s.Records() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(rec.Fields)
+	}
+}
+
+func Example_csvStream() {
+	const orders = "order_id,user_id,amount,status\n" +
+		"1,u1,20,paid\n" +
+		"2,u2,3.5,paid\n" +
+		"3,u1,100,refund\n" +
+		"4,u3,n/a,paid\n" +
+		"2,u2,3.5,paid\n" + // 重复的订单
+		"5,u9,-1,paid\n"
+	const users = "id,name\nu1,Tom\nu2,Ann\nu3,Bob\n"
+
+	// Select 按顺序保留列并可用 "原列名:新列名" 改名；Rename 只改名；不存在的列立即报错
+	s, _ := csvExampleStream(orders).Select("order_id:id", "amount")
+	s, err := s.Rename(map[string]string{"amount": "total"})
+	fmt.Println(s.Header(), err)
+	_, err = csvExampleStream(orders).Select("price")
+	fmt.Println(errors.Is(err, ErrCSVColumnNotFound))
+
+	// Dedupe 保留第一次出现的行
+	s, _ = csvExampleStream(orders).Dedupe("order_id")
+	n := 0
+	for range s.Records() {
+		n++
+	}
+	fmt.Println("dedupe:", n)
+
+	// 外部排序：MaxRows 为 2 时分块写入临时文件再归并；数值列中非数值排在最后，结束后临时文件被删除
+	tmp, _ := os.MkdirTemp("", "csv-sort")
+	defer os.RemoveAll(tmp)
+	s, err = csvExampleStream(orders).Sort(CSVSortOptions{
+		Keys:    []CSVSortKey{{Column: "amount", Numeric: true}, {Column: "order_id", Desc: true}},
+		MaxRows: 2,
+		TempDir: tmp,
+	})
+	printCSVStream(s, err)
+	left, _ := os.ReadDir(tmp)
+	fmt.Println("temp files:", len(left))
+
+	// 内连接只输出两侧都有的键；左连接保留左侧所有行，输出按键排序
+	s, err = csvExampleStream(orders).Join(csvExampleStream(users), CSVJoinOptions{On: []string{"user_id"}, RightOn: []string{"id"}})
+	printCSVStream(s, err)
+	s, err = csvExampleStream(orders).Join(csvExampleStream(users), CSVJoinOptions{
+		On: []string{"user_id"}, RightOn: []string{"id"}, Type: CSVJoinLeft, Sort: CSVSortOptions{MaxRows: 2, TempDir: tmp},
+	})
+	printCSVStream(s, err)
+
+	// WriteFile 先写临时文件再替换，新文件权限为 0644
+	out := filepath.Join(tmp, "paid.csv")
+	s = csvExampleStream(orders).Filter(func(rec *CSVRecord) bool { return rec.Get("status") == "paid" })
+	err = s.WriteFile(out, CSVWriterOptions{LineEnding: "\n"})
+	info, _ := os.Stat(out)
+	fmt.Println(info.Mode(), err)
+
+	// Output:
+	// [id total] <nil>
+	// true
+	// dedupe: 5
+	// [order_id user_id amount status]
+	// [5 u9 -1 paid]
+	// [2 u2 3.5 paid]
+	// [2 u2 3.5 paid]
+	// [1 u1 20 paid]
+	// [3 u1 100 refund]
+	// [4 u3 n/a paid]
+	// temp files: 0
+	// [order_id user_id amount status name]
+	// [1 u1 20 paid Tom]
+	// [3 u1 100 refund Tom]
+	// [2 u2 3.5 paid Ann]
+	// [2 u2 3.5 paid Ann]
+	// [4 u3 n/a paid Bob]
+	// [order_id user_id amount status name]
+	// [1 u1 20 paid Tom]
+	// [3 u1 100 refund Tom]
+	// [2 u2 3.5 paid Ann]
+	// [2 u2 3.5 paid Ann]
+	// [4 u3 n/a paid Bob]
+	// [5 u9 -1 paid ]
+	// -rw-r--r-- <nil>
+}
+
+// =============================================================================
+// 示例 4：拆分与合并
+// =============================================================================
+
+func Example_csvSplitMerge() {
+	dir, _ := os.MkdirTemp("", "csv-split")
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "orders.csv")
+	_ = os.WriteFile(src, []byte("id,city\n1,BJ\n2,SH\n3,bj\n4,\n5,_empty\n"), 0644)
+
+	// show 打印分片的文件名和内容
+	show := func(parts []string, err error) {
+		for _, p := range parts {
+			b, _ := os.ReadFile(p)
+			fmt.Printf("%s %q\n", filepath.Base(p), b)
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+	opts := CSVSplitOptions{OutDir: filepath.Join(dir, "parts"), Writer: CSVWriterOptions{LineEnding: "\n"}}
+
+	// 按行数拆分：每个分片都重复写入表头
+	show(SplitCSVByRows(src, 2, opts))
+
+	// 按大小拆分：每个分片（含表头）不超过 20 字节
+	show(SplitCSVBySize(src, 20, opts))
+
+	// 按列值拆分：仅大小写不同的值写入同一分片；空值与字面值 "_empty" 分开
+	show(SplitCSVByColumn(src, "city", opts))
+
+	// 合并：按列名对齐，输出列为并集，缺少的列为空值
+	a, b := filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv")
+	_ = os.WriteFile(a, []byte("id,name\n1,Tom\n"), 0644)
+	_ = os.WriteFile(b, []byte("name;age;id\nAnn;30;2\n"), 0644) // 分隔符各自识别
+	out := filepath.Join(dir, "all.csv")
+	err := MergeCSV([]string{a, b}, out, CSVMergeOptions{SourceColumn: "source", Writer: CSVWriterOptions{LineEnding: "\n"}})
+	show([]string{out}, err)
+
+	// Output:
+	// orders_part0001.csv "id,city\n1,BJ\n2,SH\n"
+	// orders_part0002.csv "id,city\n3,bj\n4,\n"
+	// orders_part0003.csv "id,city\n5,_empty\n"
+	// orders_part0001.csv "id,city\n1,BJ\n2,SH\n"
+	// orders_part0002.csv "id,city\n3,bj\n4,\n"
+	// orders_part0003.csv "id,city\n5,_empty\n"
+	// orders_BJ.csv "id,city\n1,BJ\n3,bj\n"
+	// orders_SH.csv "id,city\n2,SH\n"
+	// orders__empty.csv "id,city\n4,\n"
+	// orders___empty.csv "id,city\n5,_empty\n"
+	// all.csv "id,name,age,source\n1,Tom,,a.csv\n2,Ann,30,b.csv\n"
+}
@@ -0,0 +1,563 @@
+package tools
+
+// 定点小数与大数转换：金额等需要精确往返的数值不经过 float64。
+//
+//   - Decimal：系数（*big.Int）× 10^-scale，保留原文的小数位数（"12.30" 输出仍为 "12.30"）
+//   - 舍入模式：RoundHalfUp（四舍五入，默认）、RoundHalfEven（银行家舍入）、RoundHalfDown、
+//     RoundUp、RoundDown（截断）、RoundCeiling、RoundFloor
+//   - 与 Convert 集成：Convert[Decimal]、Convert[*big.Int]、Convert[*big.Rat]、Convert[*big.Float]，
+//     以及以它们为输入转为其他类型（超出范围返回 ErrConvertOverflow）
+//
+// 字符串解析与 Convert 一致，支持 ConvertOptions 中的千分位、货币符号、百分号等写法；
+// float64 输入按最短十进制表示转换（0.1 -> "0.1"，而不是其二进制近似值）。
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrConvertInexact 表示数值无法用有限位小数精确表示（如 1/3），需要指定位数和舍入模式。
+	ErrConvertInexact = errors.New("convert: value is not an exact decimal")
+
+	// ErrDivisionByZero 表示除数为 0。
+	ErrDivisionByZero = errors.New("decimal: division by zero")
+
+	// ErrDecimalScale 表示小数位数超出范围：运算结果超出 int32，或指定的位数超过 maxDecimalPlaces。
+	ErrDecimalScale = errors.New("decimal: scale out of range")
+)
+
+// maxDecimalExp 是解析科学计数法时允许的最大指数绝对值。"1e999999" 这样的输入
+// 会展开出百万位的系数，超出范围时返回 ErrConvertOverflow。
+const maxDecimalExp = 1e4
+
+// maxDecimalPlaces 是 Div、Round、DecimalFromRat 可以指定的最大小数位数，避免 10^places 占用过多内存。
+const maxDecimalPlaces = 1e4
+
+// RoundingMode 是舍入模式，零值为 RoundHalfUp。
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // 四舍五入（0.5 远离零）：2.5 -> 3，-2.5 -> -3
+	RoundHalfEven                     // 银行家舍入（0.5 取偶）：2.5 -> 2，3.5 -> 4
+	RoundHalfDown                     // 五舍六入（0.5 向零）：2.5 -> 2，2.51 -> 3
+	RoundUp                           // 远离零：2.1 -> 3，-2.1 -> -3
+	RoundDown                         // 向零截断：2.9 -> 2，-2.9 -> -2
+	RoundCeiling                      // 向正无穷：2.1 -> 3，-2.9 -> -2
+	RoundFloor                        // 向负无穷：2.9 -> 2，-2.1 -> -3
+)
+
+// Decimal 是不可变的定点小数，值为 coef × 10^-scale。零值表示 0。
+// 所有运算都返回新值，可以安全地在多个 goroutine 间共享。
+type Decimal struct {
+	coef  *big.Int // nil 表示 0，创建后不再修改
+	scale int32    // 小数位数，>= 0
+}
+
+// NewDecimal 返回 coef × 10^-scale，如 NewDecimal(1230, 2) 为 12.30。scale < 0 时视为 0。
+func NewDecimal(coef int64, scale int32) Decimal {
+	return NewDecimalFromBigInt(big.NewInt(coef), scale)
+}
+
+// NewDecimalFromBigInt 与 NewDecimal 相同，coef 会被复制。
+func NewDecimalFromBigInt(coef *big.Int, scale int32) Decimal {
+	return Decimal{coef: new(big.Int).Set(coef), scale: max(scale, 0)}
+}
+
+// ParseDecimal 按 DefaultConvertOptions 精确解析十进制字符串，保留原文的小数位数，如 "12.30"、"-1234.5"、"1.5e3"。
+// 千分位、货币符号、百分号等写法使用 ConvertWith[Decimal] 与 LenientConvertOptions（"12.5%" -> 0.125）。
+func ParseDecimal(s string) (Decimal, error) {
+	return Convert[Decimal](s)
+}
+
+// MustParseDecimal 与 ParseDecimal 相同，但解析失败时 panic。适用于常量。
+func MustParseDecimal(s string) Decimal {
+	return MustConvert[Decimal](s)
+}
+
+// DecimalFromRat 把 r 舍入为 places 位小数，places 最大为 10000。
+func DecimalFromRat(r *big.Rat, places int32, mode RoundingMode) Decimal {
+	places = min(max(places, 0), maxDecimalPlaces)
+	num := new(big.Int).Mul(r.Num(), pow10(places))
+	return Decimal{coef: divRound(num, r.Denom(), mode), scale: places}
+}
+
+// bigInt 返回系数，零值返回新的 0（调用方不得修改返回值）。
+func (d Decimal) bigInt() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Coef 返回系数的副本。
+func (d Decimal) Coef() *big.Int { return new(big.Int).Set(d.bigInt()) }
+
+// Scale 返回小数位数。
+func (d Decimal) Scale() int32 { return d.scale }
+
+// Sign 返回 -1、0 或 1。
+func (d Decimal) Sign() int { return d.bigInt().Sign() }
+
+// IsZero 判断是否为 0。
+func (d Decimal) IsZero() bool { return d.Sign() == 0 }
+
+// Cmp 比较 d 与 x 的数值（忽略小数位数，12.30 等于 12.3）：d < x 返回 -1，相等返回 0，d > x 返回 1。
+func (d Decimal) Cmp(x Decimal) int {
+	a, b := alignDecimals(d, x)
+	return a.Cmp(b)
+}
+
+// Neg 返回 -d。
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.bigInt()), scale: d.scale}
+}
+
+// Abs 返回 |d|。
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.bigInt()), scale: d.scale}
+}
+
+// Add 返回 d + x，小数位数取两者较大值。
+func (d Decimal) Add(x Decimal) Decimal {
+	a, b := alignDecimals(d, x)
+	return Decimal{coef: a.Add(a, b), scale: max(d.scale, x.scale)}
+}
+
+// Sub 返回 d - x，小数位数取两者较大值。
+func (d Decimal) Sub(x Decimal) Decimal {
+	a, b := alignDecimals(d, x)
+	return Decimal{coef: a.Sub(a, b), scale: max(d.scale, x.scale)}
+}
+
+// Mul 返回 d × x，结果精确，小数位数为两者之和（需要时用 Round 截取）。
+// 小数位数之和超出 int32 范围时返回 ErrDecimalScale。
+//
+// 使用示例：
+//
+//	amount, err := price.Mul(qty)
+func (d Decimal) Mul(x Decimal) (Decimal, error) {
+	scale, ok := addScale(d.scale, x.scale)
+	if !ok {
+		return Decimal{}, ErrDecimalScale
+	}
+	return Decimal{coef: new(big.Int).Mul(d.bigInt(), x.bigInt()), scale: scale}, nil
+}
+
+// Div 返回 d ÷ x，按 mode 舍入到 places 位小数。x 为 0 时返回 ErrDivisionByZero，
+// places 超过 10000 或 x 的小数位数与 places 之和超出 int32 范围时返回 ErrDecimalScale。
+//
+// 使用示例：
+//
+//	share, err := total.Div(NewDecimal(3, 0), 2, RoundHalfEven)
+func (d Decimal) Div(x Decimal, places int32, mode RoundingMode) (Decimal, error) {
+	if x.IsZero() {
+		return Decimal{}, ErrDivisionByZero
+	}
+	places = max(places, 0)
+	shift, ok := addScale(x.scale, places)
+	if !ok || places > maxDecimalPlaces {
+		return Decimal{}, ErrDecimalScale
+	}
+	// d/x = (dc × 10^xs) / (xc × 10^ds)，再乘 10^places 得到结果系数
+	num := new(big.Int).Mul(d.bigInt(), pow10(shift))
+	den := new(big.Int).Mul(x.bigInt(), pow10(d.scale))
+	return Decimal{coef: divRound(num, den, mode), scale: places}, nil
+}
+
+// Round 按 mode 舍入到 places 位小数；places 大于当前位数时补 0（"1.5" 保留 2 位为 "1.50"），
+// 最多补到 10000 位（数值不变）。
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	places = min(max(places, 0), max(d.scale, maxDecimalPlaces))
+	if places >= d.scale {
+		return Decimal{coef: new(big.Int).Mul(d.bigInt(), pow10(places-d.scale)), scale: places}
+	}
+	return Decimal{coef: divRound(d.bigInt(), pow10(d.scale-places), mode), scale: places}
+}
+
+// BigInt 按 mode 舍入为整数。
+func (d Decimal) BigInt(mode RoundingMode) *big.Int {
+	return d.Round(0, mode).Coef()
+}
+
+// Rat 返回精确的有理数。
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.bigInt(), pow10(d.scale))
+}
+
+// Float64 返回最接近的 float64，exact 表示是否无损。
+func (d Decimal) Float64() (f float64, exact bool) {
+	return d.Rat().Float64()
+}
+
+// String 返回十进制写法，保留全部小数位数（不使用科学计数法），如 "-12.30"。
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.bigInt()).String()
+	var b strings.Builder
+	if d.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	if d.scale == 0 {
+		b.WriteString(digits)
+		return b.String()
+	}
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	split := len(digits) - int(d.scale)
+	b.WriteString(digits[:split])
+	b.WriteByte('.')
+	b.WriteString(digits[split:])
+	return b.String()
+}
+
+// MarshalText 实现 encoding.TextMarshaler，格式同 String。
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，只接受 String 输出的写法（"-12.30"），
+// 不受 DefaultConvertOptions 影响；千分位、百分号等写法请先用 ConvertWith[Decimal] 解析。
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := parseCanonicalDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON 输出 JSON 字符串（"12.30"），避免 JavaScript 等按双精度解析时丢失精度。
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON 同时接受 JSON 字符串和数字（12.30 与 "12.30"），null 保持不变。
+// 字符串的格式同 UnmarshalText；数字按 JSON 语法解析，可以带指数（1.5e3）。
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	}
+	v, err := parseDecimalText(string(data))
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, data)
+	}
+	*d = v
+	return nil
+}
+
+// =============================================================================
+// 内部工具
+// =============================================================================
+
+// pow10 返回 10^n（n >= 0）。
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// addScale 返回两个小数位数（均 >= 0）之和，超出 int32 范围时 ok 为 false。
+func addScale(a, b int32) (int32, bool) {
+	s := int64(a) + int64(b)
+	return int32(s), s <= math.MaxInt32
+}
+
+// alignDecimals 把两个小数的系数对齐到相同的小数位数，返回新的 big.Int。
+func alignDecimals(x, y Decimal) (*big.Int, *big.Int) {
+	a := new(big.Int).Mul(x.bigInt(), pow10(max(y.scale-x.scale, 0)))
+	b := new(big.Int).Mul(y.bigInt(), pow10(max(x.scale-y.scale, 0)))
+	return a, b
+}
+
+// divRound 返回按 mode 舍入后的 num/den（den != 0）。
+func divRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	if den.Sign() < 0 {
+		num, den = new(big.Int).Neg(num), new(big.Int).Neg(den)
+	}
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int)) // 向零截断
+	if r.Sign() == 0 {
+		return q
+	}
+
+	sign := int64(num.Sign())
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	cmp := half.Cmp(den) // 余数与 0.5 的比较
+
+	away := false
+	switch mode {
+	case RoundHalfUp:
+		away = cmp >= 0
+	case RoundHalfEven:
+		away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+	case RoundHalfDown:
+		away = cmp > 0
+	case RoundUp:
+		away = true
+	case RoundDown:
+	case RoundCeiling:
+		away = sign > 0
+	case RoundFloor:
+		away = sign < 0
+	}
+	if away {
+		q.Add(q, big.NewInt(sign))
+	}
+	return q
+}
+
+// parseDecimalText 精确解析规整后的十进制文本：[-]digits[.digits][e[±]n]，|n| 不超过 maxDecimalExp。
+func parseDecimalText(s string) (Decimal, error) {
+	mant, expStr, hasExp := strings.Cut(strings.ToLower(s), "e")
+	exp := 0
+	if hasExp {
+		var err error
+		if exp, err = strconv.Atoi(expStr); err != nil {
+			return Decimal{}, ErrConvertSyntax
+		}
+		if exp > maxDecimalExp || exp < -maxDecimalExp {
+			return Decimal{}, ErrConvertOverflow
+		}
+	}
+
+	neg := false
+	if rest, ok := strings.CutPrefix(mant, "-"); ok {
+		neg, mant = true, rest
+	} else {
+		mant = strings.TrimPrefix(mant, "+")
+	}
+	intPart, frac, _ := strings.Cut(mant, ".")
+	digits := intPart + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, ErrConvertSyntax
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+	scale := len(frac) - exp
+	if scale > math.MaxInt32 {
+		return Decimal{}, ErrConvertOverflow
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// parseCanonicalDecimal 只接受 String 输出的写法：[-]digits[.digits]。
+func parseCanonicalDecimal(s string) (Decimal, error) {
+	intPart, frac, hasFrac := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if intPart == "" || (hasFrac && frac == "") || strings.Trim(intPart+frac, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrConvertSyntax, s)
+	}
+	return parseDecimalText(s)
+}
+
+// ratToDecimal 把有理数精确转换为小数；分母含 2 和 5 以外的质因数时返回 ErrConvertInexact。
+func ratToDecimal(r *big.Rat) (Decimal, error) {
+	den := new(big.Int).Set(r.Denom())
+	q, m := new(big.Int), new(big.Int)
+	countFactor := func(p int64) (n int32) {
+		for {
+			q.QuoRem(den, big.NewInt(p), m)
+			if m.Sign() != 0 {
+				return n
+			}
+			den.Set(q)
+			n++
+		}
+	}
+	twos, fives := countFactor(2), countFactor(5)
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return Decimal{}, ErrConvertInexact
+	}
+	scale := max(twos, fives)
+	num := new(big.Int).Mul(r.Num(), pow10(scale))
+	return Decimal{coef: num.Quo(num, r.Denom()), scale: scale}, nil
+}
+
+// floatToDecimal 按 float64 的最短十进制表示转换。
+func floatToDecimal(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, ErrConvertNaN
+	}
+	return parseDecimalText(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// =============================================================================
+// Convert 集成
+// =============================================================================
+
+// bigNumberSource 把 Decimal、*big.Rat、*big.Float 输入转为 int64、uint64 或 float64。
+// truncate 为 true（目标为整数）时先精确地向零截断，超出 uint64 范围返回 ErrConvertOverflow。
+func bigNumberSource(src any, truncate bool) (any, error) {
+	var r *big.Rat
+	switch val := src.(type) {
+	case Decimal:
+		r = val.Rat()
+	case *big.Rat:
+		r = val
+	case *big.Float:
+		if val.IsInf() {
+			return math.Inf(val.Sign()), nil
+		}
+		r, _ = val.Rat(nil)
+	default:
+		return nil, ErrConvertUnsupported
+	}
+
+	if truncate && !r.IsInt() {
+		r = new(big.Rat).SetInt(new(big.Int).Quo(r.Num(), r.Denom()))
+	}
+	if r.IsInt() {
+		n := r.Num()
+		switch {
+		case n.IsInt64():
+			return n.Int64(), nil
+		case n.IsUint64():
+			return n.Uint64(), nil
+		case truncate:
+			return nil, ErrConvertOverflow
+		}
+	}
+	f, _ := r.Float64()
+	if math.IsInf(f, 0) {
+		return nil, ErrConvertOverflow
+	}
+	return f, nil
+}
+
+// bigText 返回大数输入的十进制文本：*big.Rat 能精确表示为小数时输出小数，否则输出 "a/b"；
+// *big.Float 输出在其精度下能唯一确定该值的最短写法。
+func bigText(src any) (string, bool) {
+	switch val := src.(type) {
+	case Decimal:
+		return val.String(), true
+	case *big.Rat:
+		if d, err := ratToDecimal(val); err == nil {
+			return d.String(), true
+		}
+		return val.RatString(), true
+	case *big.Float:
+		return val.Text('f', -1), true
+	}
+	return "", false
+}
+
+// convertToDecimal 转换为 Decimal：字符串按 opts 精确解析，整数精确转换，
+// float64 和 *big.Float 按最短十进制表示转换，*big.Rat 须能精确表示为有限位小数。
+func convertToDecimal(src any, opts ConvertOptions) (Decimal, error) {
+	switch val := src.(type) {
+	case Decimal:
+		return val, nil
+	case string, []byte:
+		c, err := canonicalNumber(bytesOrString(val), opts)
+		if err != nil {
+			return Decimal{}, err
+		}
+		if strings.Contains(c, "/") {
+			r, ok := new(big.Rat).SetString(c)
+			if !ok {
+				return Decimal{}, ErrConvertSyntax
+			}
+			return ratToDecimal(r)
+		}
+		return parseDecimalText(c)
+	case *big.Rat:
+		return ratToDecimal(val)
+	case *big.Float:
+		if val.IsInf() {
+			return Decimal{}, ErrConvertNaN
+		}
+		return parseDecimalText(val.Text('f', -1))
+	}
+
+	n, err := numericSource(src)
+	if err != nil {
+		return Decimal{}, err
+	}
+	switch val := n.(type) {
+	case int64:
+		return NewDecimal(val, 0), nil
+	case uint64:
+		return Decimal{coef: new(big.Int).SetUint64(val)}, nil
+	default:
+		return floatToDecimal(n.(float64))
+	}
+}
+
+// convertToBigRat 转换为 *big.Rat，规则同 convertToDecimal，另外字符串可以是分数 "1/3"。
+func convertToBigRat(src any, opts ConvertOptions) (*big.Rat, error) {
+	switch val := src.(type) {
+	case *big.Rat:
+		return new(big.Rat).Set(val), nil
+	case string, []byte:
+		c, err := canonicalNumber(bytesOrString(val), opts)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(c, "/") {
+			r, ok := new(big.Rat).SetString(c)
+			if !ok {
+				return nil, ErrConvertSyntax
+			}
+			return r, nil
+		}
+	}
+	d, err := convertToDecimal(src, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.Rat(), nil
+}
+
+// convertToBigInt 转换为 *big.Int，非整数向零截断（与 Convert 的整数规则一致）。
+func convertToBigInt(src any, opts ConvertOptions) (*big.Int, error) {
+	r, err := convertToBigRat(src, opts)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Quo(r.Num(), r.Denom()), nil
+}
+
+// convertToBigFloat 转换为 *big.Float：float64 精确转换（保留 ±Inf），其他输入经 *big.Rat 转换，
+// 精度取分子分母位数与 64 中的较大者。
+func convertToBigFloat(src any, opts ConvertOptions) (*big.Float, error) {
+	switch val := src.(type) {
+	case *big.Float:
+		return new(big.Float).Copy(val), nil
+	case float64:
+		if math.IsNaN(val) {
+			return nil, ErrConvertNaN
+		}
+		return new(big.Float).SetFloat64(val), nil
+	}
+	r, err := convertToBigRat(src, opts)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Float).SetRat(r), nil
+}
+
+// bytesOrString 返回 string 或 []byte 的文本。
+func bytesOrString(v any) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v.(string)
+}
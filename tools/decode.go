@@ -0,0 +1,585 @@
+package tools
+
+// 结构体解码：把 map[string]any、url.Values、CSV 行等弱类型数据按标签填充到结构体，替代逐个字段 ToStr/ToInt。
+//
+// 字段匹配规则（按优先级）：
+//  1. `conv:"name"` 标签（标签名可通过 DecodeOptions.TagName 修改），"-" 表示忽略该字段
+//  2. `json:"name"` 标签
+//  3. 字段名本身
+//
+// 键名匹配不区分大小写（优先精确匹配）。字段值按 Convert 的规则做弱类型转换（"18" -> int、"yes" -> bool 等）。
+//
+// 标签选项：
+//   - `conv:"age,required"`：键不存在或值为空字符串时报错
+//   - `default:"18"`：键不存在或值为空字符串时使用的默认值，切片以 SliceSep 分隔，如 `default:"a,b"`
+//
+// 复合类型：
+//   - 嵌套结构体：值可以是子 map，也可以用 "addr.city" 形式的带前缀平铺键（适合 CSV 表头和表单）
+//   - 匿名嵌入结构体：字段提升到外层，与 encoding/json 一致
+//   - 切片：值可以是任意切片，也可以是按 SliceSep 分隔的字符串；url.Values 的多值直接对应切片
+//   - map[string]T：值为 key 为字符串的 map
+//   - 指针：按需分配；实现 encoding.TextUnmarshaler 的类型以字符串解码
+//   - Decimal、big.Int、big.Rat、big.Float（及其指针）：按 Convert 的规则精确转换，支持 DecodeOptions.Number
+//
+// 所有字段都会尝试解码，失败的字段汇总为 DecodeErrors 返回，其余字段照常填充。
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrFieldRequired 表示 required 字段缺失或为空，可用 errors.Is 判断。
+var ErrFieldRequired = errors.New("decode: required field is missing")
+
+// FieldError 是单个字段的解码错误。
+type FieldError struct {
+	Path string // 字段路径，如 "Addr.City"、"Tags[2]"
+	Key  string // 对应的输入键名，如 "addr.city"
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s (key %q): %v", e.Path, e.Key, e.Err)
+}
+
+// Unwrap 支持 errors.Is / errors.As 访问原始错误（如 ErrConvertOverflow）。
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// DecodeErrors 汇总一次解码中所有字段的错误。
+type DecodeErrors []*FieldError
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("decode: %d field error(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap 支持 errors.Is 匹配任意字段的错误。
+func (e DecodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// DecodeOptions 配置解码行为。
+type DecodeOptions struct {
+	// TagName 是字段名标签。
+	// 默认值："conv"（未设置该标签的字段继续尝试 json 标签）。
+	TagName string
+
+	// SliceSep 是字符串转切片时的分隔符，分隔后各元素会去除首尾空白。
+	// 默认值：","。
+	SliceSep string
+
+	// Number 配置数字字符串的解析方式（千分位、货币符号、中文数字等），可设为 &LenientConvertOptions。
+	// 默认值：DefaultConvertOptions，只接受普通十进制写法。
+	Number *ConvertOptions
+}
+
+// setDefaults 为未设置的字段填充默认值。
+func (o *DecodeOptions) setDefaults() {
+	if o.TagName == "" {
+		o.TagName = "conv"
+	}
+	if o.SliceSep == "" {
+		o.SliceSep = ","
+	}
+	if o.Number == nil {
+		number := DefaultConvertOptions
+		o.Number = &number
+	}
+}
+
+// Decode 使用默认选项把 src 解码到 dst（必须是非 nil 的结构体指针）。
+//
+// src 支持 map[string]any、map[string]string、url.Values（及 map[string][]string），
+// 以及其他 key 为字符串的 map。
+//
+// 使用示例：
+//
+//	type User struct {
+//	    ID    int64     `conv:"id,required"`
+//	    Name  string    `conv:"name"`
+//	    Age   int       `conv:"age" default:"18"`
+//	    Tags  []string  `conv:"tags"`
+//	    Addr  Address   `conv:"addr"` // 读取 addr.city / addr.zip 或子 map
+//	}
+//	var u User
+//	err := Decode(map[string]any{"id": "42", "tags": "a,b", "addr.city": "上海"}, &u)
+func Decode(src any, dst any) error {
+	return DecodeWith(src, dst, DecodeOptions{})
+}
+
+// DecodeValues 把表单或查询参数解码到 dst，等价于 Decode(values, dst)。
+func DecodeValues(values url.Values, dst any) error {
+	return DecodeWith(values, dst, DecodeOptions{})
+}
+
+// DecodeCSVRow 把一行 CSV 数据按表头映射解码到 dst。mapping 是 CSVFieldMapper 以表头模式生成的映射，
+// 表头可用 "addr.city" 的形式填充嵌套结构体。
+//
+// 使用示例：
+//
+//	mapping := make(map[string]int)
+//	_, _ = CSVFieldMapper(header, &mapping, true, "")
+//	for _, row := range rows {
+//	    var u User
+//	    if err := DecodeCSVRow(row, mapping, &u); err != nil { ... }
+//	}
+func DecodeCSVRow(row []string, mapping map[string]int, dst any) error {
+	src := make(map[string]any, len(mapping))
+	for name := range mapping {
+		// 行比表头短时缺失的列视为不存在，由 default/required 处理
+		if v, err := CSVFieldMapper(row, &mapping, false, name); err == nil {
+			src[name] = v
+		}
+	}
+	return DecodeWith(src, dst, DecodeOptions{})
+}
+
+// DecodeWith 与 Decode 相同，但使用指定的选项。
+func DecodeWith(src any, dst any, opts DecodeOptions) error {
+	opts.setDefaults()
+
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode: dst must be a non-nil pointer to struct, got %T", dst)
+	}
+	m, ok := decodeSourceMap(src)
+	if !ok {
+		return fmt.Errorf("decode: unsupported source type %T", src)
+	}
+
+	d := &decoder{opts: opts}
+	d.decodeStruct(m, rv.Elem(), "", "")
+	if len(d.errs) > 0 {
+		return d.errs
+	}
+	return nil
+}
+
+// decoder 保存一次解码的选项和累积的字段错误。
+type decoder struct {
+	opts DecodeOptions
+	errs DecodeErrors
+}
+
+func (d *decoder) fail(path, key string, err error) {
+	d.errs = append(d.errs, &FieldError{Path: path, Key: key, Err: err})
+}
+
+// decodeSourceMap 把输入统一为 map[string]any：url.Values 的单值展开为字符串，多值保留为 []string。
+func decodeSourceMap(src any) (map[string]any, bool) {
+	switch m := src.(type) {
+	case map[string]any:
+		return m, true
+	case url.Values:
+		return valuesToMap(m), true
+	case map[string][]string:
+		return valuesToMap(m), true
+	case map[string]string:
+		out := make(map[string]any, len(m))
+		for k, v := range m {
+			out[k] = v
+		}
+		return out, true
+	}
+
+	rv := reflect.ValueOf(src)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	out := make(map[string]any, rv.Len())
+	for it := rv.MapRange(); it.Next(); {
+		out[it.Key().String()] = it.Value().Interface()
+	}
+	return out, true
+}
+
+func valuesToMap(values map[string][]string) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, vs := range values {
+		if len(vs) == 1 {
+			out[k] = vs[0]
+		} else {
+			out[k] = vs
+		}
+	}
+	return out
+}
+
+// lookupKey 查找键，优先精确匹配，其次不区分大小写匹配。
+func lookupKey(m map[string]any, key string) (any, string, bool) {
+	if v, ok := m[key]; ok {
+		return v, key, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, k, true
+		}
+	}
+	return nil, key, false
+}
+
+// prefixedMap 收集 "prefix.xxx" 形式的平铺键，去掉前缀后组成子 map（前缀不区分大小写）。
+func prefixedMap(m map[string]any, prefix string) map[string]any {
+	var sub map[string]any
+	n := len(prefix) + 1
+	for k, v := range m {
+		if len(k) > n && k[len(prefix)] == '.' && strings.EqualFold(k[:len(prefix)], prefix) {
+			if sub == nil {
+				sub = make(map[string]any)
+			}
+			sub[k[n:]] = v
+		}
+	}
+	return sub
+}
+
+// structFieldKey 按 tagName 标签、json 标签、字段名的顺序解析字段的键名和标签选项，解码和写出 CSV 共用。
+// skip 为 true 表示忽略该字段。
+func structFieldKey(f reflect.StructField, tagName string) (key string, required, skip bool) {
+	tag, ok := f.Tag.Lookup(tagName)
+	if !ok {
+		tag = f.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	name, rest, _ := strings.Cut(tag, ",")
+	for opt := range strings.SplitSeq(rest, ",") {
+		if strings.TrimSpace(opt) == "required" {
+			required = true
+		}
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, required, false
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	decimalType         = reflect.TypeFor[Decimal]()
+	bigIntType          = reflect.TypeFor[big.Int]()
+	bigRatType          = reflect.TypeFor[big.Rat]()
+	bigFloatType        = reflect.TypeFor[big.Float]()
+)
+
+// isConvertStruct 判断 t 是否为由 convertReflect 直接转换的结构体类型（它们虽实现了 TextUnmarshaler，
+// 但按 Convert 的规则解析更宽松）。
+func isConvertStruct(t reflect.Type) bool {
+	switch t {
+	case timeType, decimalType, bigIntType, bigRatType, bigFloatType:
+		return true
+	}
+	return false
+}
+
+// decodeStruct 把 m 解码到结构体 sv。path/keyPrefix 用于错误信息中的字段路径和键名。
+func (d *decoder) decodeStruct(m map[string]any, sv reflect.Value, path, keyPrefix string) {
+	st := sv.Type()
+	for i := range st.NumField() {
+		f := st.Field(i)
+		fv := sv.Field(i)
+
+		// 匿名嵌入结构体：字段提升到当前层级
+		if f.Anonymous && f.Tag.Get(d.opts.TagName) == "" && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				if f.Type.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						if !fv.CanSet() {
+							continue
+						}
+						fv.Set(reflect.New(ft))
+					}
+					fv = fv.Elem()
+				}
+				d.decodeStruct(m, fv, path, keyPrefix)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		key, required, skip := structFieldKey(f, d.opts.TagName)
+		if skip {
+			continue
+		}
+		fieldPath := joinPath(path, f.Name)
+		fullKey := keyPrefix + key
+
+		raw, matched, found := lookupKey(m, key)
+		if found {
+			fullKey = keyPrefix + matched
+		}
+
+		// 嵌套结构体：子 map 或带前缀的平铺键
+		if isNestedStruct(f.Type) {
+			sub, ok := raw.(map[string]any)
+			if found && !ok {
+				if sub, ok = decodeSourceMap(raw); !ok {
+					d.fail(fieldPath, fullKey, fmt.Errorf("%w: %T is not a map", ErrConvertUnsupported, raw))
+					continue
+				}
+			}
+			if !found {
+				sub = prefixedMap(m, key)
+			}
+			if sub == nil && f.Type.Kind() == reflect.Pointer {
+				if required {
+					d.fail(fieldPath, fullKey, ErrFieldRequired)
+				}
+				continue // 没有任何数据时保持 nil 指针
+			}
+			target := fv
+			if f.Type.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(f.Type.Elem()))
+				}
+				target = fv.Elem()
+			}
+			d.decodeStruct(sub, target, fieldPath, fullKey+".")
+			continue
+		}
+
+		if !found || isEmptyString(raw) {
+			if def, ok := f.Tag.Lookup("default"); ok {
+				raw = def
+			} else {
+				if required {
+					d.fail(fieldPath, fullKey, ErrFieldRequired)
+				}
+				continue
+			}
+		}
+		d.decodeValue(raw, fv, fieldPath, fullKey)
+	}
+}
+
+// isNestedStruct 判断字段是否按嵌套结构体解码（time.Time 和实现 TextUnmarshaler 的类型除外）。
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func isEmptyString(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// decodeValue 把 raw 转换后写入 fv，失败时记录字段错误。
+func (d *decoder) decodeValue(raw any, fv reflect.Value, path, key string) {
+	if raw == nil {
+		return // 显式的 null 保持零值
+	}
+	t := fv.Type()
+
+	// 指针：分配后解码到指向的值
+	if t.Kind() == reflect.Pointer {
+		elem := reflect.New(t.Elem())
+		before := len(d.errs)
+		d.decodeValue(raw, elem.Elem(), path, key)
+		if len(d.errs) == before {
+			fv.Set(elem)
+		}
+		return
+	}
+
+	// 类型完全一致时直接赋值
+	if rv := reflect.ValueOf(raw); rv.Type().AssignableTo(t) {
+		fv.Set(rv)
+		return
+	}
+
+	// time.Time、big.Int 等也实现了 TextUnmarshaler，按 Convert 的规则解析更宽松
+	if !isConvertStruct(t) && reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		s, err := Convert[string](raw)
+		if err == nil {
+			err = fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+		if err != nil {
+			d.fail(path, key, err)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if reflect.TypeOf(raw).Implements(t) {
+			fv.Set(reflect.ValueOf(raw))
+		} else {
+			d.fail(path, key, fmt.Errorf("%w: %T does not implement %v", ErrConvertUnsupported, raw, t))
+		}
+		return
+
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			d.decodeSlice(raw, fv, path, key)
+			return
+		}
+
+	case reflect.Map:
+		d.decodeMap(raw, fv, path, key)
+		return
+	}
+
+	v, err := convertReflect(raw, t, *d.opts.Number)
+	if err != nil {
+		d.fail(path, key, err)
+		return
+	}
+	fv.Set(v)
+}
+
+// decodeSlice 解码切片：值可以是任意切片/数组，也可以是按 SliceSep 分隔的字符串。
+func (d *decoder) decodeSlice(raw any, fv reflect.Value, path, key string) {
+	var items []any
+	switch val := raw.(type) {
+	case string:
+		if strings.TrimSpace(val) != "" {
+			for s := range strings.SplitSeq(val, d.opts.SliceSep) {
+				items = append(items, strings.TrimSpace(s))
+			}
+		}
+	default:
+		rv := reflect.ValueOf(raw)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			items = []any{raw} // 单个值视为只有一个元素的切片
+			break
+		}
+		items = make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+	}
+
+	out := reflect.MakeSlice(fv.Type(), len(items), len(items))
+	for i, item := range items {
+		d.decodeValue(item, out.Index(i), fmt.Sprintf("%s[%d]", path, i), key)
+	}
+	fv.Set(out)
+}
+
+// decodeMap 解码 key 为字符串（或可由字符串转换）的 map。
+func (d *decoder) decodeMap(raw any, fv reflect.Value, path, key string) {
+	rv := reflect.ValueOf(raw)
+	if rv.Kind() != reflect.Map {
+		d.fail(path, key, fmt.Errorf("%w: %T is not a map", ErrConvertUnsupported, raw))
+		return
+	}
+	t := fv.Type()
+	out := reflect.MakeMapWithSize(t, rv.Len())
+	for it := rv.MapRange(); it.Next(); {
+		k, err := convertReflect(it.Key().Interface(), t.Key(), *d.opts.Number)
+		if err != nil {
+			d.fail(path, key, err)
+			continue
+		}
+		v := reflect.New(t.Elem()).Elem()
+		before := len(d.errs)
+		d.decodeValue(it.Value().Interface(), v, fmt.Sprintf("%s[%v]", path, it.Key().Interface()), key)
+		if len(d.errs) == before {
+			out.SetMapIndex(k, v)
+		}
+	}
+	fv.Set(out)
+}
+
+// convertReflect 以 ConvertWith 的规则把 v 转换为类型 t 的值，t 必须是 Convertible 支持的类型。
+func convertReflect(v any, t reflect.Type, opts ConvertOptions) (reflect.Value, error) {
+	var out any
+	var err error
+	switch t {
+	case timeType:
+		out, err = ConvertWith[time.Time](v, opts)
+	case durationType:
+		out, err = ConvertWith[time.Duration](v, opts)
+	case decimalType:
+		out, err = ConvertWith[Decimal](v, opts)
+	case bigIntType, bigRatType, bigFloatType:
+		// 字段是 big.Int 等值类型，转换得到的指针取其指向的值（新分配，不与其他值共享）
+		var p any
+		switch t {
+		case bigIntType:
+			p, err = ConvertWith[*big.Int](v, opts)
+		case bigRatType:
+			p, err = ConvertWith[*big.Rat](v, opts)
+		default:
+			p, err = ConvertWith[*big.Float](v, opts)
+		}
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(p).Elem(), nil
+	default:
+		switch t.Kind() {
+		case reflect.Int:
+			out, err = ConvertWith[int](v, opts)
+		case reflect.Int8:
+			out, err = ConvertWith[int8](v, opts)
+		case reflect.Int16:
+			out, err = ConvertWith[int16](v, opts)
+		case reflect.Int32:
+			out, err = ConvertWith[int32](v, opts)
+		case reflect.Int64:
+			out, err = ConvertWith[int64](v, opts)
+		case reflect.Uint:
+			out, err = ConvertWith[uint](v, opts)
+		case reflect.Uint8:
+			out, err = ConvertWith[uint8](v, opts)
+		case reflect.Uint16:
+			out, err = ConvertWith[uint16](v, opts)
+		case reflect.Uint32:
+			out, err = ConvertWith[uint32](v, opts)
+		case reflect.Uint64:
+			out, err = ConvertWith[uint64](v, opts)
+		case reflect.Float32:
+			out, err = ConvertWith[float32](v, opts)
+		case reflect.Float64:
+			out, err = ConvertWith[float64](v, opts)
+		case reflect.Bool:
+			out, err = ConvertWith[bool](v, opts)
+		case reflect.String:
+			out, err = ConvertWith[string](v, opts)
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				out, err = Convert[[]byte](v)
+				break
+			}
+			fallthrough
+		default:
+			return reflect.Value{}, fmt.Errorf("%w: cannot decode into %v", ErrConvertUnsupported, t)
+		}
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(out).Convert(t), nil
+}
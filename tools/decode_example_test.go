@@ -0,0 +1,97 @@
+package tools
+
+// 结构体解码示例，覆盖以下场景：
+//   - Decode：嵌套结构体（子 map 与 "addr.city" 平铺键）、default、required 与 DecodeErrors 汇总
+//   - DecodeValues：表单多值对应切片、未设置的字段使用默认值
+//   - DecodeCSVRow：按 CSVFieldMapper 生成的表头映射解码 CSV 行
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// DecodeAddress 是嵌套结构体，可由子 map 或 "addr.city" 形式的键填充。
+type DecodeAddress struct {
+	City string `conv:"city"`
+	Zip  int    `conv:"zip"`
+}
+
+// DecodeUser 演示各类标签。
+type DecodeUser struct {
+	ID   int64         `conv:"id,required"`
+	Name string        `json:"name"`
+	Age  int           `conv:"age" default:"18"`
+	Tags []string      `conv:"tags"`
+	Addr DecodeAddress `conv:"addr"`
+}
+
+func ExampleDecode() {
+	// 带前缀的平铺键填充嵌套结构体；age 缺失时使用 default
+	var u DecodeUser
+	err := Decode(map[string]any{
+		"id":        "42",
+		"NAME":      "Tom", // 键名不区分大小写
+		"tags":      "a, b",
+		"addr.city": "上海",
+		"addr.zip":  "200000",
+	}, &u)
+	fmt.Printf("%+v %v\n", u, err)
+
+	// 嵌套结构体也可以用子 map 填充
+	var v DecodeUser
+	err = Decode(map[string]any{"id": 1, "addr": map[string]any{"city": "杭州", "zip": 310000}}, &v)
+	fmt.Printf("%+v %v\n", v.Addr, err)
+
+	// 失败的字段汇总为 DecodeErrors，其余字段照常填充
+	var bad DecodeUser
+	err = Decode(map[string]any{"name": "Ann", "age": "abc", "addr.zip": "1e20"}, &bad)
+	var de DecodeErrors
+	if errors.As(err, &de) {
+		for _, fe := range de {
+			fmt.Println(fe.Path, fe.Key)
+		}
+	}
+	fmt.Println("required:", errors.Is(err, ErrFieldRequired), "overflow:", errors.Is(err, ErrConvertOverflow), "name:", bad.Name)
+
+	// Output:
+	// {ID:42 Name:Tom Age:18 Tags:[a b] Addr:{City:上海 Zip:200000}} <nil>
+	// {City:杭州 Zip:310000} <nil>
+	// ID id
+	// Age age
+	// Addr.Zip addr.zip
+	// required: true overflow: true name: Ann
+}
+
+func ExampleDecodeValues() {
+	// 查询参数的多值直接对应切片
+	q, _ := url.ParseQuery("id=7&tags=go&tags=csv&addr.city=北京")
+	var u DecodeUser
+	err := DecodeValues(q, &u)
+	fmt.Printf("%+v %v\n", u, err)
+
+	// Output:
+	// {ID:7 Name: Age:18 Tags:[go csv] Addr:{City:北京 Zip:0}} <nil>
+}
+
+func ExampleDecodeCSVRow() {
+	mapping := make(map[string]int)
+	_, _ = CSVFieldMapper([]string{"id", "name", "addr.city", "addr.zip"}, &mapping, true, "")
+
+	for _, row := range [][]string{
+		{"1", "Tom", "上海", "200000"},
+		{"", "Ann", "北京", "x"},
+	} {
+		var u DecodeUser
+		err := DecodeCSVRow(row, mapping, &u)
+		fmt.Printf("%+v\n", u)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	// Output:
+	// {ID:1 Name:Tom Age:18 Tags:[] Addr:{City:上海 Zip:200000}}
+	// {ID:0 Name:Ann Age:18 Tags:[] Addr:{City:北京 Zip:0}}
+	// decode: 2 field error(s): field ID (key "id"): decode: required field is missing; field Addr.Zip (key "addr.zip"): convert string(x) to int: convert: invalid syntax
+}
@@ -0,0 +1,222 @@
+package tools
+
+// 时间解析：自动识别常见时间格式，解析失败返回错误（不会用当前时间代替）。
+//
+// 支持的输入：
+//   - RFC3339/RFC3339Nano："2024-01-02T15:04:05+08:00"、"2024-01-02T15:04:05.123Z"
+//   - RFC1123/RFC1123Z/RFC850/RFC822、ANSIC、UnixDate 以及 time.Time.String() 的输出
+//   - 横线、斜线、点分隔："2024-01-02 15:04:05"、"2024/1/2 15:04"、"2024.01.02"，可带小数秒和时区偏移
+//   - 中文："2024年1月2日 15时04分05秒"、"2024年01月02日15:04"、"2024年1月"，全角数字亦可
+//   - 紧凑格式："2024"、"202401"、"20240102"、"20240102150405"，不是合法日期时返回错误（如 "20240230"）
+//   - Unix 时间戳：至少 9 位整数，按数值大小识别秒、毫秒、微秒、纳秒（可带小数秒，如 "1700000000.5"）
+//
+// 时区：字符串自带偏移或时区名时以字符串为准（末尾可带 IANA 时区名，如 "2024-01-02 15:04 Asia/Shanghai"）；
+// 否则按调用方指定的 loc 解析（ParseTime 使用本地时区）。
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTimeFormat 表示无法识别的时间格式。
+var ErrTimeFormat = errors.New("timeparse: unrecognized time format")
+
+// timeLayouts 是依次尝试的格式。中文格式先归一化为横线格式再匹配。
+// 数字月、日、小时（"1"、"2"、"15"）同时接受一位和两位写法；秒后面的小数秒无需写在格式中。
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999 -0700 MST", // time.Time.String()
+	"2006-1-2 15:04:05Z07:00",
+	"2006-1-2 15:04:05 Z07:00",
+	"2006-1-2 15:04:05 -0700",
+	"2006-1-2 15:04:05 MST",
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2 15",
+	"2006-1-2",
+	"2006-1",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.RubyDate,
+	time.UnixDate,
+	time.ANSIC,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006",
+}
+
+// chineseTimeReplacer 把中文日期归一化为横线格式："2024年1月2日 15时04分05秒" -> "2024-1-2 15:04:05"。
+var chineseTimeReplacer = strings.NewReplacer(
+	"年", "-", "月", "-", "日", " ", "号", " ",
+	"时", ":", "點", ":", "点", ":", "分", ":", "秒", "",
+)
+
+// ParseTime 自动识别格式解析时间字符串，不含时区信息的输入按本地时区解析。
+//
+// 使用示例：
+//
+//	t, err := ParseTime("2024年1月2日 15时04分")
+//	t, err := ParseTime("Tue, 02 Jan 2024 15:04:05 GMT")
+//	t, err := ParseTime("1704179045000") // 毫秒时间戳
+func ParseTime(s string) (time.Time, error) {
+	return ParseTimeIn(s, time.Local)
+}
+
+// ParseTimeIn 与 ParseTime 相同，但不含时区信息的输入按 loc 解析（nil 表示 UTC）。
+// layouts 为额外的格式，优先于内置格式尝试。
+//
+// 使用示例：
+//
+//	sh, _ := time.LoadLocation("Asia/Shanghai")
+//	t, err := ParseTimeIn("2024-01-02 15:04:05", sh)
+//	t, err := ParseTimeIn("02|01|2024", time.UTC, "02|01|2006")
+func ParseTimeIn(s string, loc *time.Location, layouts ...string) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	raw := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("%w: empty string", ErrTimeFormat)
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	s = toHalfWidth(s)
+	// time.Time.String() 可能带单调时钟读数 " m=+0.000000001"
+	if i := strings.Index(s, " m="); i > 0 {
+		s = s[:i]
+	}
+	// 末尾的 IANA 时区名
+	if i := strings.LastIndexByte(s, ' '); i > 0 && strings.Contains(s[i+1:], "/") {
+		if l, err := time.LoadLocation(s[i+1:]); err == nil {
+			s, loc = strings.TrimSpace(s[:i]), l
+		}
+	}
+
+	if t, ok, err := parseCompactOrUnix(s, loc); ok {
+		return t, err
+	}
+
+	if strings.Contains(s, "年") {
+		s = strings.TrimRight(strings.Join(strings.Fields(chineseTimeReplacer.Replace(s)), " "), "-: ")
+	} else if !strings.ContainsAny(s, ",") && strings.Count(s, ".") >= 2 {
+		// "2024.01.02 15:04:05.123"：只替换日期部分的点
+		date, rest, _ := strings.Cut(s, " ")
+		s = strings.TrimSpace(strings.ReplaceAll(date, ".", "-") + " " + rest)
+	}
+	s = strings.Replace(s, "/", "-", 2)
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrTimeFormat, raw)
+}
+
+// compactTimeLayouts 是纯数字输入按位数对应的紧凑格式。
+var compactTimeLayouts = map[int]string{
+	4:  "2006",
+	6:  "200601",
+	8:  "20060102",
+	14: "20060102150405",
+}
+
+// minUnixDigits 是作为 Unix 时间戳接受的最少整数位数。1e8 秒为 1973 年，
+// 更短的纯数字（如 "2024"、"12345"）更可能是年份或其他数据，不当作时间戳。
+const minUnixDigits = 9
+
+// parseCompactOrUnix 解析纯数字输入：4、6、8、14 位分别视为 "2006"、"200601"、"20060102"、"20060102150405"，
+// 不是合法日期时返回错误（不会退回按时间戳解析）；其余不少于 minUnixDigits 位的视为 Unix 时间戳。
+// 输入不是纯数字时 ok 为 false。
+func parseCompactOrUnix(s string, loc *time.Location) (t time.Time, ok bool, err error) {
+	digits := strings.TrimPrefix(s, "-")
+	intPart, frac, hasFrac := strings.Cut(digits, ".")
+	if intPart == "" || strings.Trim(intPart, "0123456789") != "" ||
+		(hasFrac && (frac == "" || strings.Trim(frac, "0123456789") != "")) {
+		return time.Time{}, false, nil
+	}
+	invalid := fmt.Errorf("%w: %q", ErrTimeFormat, s)
+
+	if layout, compact := compactTimeLayouts[len(s)]; compact && !hasFrac && s == digits {
+		if t, err = time.ParseInLocation(layout, s, loc); err != nil {
+			return time.Time{}, true, invalid
+		}
+		return t, true, nil
+	}
+	if len(intPart) < minUnixDigits {
+		return time.Time{}, true, invalid
+	}
+
+	if !hasFrac {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, true, invalid
+		}
+		return unixTime(n).In(loc), true, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, true, invalid
+	}
+	if t, err = unixFloatTime(f); err != nil {
+		return time.Time{}, true, invalid
+	}
+	return t.In(loc), true, nil
+}
+
+// unixTime 按数值大小识别时间戳单位：绝对值小于 1e11 为秒（约到 5138 年），
+// 小于 1e14 为毫秒，小于 1e17 为微秒，否则为纳秒。
+func unixTime(n int64) time.Time {
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(n, 0)
+	case abs < 1e14:
+		return time.UnixMilli(n)
+	case abs < 1e17:
+		return time.UnixMicro(n)
+	default:
+		return time.Unix(0, n)
+	}
+}
+
+// unixFloatTime 与 unixTime 相同，但支持小数部分。
+func unixFloatTime(f float64) (time.Time, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, ErrConvertNaN
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return time.Time{}, ErrConvertOverflow
+	}
+	switch abs := math.Abs(f); {
+	case abs < 1e11:
+	case abs < 1e14:
+		f /= 1e3
+	case abs < 1e17:
+		f /= 1e6
+	default:
+		f /= 1e9
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))), nil
+}
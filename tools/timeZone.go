@@ -0,0 +1,456 @@
+package tools
+
+// 时区与相对时间工具：
+//
+//   - LoadZone：按 IANA 名称（"Asia/Shanghai"）或固定偏移（"+08:00"、"UTC+8"）加载时区，结果带缓存
+//   - InZone / FormatTimeIn / TimeStampToStrIn：时区转换与按任意格式输出
+//     （格式可以是 Go 格式 "2006-01-02"，也可以是 "yyyy-MM-dd HH:mm:ss" 风格）
+//   - StartOfDay / EndOfDay / StartOfWeek / EndOfWeek / StartOfMonth / EndOfMonth：在指定时区计算边界，夏令时安全
+//   - ParseHumanDuration：解析 "3d4h"、"1.5小时"、"2周"、"三天"、"1 hour 30 minutes"
+//   - RelativeTime / TimeAgo：输出 "5 分钟前"、"3 天后"、"5 minutes ago"、"in 2 hours"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ErrDurationSyntax 表示无法识别的时长写法。
+var ErrDurationSyntax = errors.New("duration: invalid syntax")
+
+// zoneCache 缓存已加载的时区，time.LoadLocation 每次调用都会读取时区数据库。
+var zoneCache sync.Map // map[string]*time.Location
+
+// LoadZone 加载时区，支持：
+//   - IANA 名称："Asia/Shanghai"、"America/New_York"，以及 "UTC"、"Local"
+//   - 固定偏移："+08:00"、"-0530"、"UTC+8"、"GMT-05:30"
+//
+// 空字符串返回 UTC（与 time.LoadLocation 一致）。
+func LoadZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if loc, ok := zoneCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := parseFixedZone(name)
+	if err != nil {
+		if loc, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("load zone %q: %w", name, err)
+		}
+	}
+	zoneCache.Store(name, loc)
+	return loc, nil
+}
+
+// parseFixedZone 解析固定偏移时区，如 "+08:00"、"-0530"、"UTC+8"、"GMT-05:30"。
+func parseFixedZone(name string) (*time.Location, error) {
+	s := name
+	for _, prefix := range []string{"UTC", "GMT"} {
+		if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			s = s[len(prefix):]
+			break
+		}
+	}
+	if s == "" || (s[0] != '+' && s[0] != '-') {
+		return nil, errors.New("not a fixed offset")
+	}
+
+	sign := 1
+	if s[0] == '-' {
+		sign = -1
+	}
+	hh, mm, hasColon := strings.Cut(s[1:], ":")
+	if !hasColon && len(hh) == 4 {
+		hh, mm = hh[:2], hh[2:]
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil || h > 14 || len(hh) > 2 {
+		return nil, errors.New("invalid offset hours")
+	}
+	m := 0
+	if mm != "" {
+		if m, err = strconv.Atoi(mm); err != nil || m >= 60 || len(mm) != 2 {
+			return nil, errors.New("invalid offset minutes")
+		}
+	}
+	return time.FixedZone(name, sign*(h*3600+m*60)), nil
+}
+
+// InZone 把 t 转换到指定时区（表示同一时刻）。
+//
+// 使用示例：
+//
+//	ny, err := InZone(time.Now(), "America/New_York")
+func InZone(t time.Time, zone string) (time.Time, error) {
+	loc, err := LoadZone(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// FormatTimeIn 把 t 转换到指定时区后按 layout 格式化。zone 为空时保持 t 自身的时区。
+// layout 可以是 Go 格式（"2006-01-02 15:04:05"）或 "yyyy-MM-dd HH:mm:ss" 风格，见 toGoLayout。
+//
+// 使用示例：
+//
+//	s, err := FormatTimeIn(t, "yyyy年MM月dd日 HH:mm", "Asia/Tokyo")
+func FormatTimeIn(t time.Time, layout, zone string) (string, error) {
+	if zone != "" {
+		var err error
+		if t, err = InZone(t, zone); err != nil {
+			return "", err
+		}
+	}
+	return t.Format(toGoLayout(layout)), nil
+}
+
+// TimeStampToStrIn 与 TimeStampToStr 相同，但可以指定时区和格式（为空时分别为本地时区和 "2006-01-02 15:04:05"）。
+func TimeStampToStrIn(ts int64, isMilli bool, layout, zone string) (string, error) {
+	if ts <= 0 {
+		return "", nil
+	}
+
+	var t time.Time
+	if isMilli {
+		t = time.UnixMilli(ts)
+	} else {
+		t = time.Unix(ts, 0)
+	}
+	if layout == "" {
+		layout = "2006-01-02 15:04:05"
+	}
+	return FormatTimeIn(t, layout, zone)
+}
+
+// javaLayoutTokens 是 "yyyy-MM-dd" 风格的占位符，按长度从长到短匹配。
+var javaLayoutTokens = []struct{ token, layout string }{
+	{"yyyy", "2006"}, {"yy", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dd", "02"}, {"d", "2"},
+	{"EEEE", "Monday"}, {"EEE", "Mon"},
+	{"HH", "15"}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"m", "4"},
+	{"ss", "05"}, {"s", "5"},
+	{"SSSSSSSSS", "000000000"}, {"SSSSSS", "000000"}, {"SSS", "000"},
+	{"a", "PM"},
+	{"XXX", "Z07:00"}, {"Z", "-0700"}, {"z", "MST"},
+}
+
+// goLayoutMarkers 是 Go 格式的参考值，出现任意一个即视为 Go 格式。
+var goLayoutMarkers = []string{"06", "01", "02", "15", "03", "04", "05", "Jan", "Mon", "MST"}
+
+// toGoLayout 把 "yyyy-MM-dd HH:mm:ss" 风格的格式转为 Go 格式。
+// 含有 Go 参考值（"2006"、"01"、"15"、"04" 等）的格式原样视为 Go 格式，其余按占位符转换，
+// 因此 "HH:mm" 这类不含年份的格式同样可用；单引号内的文本原样输出（"yyyy-MM-dd'T'HH:mm"）。
+func toGoLayout(layout string) string {
+	for _, m := range goLayoutMarkers {
+		if strings.Contains(layout, m) {
+			return layout
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(layout); {
+		if layout[i] == '\'' {
+			end := strings.IndexByte(layout[i+1:], '\'')
+			if end < 0 {
+				b.WriteString(layout[i+1:])
+				break
+			}
+			b.WriteString(layout[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		matched := false
+		for _, tk := range javaLayoutTokens {
+			if strings.HasPrefix(layout[i:], tk.token) {
+				b.WriteString(tk.layout)
+				i += len(tk.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(layout[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// =============================================================================
+// 日、周、月边界
+// =============================================================================
+
+// zoneOf 返回 loc，为 nil 时使用 t 自身的时区。
+func zoneOf(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// StartOfDay 返回 t 在 loc 时区（nil 表示 t 自身的时区）所在日的 00:00:00。
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = zoneOf(t, loc)
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay 返回 t 所在日的最后一纳秒（即次日 00:00:00 减 1ns）。
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	start := StartOfDay(t, loc)
+	y, m, d := start.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, start.Location()).Add(-time.Nanosecond)
+}
+
+// StartOfWeek 返回 t 所在周第一天的 00:00:00，weekStart 为一周的第一天（中国习惯为 time.Monday）。
+func StartOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	start := StartOfDay(t, loc)
+	offset := (int(start.Weekday()) - int(weekStart) + 7) % 7
+	y, m, d := start.Date()
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, start.Location())
+}
+
+// EndOfWeek 返回 t 所在周的最后一纳秒。
+func EndOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	start := StartOfWeek(t, loc, weekStart)
+	y, m, d := start.Date()
+	return time.Date(y, m, d+7, 0, 0, 0, 0, start.Location()).Add(-time.Nanosecond)
+}
+
+// StartOfMonth 返回 t 所在月 1 日的 00:00:00。
+func StartOfMonth(t time.Time, loc *time.Location) time.Time {
+	t = zoneOf(t, loc)
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfMonth 返回 t 所在月的最后一纳秒。
+func EndOfMonth(t time.Time, loc *time.Location) time.Time {
+	start := StartOfMonth(t, loc)
+	y, m, _ := start.Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, start.Location()).Add(-time.Nanosecond)
+}
+
+// =============================================================================
+// 时长
+// =============================================================================
+
+// durationUnits 是 ParseHumanDuration 支持的单位（不区分大小写）。月、年长度不固定，不支持。
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "纳秒": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "μs": time.Microsecond, "微秒": time.Microsecond,
+	"ms": time.Millisecond, "毫秒": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second, "秒": time.Second, "秒钟": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute, "分": time.Minute, "分钟": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour, "时": time.Hour, "小时": time.Hour, "钟头": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour, "天": 24 * time.Hour, "日": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour, "周": 7 * 24 * time.Hour, "星期": 7 * 24 * time.Hour, "礼拜": 7 * 24 * time.Hour,
+}
+
+// ParseHumanDuration 解析人类可读的时长，数字与单位可以重复出现并累加：
+// "3d4h"、"1.5h"、"2周"、"三天"、"1小时30分钟"、"1 hour 30 minutes"、"-2d"。
+// 数字支持中文数字（"两"、"十五"、"一点五"）；"个"（"3个小时"）和 "and"、"," 会被忽略。
+// "半" 表示 0.5：单位前为再加半个该单位（"半小时"、"两个半小时"），单位后为上一个单位的一半（"一天半"）。
+// 不带单位的纯数字按 time.ParseDuration 规则视为错误，"0" 除外。
+//
+// 使用示例：
+//
+//	d, err := ParseHumanDuration("3d4h")  // 76h0m0s
+//	d, err := ParseHumanDuration("两周")   // 336h0m0s
+//	d, err := ParseHumanDuration("两个半小时") // 2h30m0s
+func ParseHumanDuration(s string) (time.Duration, error) {
+	raw := s
+	s = strings.TrimSpace(toHalfWidth(s))
+	if s == "0" {
+		return 0, nil
+	}
+	neg := false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		neg, s = true, rest
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+
+	var total float64
+	var lastUnit time.Duration
+	runes := []rune(s)
+	parts := 0
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) || r == ',' || r == '个' {
+			i++
+			continue
+		}
+		if (r == 'a' || r == 'A') && i+3 <= len(runes) && strings.EqualFold(string(runes[i:i+3]), "and") {
+			i += 3
+			continue
+		}
+
+		// 数字部分：阿拉伯数字、小数点、中文数字（"点" 为小数点）；单独的 "半" 视为 0.5
+		j := i
+		for j < len(runes) && isDurationNumberRune(runes[j]) {
+			j++
+		}
+		var v float64
+		switch {
+		case j > i:
+			n, err := parseNumberString(string(runes[i:j]), ConvertOptions{DecimalSep: '.', ChineseNumerals: true})
+			if err != nil {
+				return 0, fmt.Errorf("%w: %q", ErrDurationSyntax, raw)
+			}
+			v = numberToFloat(n)
+		case r == '半':
+			v, j = 0.5, i+1
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrDurationSyntax, raw)
+		}
+
+		// 单位部分：跳过空白与 "个"，取连续的字母或非数字汉字。
+		// 数字后紧跟的 "半" 表示再加 0.5 个单位（"两个半小时"）
+		k := j
+		for k < len(runes) && (unicode.IsSpace(runes[k]) || runes[k] == '个') {
+			k++
+		}
+		if j > i && k < len(runes) && runes[k] == '半' {
+			v += 0.5
+			for k++; k < len(runes) && unicode.IsSpace(runes[k]); k++ {
+			}
+		}
+		u := k
+		for u < len(runes) && !unicode.IsSpace(runes[u]) && !unicode.IsDigit(runes[u]) &&
+			!isDurationNumberRune(runes[u]) && runes[u] != ',' && runes[u] != '半' {
+			u++
+		}
+		unit, ok := durationUnits[strings.ToLower(string(runes[k:u]))]
+		if !ok && u == k && r == '半' && lastUnit > 0 {
+			// 单位后的 "半" 表示该单位的一半（"一天半"、"1小时半"）
+			unit, ok = lastUnit, true
+		}
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown unit %q in %q", ErrDurationSyntax, string(runes[k:u]), raw)
+		}
+		total += v * float64(unit)
+		lastUnit = unit
+		parts++
+		i = u
+	}
+	if parts == 0 {
+		return 0, fmt.Errorf("%w: %q", ErrDurationSyntax, raw)
+	}
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %q", ErrConvertOverflow, raw)
+	}
+	if neg {
+		total = -total
+	}
+	return time.Duration(math.Round(total)), nil
+}
+
+// numberToFloat 把 parseNumberString 返回的 int64、uint64 或 float64 转为 float64。
+func numberToFloat(n any) float64 {
+	switch v := n.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return v.(float64)
+	}
+}
+
+// isDurationNumberRune 判断 r 是否属于时长中的数字部分。"十" "百" 等单位和小数点 "点" 也算数字，"时" "分" 属于时长单位。
+func isDurationNumberRune(r rune) bool {
+	if r >= '0' && r <= '9' || r == '.' || r == '点' || r == '點' {
+		return true
+	}
+	if _, ok := chineseDigits[r]; ok {
+		return true
+	}
+	if _, ok := chineseSmallUnits[r]; ok {
+		return true
+	}
+	_, ok := chineseBigUnits[r]
+	return ok
+}
+
+// =============================================================================
+// 相对时间
+// =============================================================================
+
+// TimeLang 是相对时间的输出语言。
+type TimeLang int
+
+const (
+	TimeLangZH TimeLang = iota // "5 分钟前"、"3 天后"
+	TimeLangEN                 // "5 minutes ago"、"in 3 days"
+)
+
+// relativeUnits 按从大到小排列，月、年按 30 天、365 天近似。
+var relativeUnits = []struct {
+	d      time.Duration
+	zh, en string
+}{
+	{365 * 24 * time.Hour, "年", "year"},
+	{30 * 24 * time.Hour, "个月", "month"},
+	{7 * 24 * time.Hour, "周", "week"},
+	{24 * time.Hour, "天", "day"},
+	{time.Hour, "小时", "hour"},
+	{time.Minute, "分钟", "minute"},
+	{time.Second, "秒", "second"},
+}
+
+// RelativeTime 以 now 为基准描述 t：早于 now 为 "x 前"/"x ago"，晚于 now 为 "x 后"/"in x"，
+// 相差不足 10 秒为 "刚刚"/"just now"。只取最大的一个单位并向下取整。
+//
+// 使用示例：
+//
+//	RelativeTime(now.Add(-5*time.Minute), now, TimeLangZH) // "5 分钟前"
+//	RelativeTime(now.Add(49*time.Hour), now, TimeLangEN)   // "in 2 days"
+func RelativeTime(t, now time.Time, lang TimeLang) string {
+	d := now.Sub(t)
+	past := d >= 0
+	if !past {
+		d = -d
+	}
+	if d < 10*time.Second {
+		if lang == TimeLangEN {
+			return "just now"
+		}
+		return "刚刚"
+	}
+
+	for _, u := range relativeUnits {
+		if d < u.d {
+			continue
+		}
+		n := int64(d / u.d)
+		if lang == TimeLangEN {
+			unit := u.en
+			if n != 1 {
+				unit += "s"
+			}
+			if past {
+				return fmt.Sprintf("%d %s ago", n, unit)
+			}
+			return fmt.Sprintf("in %d %s", n, unit)
+		}
+		if past {
+			return fmt.Sprintf("%d %s前", n, u.zh)
+		}
+		return fmt.Sprintf("%d %s后", n, u.zh)
+	}
+	return "" // 不可达：d >= 10s 时至少匹配秒
+}
+
+// TimeAgo 以当前时间为基准描述 t，见 RelativeTime。
+func TimeAgo(t time.Time, lang TimeLang) string {
+	return RelativeTime(t, time.Now(), lang)
+}
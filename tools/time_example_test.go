@@ -0,0 +1,241 @@
+package tools
+
+// 时间解析示例，覆盖以下场景：
+//   - 示例1：ParseTime 识别的各类格式（RFC3339、横线/斜线/点分隔、中文、紧凑格式、Unix 时间戳、RFC1123）
+//   - 示例2：ParseTimeIn 指定时区与自定义格式，TimeToTimeStamp 与运行环境的本地时区无关
+//   - 示例3：时区转换与 "yyyy-MM-dd" 风格格式化
+//   - 示例4：日、周、月边界（含夏令时切换日）
+//   - 示例5：ParseHumanDuration 与中英文相对时间
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// 示例 1：ParseTime 识别的各类格式
+// =============================================================================
+
+func Example_parseTimeLayouts() {
+	for _, s := range []string{
+		"2024-01-02T15:04:05+08:00",      // RFC3339
+		"2024-01-02T15:04:05.123Z",       // RFC3339Nano
+		"2024-01-02 15:04:05",            // 横线分隔
+		"2024/1/2 15:04",                 // 斜线分隔，一位月日
+		"2024.01.02 15:04:05.5",          // 点分隔，带小数秒
+		"2024年1月2日 15时04分05秒",            // 中文
+		"２０２４年０１月０２日",                    // 全角数字
+		"2024",                           // 年
+		"202403",                         // 年月
+		"20240102",                       // 紧凑日期
+		"20240102150405",                 // 紧凑日期时间
+		"1704207845",                     // Unix 秒
+		"1704207845000",                  // Unix 毫秒
+		"Tue, 02 Jan 2024 15:04:05 GMT",  // RFC1123
+		"2024-01-02 15:04 Asia/Shanghai", // 末尾 IANA 时区名
+	} {
+		// 不含时区信息的输入按 ParseTimeIn 的 loc 解析，这里用 UTC 保证输出稳定
+		t, err := ParseTimeIn(s, time.UTC)
+		fmt.Println(t.Format(time.RFC3339Nano), err)
+	}
+
+	// 不合法的紧凑日期和过短的数字返回错误，不会被当作 Unix 时间戳
+	for _, s := range []string{"20241301", "20240230", "12345", "明天下午"} {
+		_, err := ParseTime(s)
+		fmt.Println(s, errors.Is(err, ErrTimeFormat))
+	}
+
+	// Output:
+	// 2024-01-02T15:04:05+08:00 <nil>
+	// 2024-01-02T15:04:05.123Z <nil>
+	// 2024-01-02T15:04:05Z <nil>
+	// 2024-01-02T15:04:00Z <nil>
+	// 2024-01-02T15:04:05.5Z <nil>
+	// 2024-01-02T15:04:05Z <nil>
+	// 2024-01-02T00:00:00Z <nil>
+	// 2024-01-01T00:00:00Z <nil>
+	// 2024-03-01T00:00:00Z <nil>
+	// 2024-01-02T00:00:00Z <nil>
+	// 2024-01-02T15:04:05Z <nil>
+	// 2024-01-02T15:04:05Z <nil>
+	// 2024-01-02T15:04:05Z <nil>
+	// 2024-01-02T15:04:05Z <nil>
+	// 2024-01-02T15:04:00+08:00 <nil>
+	// 20241301 true
+	// 20240230 true
+	// 12345 true
+	// 明天下午 true
+}
+
+// =============================================================================
+// 示例 2：指定时区、自定义格式与时间戳
+// =============================================================================
+
+func Example_parseTimeZone() {
+	sh, _ := time.LoadLocation("Asia/Shanghai")
+
+	// 不含时区信息时按 loc 解析；字符串自带偏移时以字符串为准
+	t1, _ := ParseTimeIn("2024-01-02 00:00:00", sh)
+	t2, _ := ParseTimeIn("2024-01-02T00:00:00Z", sh)
+	fmt.Println(t1.Format(time.RFC3339), t2.Format(time.RFC3339))
+
+	// 额外的格式优先于内置格式尝试
+	t3, _ := ParseTimeIn("02|01|2024", time.UTC, "02|01|2006")
+	fmt.Println(t3.Format(time.DateOnly))
+
+	// TimeToTimeStamp 把不含时区信息的字符串按 UTC 解析；TimeToTimeStampIn 可指定时区
+	ts, _ := TimeToTimeStampErr("2024-01-02 00:00:00", false)
+	tsSh, _ := TimeToTimeStampIn("2024-01-02 00:00:00", false, sh)
+	fmt.Println(ts, tsSh, TimeToTimeStamp("2024-01-02T00:00:00+08:00", true))
+
+	// 无法识别时返回错误，不会用当前时间代替
+	_, err := TimeToTimeStampErr("not a time", false)
+	fmt.Println(TimeToTimeStamp("not a time", false), errors.Is(err, ErrTimeFormat))
+
+	// Output:
+	// 2024-01-02T00:00:00+08:00 2024-01-02T00:00:00Z
+	// 2024-01-02
+	// 1704153600 1704124800 1704124800000
+	// 0 true
+}
+
+// =============================================================================
+// 示例 3：时区转换与格式化
+// =============================================================================
+
+func Example_timeZone() {
+	t := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	// IANA 名称与固定偏移
+	for _, zone := range []string{"Asia/Shanghai", "America/New_York", "+05:30", "UTC-3"} {
+		z, err := InZone(t, zone)
+		fmt.Println(zone, z.Format(time.RFC3339), err)
+	}
+
+	// Go 格式与 "yyyy-MM-dd" 风格均可；单引号内原样输出
+	s1, _ := FormatTimeIn(t, "2006-01-02 15:04", "Asia/Tokyo")
+	s2, _ := FormatTimeIn(t, "yyyy年MM月dd日 HH:mm:ss", "Asia/Shanghai")
+	s3, _ := FormatTimeIn(t, "yyyy-MM-dd'T'HH:mm:ss.SSSXXX", "+08:00")
+	s4, _ := FormatTimeIn(t, "EEE, d MMM yyyy hh:mm a", "")
+	s5, _ := FormatTimeIn(t, "HH:mm", "Asia/Tokyo") // 不含年份的格式同样转换
+	fmt.Println(s1)
+	fmt.Println(s2)
+	fmt.Println(s3)
+	fmt.Println(s4)
+	fmt.Println(s5)
+
+	s6, _ := TimeStampToStrIn(1704207845000, true, "yyyy/MM/dd HH:mm", "Asia/Shanghai")
+	fmt.Println(s6)
+
+	_, err := LoadZone("Mars/Olympus")
+	fmt.Println(err != nil)
+
+	// Output:
+	// Asia/Shanghai 2024-01-02T23:04:05+08:00 <nil>
+	// America/New_York 2024-01-02T10:04:05-05:00 <nil>
+	// +05:30 2024-01-02T20:34:05+05:30 <nil>
+	// UTC-3 2024-01-02T12:04:05-03:00 <nil>
+	// 2024-01-03 00:04
+	// 2024年01月02日 23:04:05
+	// 2024-01-02T23:04:05.000+08:00
+	// Tue, 2 Jan 2024 03:04 PM
+	// 00:04
+	// 2024/01/02 23:04
+	// true
+}
+
+// =============================================================================
+// 示例 4：日、周、月边界
+// =============================================================================
+
+func Example_timeBounds() {
+	sh, _ := LoadZone("Asia/Shanghai")
+	// UTC 的 1 月 31 日 20:00 在上海已是 2 月 1 日（周四）
+	t := time.Date(2024, 1, 31, 20, 0, 0, 0, time.UTC)
+
+	const layout = "2006-01-02 15:04:05.999999999 MST"
+	fmt.Println(StartOfDay(t, sh).Format(layout))
+	fmt.Println(EndOfDay(t, sh).Format(layout))
+	fmt.Println(StartOfWeek(t, sh, time.Monday).Format(layout))
+	fmt.Println(StartOfWeek(t, sh, time.Sunday).Format(layout))
+	fmt.Println(EndOfMonth(t, sh).Format(layout))
+	fmt.Println(StartOfMonth(t, nil).Format(layout)) // nil 使用 t 自身的时区
+
+	// 夏令时切换日只有 23 小时，边界仍然正确
+	ny, _ := LoadZone("America/New_York")
+	dst := time.Date(2024, 3, 10, 12, 0, 0, 0, ny)
+	fmt.Println(EndOfDay(dst, nil).Sub(StartOfDay(dst, nil)).Round(time.Second))
+
+	// Output:
+	// 2024-02-01 00:00:00 CST
+	// 2024-02-01 23:59:59.999999999 CST
+	// 2024-01-29 00:00:00 CST
+	// 2024-01-28 00:00:00 CST
+	// 2024-02-29 23:59:59.999999999 CST
+	// 2024-01-01 00:00:00 UTC
+	// 23h0m0s
+}
+
+// =============================================================================
+// 示例 5：人类可读的时长与相对时间
+// =============================================================================
+
+func Example_humanDuration() {
+	for _, s := range []string{
+		"3d4h", "1.5h", "1 hour 30 minutes", "90s",
+		"两周", "三天", "1小时30分钟", "十五分钟",
+		"两个半小时", "一点五小时", "半小时", "一天半",
+	} {
+		d, err := ParseHumanDuration(s)
+		fmt.Println(s, d, err)
+	}
+
+	// 月、年长度不固定，不支持；纯数字（"0" 除外）缺少单位
+	_, err1 := ParseHumanDuration("3个月")
+	_, err2 := ParseHumanDuration("15")
+	fmt.Println(errors.Is(err1, ErrDurationSyntax), errors.Is(err2, ErrDurationSyntax))
+
+	// 相对时间只取最大的一个单位并向下取整
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	for _, d := range []time.Duration{-5 * time.Second, -5 * time.Minute, -time.Hour, 49 * time.Hour, 40 * 24 * time.Hour} {
+		fmt.Printf("%q %q\n", RelativeTime(now.Add(d), now, TimeLangZH), RelativeTime(now.Add(d), now, TimeLangEN))
+	}
+
+	// Output:
+	// 3d4h 76h0m0s <nil>
+	// 1.5h 1h30m0s <nil>
+	// 1 hour 30 minutes 1h30m0s <nil>
+	// 90s 1m30s <nil>
+	// 两周 336h0m0s <nil>
+	// 三天 72h0m0s <nil>
+	// 1小时30分钟 1h30m0s <nil>
+	// 十五分钟 15m0s <nil>
+	// 两个半小时 2h30m0s <nil>
+	// 一点五小时 1h30m0s <nil>
+	// 半小时 30m0s <nil>
+	// 一天半 36h0m0s <nil>
+	// true true
+	// "刚刚" "just now"
+	// "5 分钟前" "5 minutes ago"
+	// "1 小时前" "1 hour ago"
+	// "2 天后" "in 2 days"
+	// "1 个月后" "in 1 month"
+}
+
+// TestTimeToTimeStampIgnoresLocal 确认 TimeToTimeStamp 的结果不随 time.Local 变化。
+func TestTimeToTimeStampIgnoresLocal(t *testing.T) {
+	saved := time.Local
+	defer func() { time.Local = saved }()
+
+	for _, zone := range []*time.Location{time.UTC, time.FixedZone("UTC+8", 8*3600), time.FixedZone("UTC-5", -5*3600)} {
+		time.Local = zone
+		if got := TimeToTimeStamp("2024-01-02 00:00:00", false); got != 1704153600 {
+			t.Errorf("time.Local=%s: TimeToTimeStamp = %d, want 1704153600", zone, got)
+		}
+		if got := TimeToTimeStamp("2024年1月2日", true); got != 1704153600000 {
+			t.Errorf("time.Local=%s: TimeToTimeStamp(milli) = %d, want 1704153600000", zone, got)
+		}
+	}
+}
@@ -29,7 +29,6 @@ import (
 	"io/fs"
 	"io/ioutil"
 	"log"
-	"math"
 	"math/big"
 	"net"
 	"net/url"
@@ -294,6 +293,13 @@ func ToStr(v interface{}) string {
 			return val.Format(time.RFC3339Nano)
 		}
 
+	// =========================
+	// big number（*big.Float、*big.Rat 的 String() 会丢失精度或输出分数）
+	// =========================
+	case Decimal, *big.Int, *big.Rat, *big.Float:
+		s, _ := Convert[string](val)
+		return s
+
 	// =========================
 	// error
 	// =========================
@@ -339,146 +345,10 @@ func ToStr(v interface{}) string {
 //	s, err := ToStrErr(123)       // "123", nil
 //	s, err := ToStrErr(nil)       // "", error("值为 nil")
 //	s, err := ToStrErr(true)      // "true", nil
-//	s, err := ToStrErr(3.14)      // "3.140000", nil
+//	s, err := ToStrErr(3.14)      // "3.14", nil
 //	s, err := ToStrErr(json.Number("99")) // "99", nil
 func ToStrErr(v interface{}) (string, error) {
-	if v == nil {
-		return "", fmt.Errorf("nil value")
-	}
-
-	switch val := v.(type) {
-
-	case string:
-		return val, nil
-	case *string:
-		if val != nil {
-			return *val, nil
-		}
-		return "", fmt.Errorf("nil *string")
-
-	case []byte:
-		return string(val), nil
-	case *[]byte:
-		if val != nil {
-			return string(*val), nil
-		}
-		return "", fmt.Errorf("nil *[]byte")
-
-	case json.RawMessage:
-		return string(val), nil
-	case *json.RawMessage:
-		if val != nil {
-			return string(*val), nil
-		}
-		return "", fmt.Errorf("nil *json.RawMessage")
-
-	case []rune:
-		return string(val), nil
-
-	case int:
-		return strconv.Itoa(val), nil
-	case *int:
-		if val != nil {
-			return strconv.Itoa(*val), nil
-		}
-		return "", fmt.Errorf("nil *int")
-
-	case int64:
-		return strconv.FormatInt(val, 10), nil
-	case *int64:
-		if val != nil {
-			return strconv.FormatInt(*val, 10), nil
-		}
-		return "", fmt.Errorf("nil *int64")
-
-	case int32:
-		return strconv.Itoa(int(val)), nil
-	case *int32:
-		if val != nil {
-			return strconv.Itoa(int(*val)), nil
-		}
-		return "", fmt.Errorf("nil *int32")
-
-	case float64:
-		return strconv.FormatFloat(val, 'f', -1, 64), nil
-	case *float64:
-		if val != nil {
-			return strconv.FormatFloat(*val, 'f', -1, 64), nil
-		}
-		return "", fmt.Errorf("nil *float64")
-
-	case float32:
-		return strconv.FormatFloat(float64(val), 'f', -1, 32), nil
-	case *float32:
-		if val != nil {
-			return strconv.FormatFloat(float64(*val), 'f', -1, 32), nil
-		}
-		return "", fmt.Errorf("nil *float32")
-
-	case bool:
-		return strconv.FormatBool(val), nil
-	case *bool:
-		if val != nil {
-			return strconv.FormatBool(*val), nil
-		}
-		return "", fmt.Errorf("nil *bool")
-
-	case json.Number:
-		return val.String(), nil
-	case *json.Number:
-		if val != nil {
-			return val.String(), nil
-		}
-		return "", fmt.Errorf("nil *json.Number")
-
-	case time.Time:
-		return val.Format(time.RFC3339Nano), nil
-	case *time.Time:
-		if val != nil {
-			return val.Format(time.RFC3339Nano), nil
-		}
-		return "", fmt.Errorf("nil *time.Time")
-
-	case error:
-		return val.Error(), nil
-	case *error:
-		if val != nil && *val != nil {
-			return (*val).Error(), nil
-		}
-		return "", fmt.Errorf("nil *error")
-
-	case fmt.Stringer:
-		return val.String(), nil
-	case *fmt.Stringer:
-		if val != nil {
-			return (*val).String(), nil
-		}
-		return "", fmt.Errorf("nil *fmt.Stringer")
-
-	case []string:
-		return strings.Join(val, ","), nil
-	case *[]string:
-		if val != nil {
-			return strings.Join(*val, ","), nil
-		}
-		return "", fmt.Errorf("nil *[]string")
-
-	case []any:
-		b, err := json.Marshal(val)
-		if err != nil {
-			return "", err
-		}
-		return string(b), nil
-		
-	case map[string]interface{}:
-		b, err := json.Marshal(val)
-		if err != nil {
-			return "", err
-		}
-		return string(b), nil
-	}
-
-	return "", fmt.Errorf("unsupported type: %T", v)
+	return Convert[string](v)
 }
 
 // ToBytes 将任意类型转换为 []byte，无法转换时返回空切片
@@ -698,1350 +568,79 @@ func ToBytesErr(v interface{}) ([]byte, error) {
 
 	switch rv.Kind() {
 
-	case reflect.Map, reflect.Struct:
-		return json.Marshal(v)
-
-	case reflect.Slice, reflect.Array:
-		// 排除 []byte
-		if rv.Type().Elem().Kind() == reflect.Uint8 {
-			return v.([]byte), nil
-		}
-		return json.Marshal(v)
-	default:
-		return nil, fmt.Errorf("unsupported type: %T", v)
-	}
-
-}
-
-// ToInt64 将任意类型转换为 int64，无法转换时或超出范围时返回 0
-func ToInt64(v interface{}) int64 {
-	if v == nil {
-		return 0
-	}
-
-	switch val := v.(type) {
-
-	// ===== 整数 =====
-	case int:
-		return int64(val)
-	case int8:
-		return int64(val)
-	case int16:
-		return int64(val)
-	case int32:
-		return int64(val)
-	case int64:
-		return val
-
-	// ===== 无符号 =====
-	case uint:
-		return int64(val)
-	case uint8:
-		return int64(val)
-	case uint16:
-		return int64(val)
-	case uint32:
-		return int64(val)
-	case uint64:
-		if val <= math.MaxInt64 {
-			return int64(val)
-		}
-		return 0
-
-	// ===== 浮点 =====
-	case float32:
-		return int64(val)
-	case float64:
-		return int64(val)
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1
-		}
-		return 0
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-
-		// 优先 int
-		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
-			return i
-		}
-
-		// 再 float
-		if f, err := strconv.ParseFloat(s, 64); err == nil {
-			return int64(f)
-		}
-
-		return 0
-
-	// ===== []byte =====
-	case []byte:
-		return ToInt64(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		if i, err := val.Int64(); err == nil {
-			return i
-		}
-		if f, err := val.Float64(); err == nil {
-			return int64(f)
-		}
-		return 0
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToInt64(val.String())
-
-	case error:
-		return 0
-
-	case time.Time:
-		return val.Unix()
-	}
-
-	// ===== 反射兜底 =====
-	rv := reflect.ValueOf(v)
-
-	switch rv.Kind() {
-	case reflect.Ptr:
-		if rv.IsNil() {
-			return 0
-		}
-		return ToInt64(rv.Elem().Interface())
-	}
-
-	return 0
-}
-
-// ToInt64WithErr 将任意类型转换为 int64，无法转换或超出范围时返回错误
-func ToInt64Err(v interface{}) (int64, error) {
-	if v == nil {
-		return 0, fmt.Errorf("val is nil")
-	}
-
-	switch val := v.(type) {
-
-	// ===== 整数 =====
-	case int:
-		return int64(val), nil
-	case int8:
-		return int64(val), nil
-	case int16:
-		return int64(val), nil
-	case int32:
-		return int64(val), nil
-	case int64:
-		return val, nil
-
-	// ===== 无符号 =====
-	case uint:
-		if uint64(val) > math.MaxInt64 {
-			return 0, fmt.Errorf("uint overflow")
-		}
-		return int64(val), nil
-
-	case uint64:
-		if val > math.MaxInt64 {
-			return 0, fmt.Errorf("uint64 overflow")
-		}
-		return int64(val), nil
-
-	case uint8, uint16, uint32:
-		return int64(reflect.ValueOf(val).Uint()), nil
-
-	// ===== 浮点 =====
-	case float32:
-		f := float64(val)
-		if f > math.MaxInt64 || f < math.MinInt64 {
-			return 0, fmt.Errorf("float32 overflow")
-		}
-		return int64(f), nil
-
-	case float64:
-		if val > math.MaxInt64 || val < math.MinInt64 {
-			return 0, fmt.Errorf("float64 overflow")
-		}
-		return int64(val), nil
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1, nil
-		}
-		return 0, nil
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-
-		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
-			return i, nil
-		}
-
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return 0, fmt.Errorf("string parse error: %v", err)
-		}
-
-		if f > math.MaxInt64 || f < math.MinInt64 {
-			return 0, fmt.Errorf("float overflow")
-		}
-
-		return int64(f), nil
-
-	// ===== []byte =====
-	case []byte:
-		return ToInt64Err(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		if i, err := val.Int64(); err == nil {
-			return i, nil
-		}
-		if f, err := val.Float64(); err == nil {
-			return int64(f), nil
-		}
-		return 0, fmt.Errorf("json.Number parse error")
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToInt64Err(val.String())
-
-	case time.Time:
-		return val.Unix(), nil
-	}
-
-	// ===== 指针处理 =====
-	rv := reflect.ValueOf(v)
-
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0, fmt.Errorf("nil pointer")
-		}
-		return ToInt64Err(rv.Elem().Interface())
-	}
-
-	return 0, fmt.Errorf("unsupported type: %T", v)
-}
-
-// ToInt 将任意类型转换为 int，无法转换时返回 0
-func ToInt(v interface{}) int {
-	if v == nil {
-		return 0
-	}
-
-	maxInt := int(^uint(0) >> 1)
-	minInt := -maxInt - 1
-
-	switch val := v.(type) {
-
-	// ===== int 系列 =====
-	case int:
-		return val
-	case int8:
-		return int(val)
-	case int16:
-		return int(val)
-	case int32:
-		return int(val)
-	case int64:
-		if val > int64(maxInt) || val < int64(minInt) {
-			return 0
-		}
-		return int(val)
-
-	// ===== uint =====
-	case uint:
-		if val > uint(maxInt) {
-			return 0
-		}
-		return int(val)
-
-	case uint8, uint16, uint32:
-		return int(reflect.ValueOf(val).Uint())
-
-	case uint64:
-		if val > uint64(maxInt) {
-			return 0
-		}
-		return int(val)
-
-	// ===== float =====
-	case float32:
-		f := float64(val)
-		if f > float64(maxInt) || f < float64(minInt) {
-			return 0
-		}
-		return int(f)
-
-	case float64:
-		if val > float64(maxInt) || val < float64(minInt) {
-			return 0
-		}
-		return int(val)
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1
-		}
-		return 0
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-
-		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
-			return ToInt(i)
-		}
-
-		if f, err := strconv.ParseFloat(s, 64); err == nil {
-			return ToInt(f)
-		}
-
-		return 0
-
-	// ===== []byte =====
-	case []byte:
-		return ToInt(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		if i, err := val.Int64(); err == nil {
-			return ToInt(i)
-		}
-		if f, err := val.Float64(); err == nil {
-			return ToInt(f)
-		}
-		return 0
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToInt(val.String())
-
-	case error:
-		return 0
-
-	case time.Time:
-		return ToInt(val.Unix())
-	}
-
-	// ===== 指针处理 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0
-		}
-		return ToInt(rv.Elem().Interface())
-	}
-
-	return 0
-}
-
-// ToIntErr 将任意类型转换为 int，无法转换或溢出时返回错误
-func ToIntErr(v interface{}) (int, error) {
-	if v == nil {
-		return 0, fmt.Errorf("val is nil")
-	}
-
-	maxInt := int(^uint(0) >> 1)
-	minInt := -maxInt - 1
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		return val, nil
-
-	case int8:
-		return int(val), nil
-	case int16:
-		return int(val), nil
-	case int32:
-		return int(val), nil
-
-	case int64:
-		if val > int64(maxInt) || val < int64(minInt) {
-			return 0, fmt.Errorf("int64 overflow")
-		}
-		return int(val), nil
-
-	// ===== uint =====
-	case uint:
-		if val > uint(maxInt) {
-			return 0, fmt.Errorf("uint overflow")
-		}
-		return int(val), nil
-
-	case uint8, uint16, uint32:
-		return int(reflect.ValueOf(val).Uint()), nil
-
-	case uint64:
-		if val > uint64(maxInt) {
-			return 0, fmt.Errorf("uint64 overflow")
-		}
-		return int(val), nil
-
-	// ===== float =====
-	case float32:
-		f := float64(val)
-		if f > float64(maxInt) || f < float64(minInt) {
-			return 0, fmt.Errorf("float32 overflow")
-		}
-		return int(f), nil
-
-	case float64:
-		if val > float64(maxInt) || val < float64(minInt) {
-			return 0, fmt.Errorf("float64 overflow")
-		}
-		return int(val), nil
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1, nil
-		}
-		return 0, nil
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-
-		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
-			return ToIntErr(i)
-		}
-
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return 0, fmt.Errorf("string parse error: %v", err)
-		}
-
-		if f > float64(maxInt) || f < float64(minInt) {
-			return 0, fmt.Errorf("float overflow")
-		}
-
-		return int(f), nil
-
-	// ===== []byte =====
-	case []byte:
-		return ToIntErr(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		if i, err := val.Int64(); err == nil {
-			return ToIntErr(i)
-		}
-		if f, err := val.Float64(); err == nil {
-			return ToIntErr(f)
-		}
-		return 0, fmt.Errorf("json.Number parse error")
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToIntErr(val.String())
-
-	case time.Time:
-		return ToIntErr(val.Unix())
-	}
-
-	// ===== 指针处理 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0, fmt.Errorf("nil pointer")
-		}
-		return ToIntErr(rv.Elem().Interface())
-	}
-
-	return 0, fmt.Errorf("unsupported type: %T", v)
-}
-
-// ToUint32 将任意类型转换为 uint32，无法转换或溢出时返回 0
-func ToUint32(v interface{}) uint32 {
-	if v == nil {
-		return 0
-	}
-
-	max := uint64(^uint32(0))
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		if val < 0 {
-			return 0
-		}
-		return ToUint32(int64(val))
-
-	case int8:
-		if val < 0 {
-			return 0
-		}
-		return uint32(val)
-
-	case int16:
-		if val < 0 {
-			return 0
-		}
-		return uint32(val)
-
-	case int32:
-		if val < 0 {
-			return 0
-		}
-		return uint32(val)
-
-	case int64:
-		if val < 0 || uint64(val) > max {
-			return 0
-		}
-		return uint32(val)
-
-	// ===== uint =====
-	case uint:
-		if uint64(val) > max {
-			return 0
-		}
-		return uint32(val)
-
-	case uint8:
-		return uint32(val)
-	case uint16:
-		return uint32(val)
-	case uint32:
-		return val
-
-	case uint64:
-		if val > max {
-			return 0
-		}
-		return uint32(val)
-
-	// ===== float =====
-	case float32:
-		f := float64(val)
-		if f < 0 || f > float64(max) {
-			return 0
-		}
-		return uint32(f)
-
-	case float64:
-		if val < 0 || val > float64(max) {
-			return 0
-		}
-		return uint32(val)
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1
-		}
-		return 0
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-
-		if u, err := strconv.ParseUint(s, 10, 32); err == nil {
-			return uint32(u)
-		}
-
-		if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 && f <= float64(max) {
-			return uint32(f)
-		}
-
-		return 0
-
-	// ===== []byte =====
-	case []byte:
-		return ToUint32(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		if i, err := val.Int64(); err == nil && i >= 0 {
-			return ToUint32(i)
-		}
-		if f, err := val.Float64(); err == nil {
-			return ToUint32(f)
-		}
-		return 0
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToUint32(val.String())
-
-	case error:
-		return 0
-
-	case time.Time:
-		return ToUint32(val.Unix())
-	}
-
-	// ===== 指针 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0
-		}
-		return ToUint32(rv.Elem().Interface())
-	}
-
-	return 0
-}
-
-// ToUint32Err 将任意类型转换为 uint32，无法转换或溢出时返回错误
-func ToUint32Err(v interface{}) (uint32, error) {
-	if v == nil {
-		return 0, fmt.Errorf("val is nil")
-	}
-
-	max := uint64(^uint32(0))
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		if val < 0 || uint64(val) > max {
-			return 0, fmt.Errorf("int overflow")
-		}
-		return uint32(val), nil
-
-	case int8:
-		if val < 0 {
-			return 0, fmt.Errorf("negative int8")
-		}
-		return uint32(val), nil
-
-	case int16:
-		if val < 0 {
-			return 0, fmt.Errorf("negative int16")
-		}
-		return uint32(val), nil
-
-	case int32:
-		if val < 0 {
-			return 0, fmt.Errorf("negative int32")
-		}
-		return uint32(val), nil
-
-	case int64:
-		if val < 0 || uint64(val) > max {
-			return 0, fmt.Errorf("int64 overflow")
-		}
-		return uint32(val), nil
-
-	// ===== uint =====
-	case uint:
-		if uint64(val) > max {
-			return 0, fmt.Errorf("uint overflow")
-		}
-		return uint32(val), nil
-
-	case uint8:
-		return uint32(val), nil
-	case uint16:
-		return uint32(val), nil
-	case uint32:
-		return val, nil
-
-	case uint64:
-		if val > max {
-			return 0, fmt.Errorf("uint64 overflow")
-		}
-		return uint32(val), nil
-
-	// ===== float =====
-	case float32:
-		f := float64(val)
-		if f < 0 || f > float64(max) {
-			return 0, fmt.Errorf("float32 overflow")
-		}
-		return uint32(f), nil
-
-	case float64:
-		if val < 0 || val > float64(max) {
-			return 0, fmt.Errorf("float64 overflow")
-		}
-		return uint32(val), nil
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1, nil
-		}
-		return 0, nil
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-
-		if u, err := strconv.ParseUint(s, 10, 32); err == nil {
-			return uint32(u), nil
-		}
-
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return 0, fmt.Errorf("string parse error: %v", err)
-		}
-
-		if f < 0 || f > float64(max) {
-			return 0, fmt.Errorf("float overflow")
-		}
-
-		return uint32(f), nil
-
-	// ===== []byte =====
-	case []byte:
-		return ToUint32Err(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		if i, err := val.Int64(); err == nil && i >= 0 {
-			return ToUint32Err(i)
-		}
-		if f, err := val.Float64(); err == nil {
-			return ToUint32Err(f)
-		}
-		return 0, fmt.Errorf("json.Number parse error")
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToUint32Err(val.String())
-
-	case time.Time:
-		return ToUint32Err(val.Unix())
-	}
-
-	// ===== 指针 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0, fmt.Errorf("nil pointer")
-		}
-		return ToUint32Err(rv.Elem().Interface())
-	}
-
-	return 0, fmt.Errorf("unsupported type: %T", v)
-}
-
-// ToFloat64 将任意类型转换为 float64，无法转换时返回 0
-func ToFloat64(v interface{}) float64 {
-	if v == nil {
-		return 0
-	}
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		return float64(val)
-	case int8:
-		return float64(val)
-	case int16:
-		return float64(val)
-	case int32:
-		return float64(val)
-	case int64:
-		return float64(val)
-
-	// ===== uint =====
-	case uint:
-		return float64(val)
-	case uint8:
-		return float64(val)
-	case uint16:
-		return float64(val)
-	case uint32:
-		return float64(val)
-	case uint64:
-		return float64(val)
-
-	// ===== float =====
-	case float32:
-		return float64(val)
-	case float64:
-		return val
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1
-		}
-		return 0
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-		if s == "" {
-			return 0
-		}
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return 0
-		}
-		return f
-
-	// ===== []byte =====
-	case []byte:
-		return ToFloat64(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		f, err := val.Float64()
-		if err != nil {
-			return 0
-		}
-		return f
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToFloat64(val.String())
-
-	case error:
-		return 0
-
-	case time.Time:
-		return float64(val.Unix())
-
-	// ===== rune slice =====
-	case []rune:
-		return ToFloat64(string(val))
-	}
-
-	// ===== 指针统一处理 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0
-		}
-		return ToFloat64(rv.Elem().Interface())
-	}
-
-	return 0
-}
-
-// ToFloat64Err 将任意类型转换为 float64，无法转换时返回错误
-func ToFloat64Err(v interface{}) (float64, error) {
-	if v == nil {
-		return 0, fmt.Errorf("val is nil")
-	}
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		return float64(val), nil
-	case int8:
-		return float64(val), nil
-	case int16:
-		return float64(val), nil
-	case int32:
-		return float64(val), nil
-	case int64:
-		return float64(val), nil
-
-	// ===== uint =====
-	case uint:
-		return float64(val), nil
-	case uint8:
-		return float64(val), nil
-	case uint16:
-		return float64(val), nil
-	case uint32:
-		return float64(val), nil
-	case uint64:
-		return float64(val), nil
-
-	// ===== float =====
-	case float32:
-		return float64(val), nil
-	case float64:
-		return val, nil
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1, nil
-		}
-		return 0, nil
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-		if s == "" {
-			return 0, fmt.Errorf("empty string")
-		}
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return 0, fmt.Errorf("string parse error: %v", err)
-		}
-		return f, nil
-
-	// ===== []byte =====
-	case []byte:
-		return ToFloat64Err(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		return val.Float64()
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToFloat64Err(val.String())
-
-	case error:
-		return 0, fmt.Errorf("cannot convert error to float64")
-
-	case time.Time:
-		return float64(val.Unix()), nil
-
-	case []rune:
-		return ToFloat64Err(string(val))
-	}
-
-	// ===== 指针 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0, fmt.Errorf("nil pointer")
-		}
-		return ToFloat64Err(rv.Elem().Interface())
-	}
-
-	return 0, fmt.Errorf("unsupported type: %T", v)
-}
-
-// ToFloat 将任意类型转换为 float32，无法转换时返回 0
-func ToFloat(v interface{}) float32 {
-	if v == nil {
-		return 0
-	}
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		return float32(val)
-	case int8:
-		return float32(val)
-	case int16:
-		return float32(val)
-	case int32:
-		return float32(val)
-	case int64:
-		return float32(val)
-
-	// ===== uint =====
-	case uint:
-		return float32(val)
-	case uint8:
-		return float32(val)
-	case uint16:
-		return float32(val)
-	case uint32:
-		return float32(val)
-	case uint64:
-		return float32(val)
-
-	// ===== float =====
-	case float32:
-		return val
-	case float64:
-		return float32(val)
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1
-		}
-		return 0
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-		if s == "" {
-			return 0
-		}
-		f, err := strconv.ParseFloat(s, 32)
-		if err != nil {
-			return 0
-		}
-		return float32(f)
-
-	// ===== []byte =====
-	case []byte:
-		return ToFloat(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		f, err := val.Float64()
-		if err != nil {
-			return 0
-		}
-		return float32(f)
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToFloat(val.String())
-
-	case error:
-		return 0
-
-	case time.Time:
-		return float32(val.Unix())
-
-	// ===== rune =====
-	case []rune:
-		return ToFloat(string(val))
-	}
-
-	// ===== 指针统一处理 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0
-		}
-		return ToFloat(rv.Elem().Interface())
-	}
-
-	return 0
-}
-
-// ToFloatErr 将任意类型转换为 float32，无法转换时返回错误
-func ToFloatErr(v interface{}) (float32, error) {
-	if v == nil {
-		return 0, fmt.Errorf("val is nil")
-	}
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		return float32(val), nil
-	case int8:
-		return float32(val), nil
-	case int16:
-		return float32(val), nil
-	case int32:
-		return float32(val), nil
-	case int64:
-		return float32(val), nil
-
-	// ===== uint =====
-	case uint:
-		return float32(val), nil
-	case uint8:
-		return float32(val), nil
-	case uint16:
-		return float32(val), nil
-	case uint32:
-		return float32(val), nil
-	case uint64:
-		return float32(val), nil
-
-	// ===== float =====
-	case float32:
-		return val, nil
-	case float64:
-		return float32(val), nil
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1, nil
-		}
-		return 0, nil
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-		if s == "" {
-			return 0, fmt.Errorf("empty string")
-		}
-		f, err := strconv.ParseFloat(s, 32)
-		if err != nil {
-			return 0, fmt.Errorf("string parse error: %v", err)
-		}
-		return float32(f), nil
-
-	// ===== []byte =====
-	case []byte:
-		return ToFloatErr(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		f, err := val.Float64()
-		if err != nil {
-			return 0, err
-		}
-		return float32(f), nil
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToFloatErr(val.String())
-
-	case error:
-		return 0, fmt.Errorf("cannot convert error to float32")
-
-	case time.Time:
-		return float32(val.Unix()), nil
-
-	case []rune:
-		return ToFloatErr(string(val))
-	}
-
-	// ===== 指针 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0, fmt.Errorf("nil pointer")
-		}
-		return ToFloatErr(rv.Elem().Interface())
-	}
-
-	return 0, fmt.Errorf("unsupported type: %T", v)
-}
-
-// ToUint64 将任意类型转换为 uint64，无法转换或负数时返回 0
-func ToUint64(v interface{}) uint64 {
-	if v == nil {
-		return 0
-	}
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		if val < 0 {
-			return 0
-		}
-		return uint64(val)
-	case int8:
-		if val < 0 {
-			return 0
-		}
-		return uint64(val)
-	case int16:
-		if val < 0 {
-			return 0
-		}
-		return uint64(val)
-	case int32:
-		if val < 0 {
-			return 0
-		}
-		return uint64(val)
-	case int64:
-		if val < 0 {
-			return 0
-		}
-		return uint64(val)
-
-	// ===== uint =====
-	case uint:
-		return uint64(val)
-	case uint8:
-		return uint64(val)
-	case uint16:
-		return uint64(val)
-	case uint32:
-		return uint64(val)
-	case uint64:
-		return val
-
-	// ===== float =====
-	case float32:
-		if val < 0 {
-			return 0
-		}
-		return uint64(val)
-	case float64:
-		if val < 0 {
-			return 0
-		}
-		return uint64(val)
-
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1
-		}
-		return 0
-
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-		if s == "" {
-			return 0
-		}
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil || f < 0 {
-			return 0
-		}
-		return uint64(f)
-
-	// ===== []byte =====
-	case []byte:
-		return ToUint64(string(val))
-
-	// ===== json.Number =====
-	case json.Number:
-		i, err := val.Int64()
-		if err != nil || i < 0 {
-			return 0
-		}
-		return uint64(i)
-
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToUint64(val.String())
-
-	case error:
-		return 0
-
-	case time.Time:
-		return uint64(val.Unix())
-
-	// ===== rune =====
-	case []rune:
-		return ToUint64(string(val))
-	}
-
-	// ===== 指针处理 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0
+	case reflect.Map, reflect.Struct:
+		return json.Marshal(v)
+
+	case reflect.Slice, reflect.Array:
+		// 排除 []byte
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v.([]byte), nil
 		}
-		return ToUint64(rv.Elem().Interface())
+		return json.Marshal(v)
+	default:
+		return nil, fmt.Errorf("unsupported type: %T", v)
 	}
 
-	return 0
 }
 
-// ToUint64Err 将任意类型转换为 uint64，无法转换或为负数时返回错误
-func ToUint64Err(v interface{}) (uint64, error) {
-	if v == nil {
-		return 0, fmt.Errorf("val is nil")
-	}
-
-	switch val := v.(type) {
-
-	// ===== int =====
-	case int:
-		if val < 0 {
-			return 0, fmt.Errorf("int < 0: %d", val)
-		}
-		return uint64(val), nil
-	case int8:
-		if val < 0 {
-			return 0, fmt.Errorf("int8 < 0: %d", val)
-		}
-		return uint64(val), nil
-	case int16:
-		if val < 0 {
-			return 0, fmt.Errorf("int16 < 0: %d", val)
-		}
-		return uint64(val), nil
-	case int32:
-		if val < 0 {
-			return 0, fmt.Errorf("int32 < 0: %d", val)
-		}
-		return uint64(val), nil
-	case int64:
-		if val < 0 {
-			return 0, fmt.Errorf("int64 < 0: %d", val)
-		}
-		return uint64(val), nil
-
-	// ===== uint =====
-	case uint:
-		return uint64(val), nil
-	case uint8:
-		return uint64(val), nil
-	case uint16:
-		return uint64(val), nil
-	case uint32:
-		return uint64(val), nil
-	case uint64:
-		return val, nil
+// ToInt64 将任意类型转换为 int64，无法转换时或超出范围时返回 0
+func ToInt64(v interface{}) int64 {
+	return ConvertOr[int64](v, 0)
+}
 
-	// ===== float =====
-	case float32:
-		if val < 0 {
-			return 0, fmt.Errorf("float32 < 0: %f", val)
-		}
-		return uint64(val), nil
-	case float64:
-		if val < 0 {
-			return 0, fmt.Errorf("float64 < 0: %f", val)
-		}
-		return uint64(val), nil
+// ToInt64WithErr 将任意类型转换为 int64，无法转换或超出范围时返回错误
+func ToInt64Err(v interface{}) (int64, error) {
+	return Convert[int64](v)
+}
 
-	// ===== bool =====
-	case bool:
-		if val {
-			return 1, nil
-		}
-		return 0, nil
+// ToInt 将任意类型转换为 int，无法转换时返回 0
+func ToInt(v interface{}) int {
+	return ConvertOr[int](v, 0)
+}
 
-	// ===== string =====
-	case string:
-		s := strings.TrimSpace(val)
-		if s == "" {
-			return 0, fmt.Errorf("empty string")
-		}
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return 0, fmt.Errorf("parse error: %v", err)
-		}
-		if f < 0 {
-			return 0, fmt.Errorf("negative value: %f", f)
-		}
-		return uint64(f), nil
+// ToIntErr 将任意类型转换为 int，无法转换或溢出时返回错误
+func ToIntErr(v interface{}) (int, error) {
+	return Convert[int](v)
+}
 
-	// ===== []byte =====
-	case []byte:
-		return ToUint64Err(string(val))
+// ToUint32 将任意类型转换为 uint32，无法转换或溢出时返回 0
+func ToUint32(v interface{}) uint32 {
+	return ConvertOr[uint32](v, 0)
+}
 
-	// ===== json.Number =====
-	case json.Number:
-		i, err := val.Int64()
-		if err != nil {
-			return 0, err
-		}
-		if i < 0 {
-			return 0, fmt.Errorf("negative json.Number")
-		}
-		return uint64(i), nil
+// ToUint32Err 将任意类型转换为 uint32，无法转换或溢出时返回错误
+func ToUint32Err(v interface{}) (uint32, error) {
+	return Convert[uint32](v)
+}
 
-	// ===== 接口 =====
-	case fmt.Stringer:
-		return ToUint64Err(val.String())
+// ToFloat64 将任意类型转换为 float64，无法转换时返回 0
+func ToFloat64(v interface{}) float64 {
+	return ConvertOr[float64](v, 0)
+}
 
-	case error:
-		return 0, fmt.Errorf("cannot convert error to uint64")
+// ToFloat64Err 将任意类型转换为 float64，无法转换时返回错误
+func ToFloat64Err(v interface{}) (float64, error) {
+	return Convert[float64](v)
+}
 
-	case time.Time:
-		return uint64(val.Unix()), nil
+// ToFloat 将任意类型转换为 float32，无法转换时返回 0
+func ToFloat(v interface{}) float32 {
+	return ConvertOr[float32](v, 0)
+}
 
-	case []rune:
-		return ToUint64Err(string(val))
-	}
+// ToFloatErr 将任意类型转换为 float32，无法转换时返回错误
+func ToFloatErr(v interface{}) (float32, error) {
+	return Convert[float32](v)
+}
 
-	// ===== 指针 =====
-	rv := reflect.ValueOf(v)
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return 0, fmt.Errorf("nil pointer")
-		}
-		return ToUint64Err(rv.Elem().Interface())
-	}
+// ToUint64 将任意类型转换为 uint64，无法转换或负数时返回 0
+func ToUint64(v interface{}) uint64 {
+	return ConvertOr[uint64](v, 0)
+}
 
-	return 0, fmt.Errorf("unsupported type: %T", v)
+// ToUint64Err 将任意类型转换为 uint64，无法转换或为负数时返回错误
+func ToUint64Err(v interface{}) (uint64, error) {
+	return Convert[uint64](v)
 }
 
 // HexStringToBytes 将十六进制字符串转换为 []byte
@@ -2118,91 +717,7 @@ func BytesToHexString(data []byte, withSpace bool) string {
 
 // ToBool 将任意类型转换为 bool，无法转换时返回 false 不适用高标准环境
 func ToBool(v interface{}) bool {
-	switch val := v.(type) {
-	case bool:
-		return val
-	case *bool:
-		if val != nil {
-			return *val
-		}
-
-	case string:
-		s := strings.TrimSpace(strings.ToLower(val))
-		if s == "true" || s == "1" || s == "yes" || s == "y" || s == "on" {
-			return true
-		}
-		return false
-	case *string:
-		if val != nil {
-			s := strings.TrimSpace(strings.ToLower(*val))
-			if s == "true" || s == "1" || s == "yes" || s == "y" || s == "on" {
-				return true
-			}
-		}
-
-	case []byte:
-		s := strings.TrimSpace(strings.ToLower(string(val)))
-		if s == "true" || s == "1" || s == "yes" || s == "y" || s == "on" {
-			return true
-		}
-		return false
-	case *([]byte):
-		if val != nil {
-			s := strings.TrimSpace(strings.ToLower(string(*val)))
-			if s == "true" || s == "1" || s == "yes" || s == "y" || s == "on" {
-				return true
-			}
-		}
-
-	case int:
-		return val != 0
-	case *int:
-		if val != nil {
-			return *val != 0
-		}
-
-	case int64:
-		return val != 0
-	case *int64:
-		if val != nil {
-			return *val != 0
-		}
-
-	case int32:
-		return val != 0
-	case *int32:
-		if val != nil {
-			return *val != 0
-		}
-
-	case float64:
-		return val != 0
-	case *float64:
-		if val != nil {
-			return *val != 0
-		}
-
-	case float32:
-		return val != 0
-	case *float32:
-		if val != nil {
-			return *val != 0
-		}
-
-	case json.Number:
-		f, err := val.Float64()
-		if err == nil {
-			return f != 0
-		}
-
-	default:
-		if v == nil {
-			return false
-		}
-		fmt.Printf("⚡ ToBool遇到未知类型：%T -> %+v\n", v, v)
-	}
-
-	return false
+	return ConvertOr[bool](v, false)
 }
 
 func parseBoolStr(s string) (bool, error) {
@@ -2220,84 +735,7 @@ func parseBoolStr(s string) (bool, error) {
 
 // ToBoolErr 将任意类型转换为 bool，无法转换时返回 error（适用于严格环境）
 func ToBoolErr(v interface{}) (bool, error) {
-	switch val := v.(type) {
-	case bool:
-		return val, nil
-	case *bool:
-		if val != nil {
-			return *val, nil
-		}
-		return false, fmt.Errorf("nil *bool")
-
-	case string:
-		return parseBoolStr(val)
-	case *string:
-		if val != nil {
-			return parseBoolStr(*val)
-		}
-		return false, fmt.Errorf("nil *string")
-
-	case []byte:
-		return parseBoolStr(string(val))
-	case *([]byte):
-		if val != nil {
-			return parseBoolStr(string(*val))
-		}
-		return false, fmt.Errorf("nil *[]byte")
-
-	case int:
-		return val != 0, nil
-	case *int:
-		if val != nil {
-			return *val != 0, nil
-		}
-		return false, fmt.Errorf("nil *int")
-
-	case int64:
-		return val != 0, nil
-	case *int64:
-		if val != nil {
-			return *val != 0, nil
-		}
-		return false, fmt.Errorf("nil *int64")
-
-	case int32:
-		return val != 0, nil
-	case *int32:
-		if val != nil {
-			return *val != 0, nil
-		}
-		return false, fmt.Errorf("nil *int32")
-
-	case float64:
-		return val != 0, nil
-	case *float64:
-		if val != nil {
-			return *val != 0, nil
-		}
-		return false, fmt.Errorf("nil *float64")
-
-	case float32:
-		return val != 0, nil
-	case *float32:
-		if val != nil {
-			return *val != 0, nil
-		}
-		return false, fmt.Errorf("nil *float32")
-
-	case json.Number:
-		f, err := val.Float64()
-		if err != nil {
-			return false, fmt.Errorf("json.Number 转 float64 失败: %w", err)
-		}
-		return f != 0, nil
-
-	default:
-		if v == nil {
-			return false, fmt.Errorf("nil value")
-		}
-		return false, fmt.Errorf("不支持的类型: %T", v)
-	}
+	return Convert[bool](v)
 }
 
 // Base64Convert 通用 Base64 编码/解码函数
@@ -2338,11 +776,19 @@ func Base64Convert(data interface{}, encode bool) ([]byte, error) {
 
 // GetFormatTimeByParam 根据参数截取时间
 //
-// now: 时间文本，可为空，如果为空则取当前时间
+// now: 时间文本，可为空，如果为空则取当前时间；支持的格式见 ParseTime
 // param: 截取级别，可空，默认0
 //
 //	0=秒, 1=年, 2=月, 3=日, 4=小时, 5=分钟
+//
+// 解析失败返回空字符串，需要错误信息时使用 GetFormatTimeByParamErr
 func GetFormatTimeByParam(now string, param int) string {
+	s, _ := GetFormatTimeByParamErr(now, param)
+	return s
+}
+
+// GetFormatTimeByParamErr 与 GetFormatTimeByParam 相同，但解析失败时返回错误（不会用当前时间代替）
+func GetFormatTimeByParamErr(now string, param int) (string, error) {
 	var t time.Time
 	var err error
 
@@ -2350,28 +796,26 @@ func GetFormatTimeByParam(now string, param int) string {
 	if now == "" {
 		t = time.Now()
 	} else {
-		// 尝试解析输入时间
-		t, err = time.Parse("2006-01-02 15:04:05", now)
+		t, err = ParseTime(now)
 		if err != nil {
-			// 解析失败用当前时间
-			t = time.Now()
+			return "", err
 		}
 	}
 
 	// 根据 param 返回对应格式
 	switch param {
 	case 1:
-		return t.Format("2006")
+		return t.Format("2006"), nil
 	case 2:
-		return t.Format("2006-01")
+		return t.Format("2006-01"), nil
 	case 3:
-		return t.Format("2006-01-02")
+		return t.Format("2006-01-02"), nil
 	case 4:
-		return t.Format("2006-01-02 15")
+		return t.Format("2006-01-02 15"), nil
 	case 5:
-		return t.Format("2006-01-02 15:04")
+		return t.Format("2006-01-02 15:04"), nil
 	default:
-		return t.Format("2006-01-02 15:04:05")
+		return t.Format("2006-01-02 15:04:05"), nil
 	}
 }
 
@@ -2379,6 +823,8 @@ func GetFormatTimeByParam(now string, param int) string {
 //
 // ts: 时间戳
 // isMilli: true 表示 ts 为13位毫秒时间戳，false 表示10位秒时间戳
+//
+// 按本地时区输出，需要指定时区或格式时使用 TimeStampToStrIn
 func TimeStampToStr(ts int64, isMilli bool) string {
 	if ts <= 0 {
 		return ""
@@ -2398,33 +844,53 @@ func TimeStampToStr(ts int64, isMilli bool) string {
 
 // TimeToTimeStamp 将时间转换为10位或13位时间戳
 //
-// t: 要转换的时间，可以是 time.Time、字符串或 nil/其他类型
-//
-//	字符串格式应为 "2025-01-02 15:04:05"
+// t: 要转换的时间，可以是 time.Time、*time.Time、字符串（支持的格式见 ParseTime，不含时区信息时按 UTC 解析）或数字时间戳
 //
 // isMilli: true 表示返回13位毫秒时间戳，false 表示10位秒时间戳
+//
+// 解析失败返回 0，需要错误信息时使用 TimeToTimeStampErr
 func TimeToTimeStamp(t interface{}, isMilli bool) int64 {
+	ts, _ := TimeToTimeStampErr(t, isMilli)
+	return ts
+}
+
+// TimeToTimeStampErr 与 TimeToTimeStamp 相同，但解析失败或类型不支持时返回错误（不会用当前时间代替）
+//
+// 不含时区信息的字符串按 UTC 解析，结果与运行环境的本地时区无关；需要按其他时区解析时使用 TimeToTimeStampIn
+func TimeToTimeStampErr(t interface{}, isMilli bool) (int64, error) {
+	return TimeToTimeStampIn(t, isMilli, time.UTC)
+}
+
+// TimeToTimeStampIn 与 TimeToTimeStampErr 相同，但不含时区信息的字符串按 loc 解析（nil 表示 UTC）
+//
+// 使用示例：
+//
+//	sh, _ := time.LoadLocation("Asia/Shanghai")
+//	ts, err := TimeToTimeStampIn("2024-01-02 00:00:00", false, sh) // 1704124800
+func TimeToTimeStampIn(t interface{}, isMilli bool, loc *time.Location) (int64, error) {
 	var tt time.Time
 
 	switch v := t.(type) {
 	case time.Time:
 		tt = v
 	case string:
-		parsed, err := time.Parse("2006-01-02 15:04:05", v)
+		parsed, err := ParseTimeIn(v, loc)
 		if err != nil {
-			// 解析失败用当前时间
-			tt = time.Now()
-		} else {
-			tt = parsed
+			return 0, err
 		}
+		tt = parsed
 	default:
-		tt = time.Now()
+		parsed, err := Convert[time.Time](t)
+		if err != nil {
+			return 0, err
+		}
+		tt = parsed
 	}
 
 	if isMilli {
-		return tt.UnixNano() / int64(time.Millisecond)
+		return tt.UnixMilli(), nil
 	}
-	return tt.Unix()
+	return tt.Unix(), nil
 }
 
 // GetRunPath 获取当前cmd终端目录或当前程序运行目录
@@ -2627,6 +1093,8 @@ func ReadFile(filePath string) ([]byte, error) {
 //  2. 移除英文逗号（避免干扰分隔）
 //  3. 移除双引号（避免错位）
 //  4. 移除换行符（\r \n）
+//
+// Deprecated: 会删除字段中的逗号、引号和换行，导致数据丢失；请使用 FormatCSVLine 或 CSVWriter。
 func CSVStringsToLine(fields []string, lineBreak bool) []byte {
 	for i, str := range fields {
 		// 不允许英文逗号
@@ -2835,35 +1303,25 @@ func FileToUTF8(filePath string) error {
 	}
 
 	// chardet检测编码，此时面对的一定是非BOM文件
-	sample := content
-	if len(sample) > 10240 {
-		sample = sample[:10240]
+	enc, charset, err := detectEncoding(content)
+	if charset != "" {
+		fmt.Println("检测到编码:", charset)
 	}
-
-	detector := chardet.NewTextDetector()
-	result, err := detector.DetectBest(sample)
 	if err != nil {
-		return fmt.Errorf("编码检测失败: %v", err)
+		return err
 	}
-	fmt.Println("检测到编码:", result.Charset)
 
 	// chardet明确识别为UTF-8（外部来源的无BOM文件）
-	if strings.EqualFold(result.Charset, "UTF-8") {
+	if enc == encoding.Nop {
 		return nil
 	}
 
-	// 找对应解码器
-	enc := getEncoding(result.Charset)
-	if enc == nil {
-		return fmt.Errorf("暂不支持的编码类型: %s", result.Charset)
-	}
-
-	fmt.Printf("文件是 %s 编码，开始转换为 UTF-8...\n", result.Charset)
+	fmt.Printf("文件是 %s 编码，开始转换为 UTF-8...\n", charset)
 
 	reader := transform.NewReader(bytes.NewReader(content), enc.NewDecoder())
 	converted, err := io.ReadAll(reader)
 	if err != nil {
-		return fmt.Errorf("%s 转换失败: %v", result.Charset, err)
+		return fmt.Errorf("%s 转换失败: %v", charset, err)
 	}
 
 	//  写入时加BOM，作为"已转换"的永久标记，下次调用直接第一关跳过
@@ -2876,6 +1334,31 @@ func FileToUTF8(filePath string) error {
 	return nil
 }
 
+// detectEncoding 用 chardet 检测 sample（最多取前 10KB）的编码，返回解码器和 chardet 给出的编码名。
+// UTF-8 返回 encoding.Nop；不支持的编码返回错误（此时编码名仍有效）。
+func detectEncoding(sample []byte) (encoding.Encoding, string, error) {
+	if len(sample) > 10240 {
+		sample = sample[:10240]
+	}
+
+	detector := chardet.NewTextDetector()
+	result, err := detector.DetectBest(sample)
+	if err != nil {
+		return nil, "", fmt.Errorf("编码检测失败: %v", err)
+	}
+
+	if strings.EqualFold(result.Charset, "UTF-8") {
+		return encoding.Nop, result.Charset, nil
+	}
+
+	// 找对应解码器
+	enc := getEncoding(result.Charset)
+	if enc == nil {
+		return nil, result.Charset, fmt.Errorf("暂不支持的编码类型: %s", result.Charset)
+	}
+	return enc, result.Charset, nil
+}
+
 // getEncoding 根据检测结果返回对应编码
 func getEncoding(charset string) encoding.Encoding {
 	cs := strings.ToUpper(charset)
@@ -3128,6 +1611,8 @@ func CSVFieldMapper(fields []string, mapping *map[string]int, isHeader bool, fie
 //  1. 如果 fields 为空，则返回空字符串
 //  2. 如果 quoted 为 true，会对每个字段加上双引号
 //  3. 不会自动处理字段中包含逗号或引号的情况，如需处理请自行转义
+//
+// Deprecated: 请使用 FormatCSVLine，它会按 RFC 4180 正确转义。
 func CSVJoinFields(fields []string, quoted bool) string {
 	if len(fields) == 0 {
 		return ""
@@ -3537,6 +2022,8 @@ func HashCalc(data interface{}, mode int, hashType int) (string, bool) {
 }
 
 // CsvCleanStrings 处理字符串切片：去除首尾双引号和空格 一般用于处理csv 行分割后的数据
+//
+// 字段内含逗号、引号或换行时按行分割会出错，读取完整 CSV 文件请使用 NewCSVReader/OpenCSV
 func CsvCleanStrings(input []string) []string {
 	result := make([]string, 0, len(input))
 	for i, s := range input {
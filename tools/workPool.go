@@ -6,17 +6,27 @@ package tools
 //   - 泛型支持：结果类型 T 由调用方指定，同一个 Pool 可处理不同结构体的任务
 //   - 任务超时：每个任务独立设置截止时间，超时后自动取消
 //   - 自动重试：失败任务按指数退避策略自动重试，可配置最大次数
-//   - 限速控制：令牌桶模型，精确控制每秒最多启动多少个任务
-//   - 动态扩容：队列积压时自动增加 Worker 数量，也支持手动调整
-//   - 优雅停止：StopGraceful() 等待所有队列中的任务执行完毕后再退出
-//   - 实时指标：原子计数器实时统计成功/失败/重试/队列深度等数据
+//   - 限速控制：令牌桶模型，精确控制每秒最多启动多少个任务，支持突发
+//   - 按 key 限流：实现 Keyed 的任务按 key 单独限制并发和速率，慢域名不会拖垮整个 Pool
+//   - 自适应并发：按执行耗时和错误率自动调整并发上限与速率（AIMD）
+//   - 动态扩缩容：队列积压时自动增加 Worker，空闲超时后自动回收，也支持手动调整
+//   - 优雅停止：StopGraceful() 等待所有队列中的任务执行完毕后再退出，StopGracefulContext 可限定等待时间
+//   - 运维控制：Pause/Resume 暂停和恢复取任务，Drain 等待已提交任务全部结束而不关闭 Pool
+//   - 实时指标：原子计数器实时统计成功/失败/重试/队列深度等数据，延迟直方图可导出为 Prometheus 格式
 //   - 事件回调：任务成功或彻底失败时触发用户自定义钩子函数
+//   - 结构化事件：入队/执行/重试/超时/完成/扩缩容/暂停/停止均产生类型化 Event，可订阅或输出到 log/slog
+//   - 任务句柄：Submit 返回 Future，可等待结果、查询状态或单独取消某个任务
+//   - 熔断保护：下游持续失败时熔断器断开，任务快速失败，冷却后半开试探恢复
+//   - 崩溃隔离：任务 panic 被转换为带堆栈的 PanicError，Worker 继续工作
 
 import (
 	"context"
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"math"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -80,6 +90,31 @@ type Result[T any] struct {
 	Duration time.Duration
 }
 
+// ErrTaskTimeout 表示单次执行超过了 TaskTimeout，可用 errors.Is 判断。
+var ErrTaskTimeout = errors.New("任务执行超时")
+
+// PanicError 表示任务在 Run() 中发生了 panic，由 Pool 捕获后作为 Result.Err 返回。
+// 可用 errors.As 判断；若 panic 的值本身是 error，errors.Is/As 也能穿透访问它。
+//
+// panic 通常意味着程序缺陷，重试大概率仍会 panic，因此发生 panic 的任务不会被重试。
+type PanicError struct {
+	TaskID string // 发生 panic 的任务
+	Value  any    // recover() 得到的原始值
+	Stack  []byte // panic 发生时的 goroutine 堆栈
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("workerpool: task %q panicked: %v", e.TaskID, e.Value)
+}
+
+// Unwrap 在 panic 的值是 error 时返回它。
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
 // =============================================================================
 // Options：Pool 配置项
 // =============================================================================
@@ -87,6 +122,10 @@ type Result[T any] struct {
 // Options 用于配置 WorkerPool 的全部行为参数。
 // 所有字段均有合理默认值，未设置时由 setDefaults() 补全。
 type Options struct {
+	// Name 是 Pool 的名称，用于日志和导出指标时的 pool 标签。
+	// 默认值："default"。
+	Name string
+
 	// Workers 是 Pool 启动时创建的初始 Worker（goroutine）数量。
 	// 同时也是动态缩容的下限：Pool 不会低于此数量。
 	// 默认值：4。
@@ -97,6 +136,11 @@ type Options struct {
 	// 动态扩容策略：当队列积压任务数 > 当前 Worker 数时，每次触发增加 1 个 Worker。
 	MaxWorkers int
 
+	// IdleTimeout 是 Worker 连续空闲多久后自动退出。
+	// 只有超出下限（Workers，或最近一次 Scale(n) 的 n）的 Worker 才会退出。
+	// 默认值：60s；设为负数表示禁用空闲回收。
+	IdleTimeout time.Duration
+
 	// QueueSize 是任务队列（channel）的缓冲容量。
 	// Submit() 时若队列已满会立即返回错误，不会阻塞调用方。
 	// 默认值：1024。
@@ -120,9 +164,35 @@ type Options struct {
 	// RateLimit 限制每秒最多启动的任务数（令牌桶模型）。
 	// Worker 在取到任务后、真正调用 Run() 前，会先等待令牌可用。
 	// 设为 0 表示不限速。
-	// 示例：RateLimit=10.0 表示每 100ms 最多启动 1 个任务。
+	// 示例：RateLimit=10.0 表示平均每 100ms 启动 1 个任务。
 	RateLimit float64
 
+	// RateBurst 是全局令牌桶的容量，即空闲一段时间后允许瞬间连续启动的任务数。
+	// 默认值：1（不允许突发）。
+	RateBurst int
+
+	// KeyConcurrency 限制同一 key（见 Keyed 接口）同时执行的任务数，0 表示不限制。
+	// 达到上限的任务会被暂存，Worker 转而处理其他任务，不会被占住。
+	KeyConcurrency int
+
+	// KeyRateLimit 限制同一 key 每秒最多启动的任务数，0 表示不限速。
+	// 等待 key 令牌时 Worker 会被占用，建议与 KeyConcurrency 同时设置以限制占用数量。
+	KeyRateLimit float64
+
+	// KeyRateBurst 是每个 key 的令牌桶容量。
+	// 默认值：1。
+	KeyRateBurst int
+
+	// Adaptive 启用自适应并发控制（见 workPoolAdaptive.go），为 nil 表示不启用。
+	// 启用后 Worker 数量由控制器在 [Workers, MaxWorkers] 内调整，不再按队列积压自动扩容；
+	// 若同时设置了 RateLimit，实际速率在 [MinRate, RateLimit] 内调整。
+	// 当前并发上限和速率可通过 Metrics().ConcurrencyLimit / CurrentRate 查看。
+	Adaptive *AdaptiveOptions
+
+	// Breaker 启用熔断器（见 workPoolBreaker.go），为 nil 表示不启用。
+	// 熔断器断开时任务不再执行，直接以包装了 ErrBreakerOpen 的错误失败，且不会重试。
+	Breaker *BreakerOptions
+
 	// OnSuccess 是任务成功后触发的回调函数。
 	// 在独立的 goroutine 中异步调用，不会阻塞 Worker。
 	// 可用于记录指标、发送通知等。参数：任务ID、总耗时。
@@ -134,9 +204,22 @@ type Options struct {
 	OnFailure func(taskID string, err error, attempts int)
 
 	// Logger 是自定义日志函数，签名与 log.Printf 相同。
-	// 若不设置，默认使用标准库 log.Printf 输出到 stderr。
+	// 若不设置，默认使用标准库 log.Printf 输出到 stderr；设置了 Slog 时默认不输出。
 	// 可替换为 zap/logrus 等结构化日志库的适配函数。
 	Logger func(format string, args ...any)
+
+	// Slog 设置后，所有生命周期事件以结构化日志输出到该 slog.Logger（见 workPoolEvents.go），
+	// 并替代默认的 printf 风格 Logger。
+	Slog *slog.Logger
+
+	// OnEvent 是生命周期事件的同步回调，在产生事件的 goroutine 中直接调用，必须快速返回且不可阻塞。
+	// 部分事件在持有 Pool 内部锁时产生，回调中不可调用该 Pool 的方法。
+	// 也可以通过 WorkerPool.Events 以 channel 方式订阅。
+	OnEvent func(e Event)
+
+	// LatencyBuckets 是排队等待时间和执行耗时直方图的桶上界（单位：秒）。
+	// 默认值：DefaultLatencyBuckets。
+	LatencyBuckets []float64
 }
 
 // setDefaults 为未显式设置的字段填充合理的默认值。
@@ -152,8 +235,38 @@ func (o *Options) setDefaults() {
 	if o.QueueSize <= 0 {
 		o.QueueSize = 1024 // 默认队列容量 1024
 	}
+	if o.IdleTimeout == 0 {
+		o.IdleTimeout = 60 * time.Second // 默认空闲 60 秒后回收多余 Worker
+	}
 	if o.Logger == nil {
-		o.Logger = log.Printf // 默认使用标准库日志
+		if o.Slog != nil {
+			o.Logger = func(string, ...any) {} // 由 Slog 输出结构化事件，不再重复输出文本日志
+		} else {
+			o.Logger = log.Printf // 默认使用标准库日志
+		}
+	}
+	if o.Name == "" {
+		o.Name = "default"
+	}
+	if len(o.LatencyBuckets) == 0 {
+		o.LatencyBuckets = DefaultLatencyBuckets
+	}
+	if o.RateBurst <= 0 {
+		o.RateBurst = 1
+	}
+	if o.KeyRateBurst <= 0 {
+		o.KeyRateBurst = 1
+	}
+	if o.Breaker != nil {
+		b := *o.Breaker
+		b.setDefaults()
+		o.Breaker = &b
+	}
+	if o.Adaptive != nil {
+		// 复制一份，避免修改调用方持有的配置
+		a := *o.Adaptive
+		a.setDefaults(o.RateLimit)
+		o.Adaptive = &a
 	}
 }
 
@@ -176,14 +289,48 @@ type Metrics struct {
 	// Retried 是触发重试的总次数（一个任务重试 3 次则计 3）。
 	Retried atomic.Int64
 
+	// Cancelled 是通过 Future.Cancel 或 Cancel(taskID) 被主动取消的任务总数（不计入 Failed）。
+	Cancelled atomic.Int64
+
+	// Panicked 是 Run() 发生 panic 并被捕获的总次数。
+	Panicked atomic.Int64
+
+	// BreakerRejected 是因熔断器断开而未执行、直接失败的次数。
+	BreakerRejected atomic.Int64
+
+	// BreakersOpen 是当前处于断开或半开状态的熔断器数量，为实时瞬时值。
+	BreakersOpen atomic.Int64
+
 	// InFlight 是当前正在执行中（Run() 尚未返回）的任务数量，为实时瞬时值。
 	InFlight atomic.Int64
 
+	// EventsDropped 是因订阅 channel 已满而被丢弃的事件数（见 WorkerPool.Events）。
+	EventsDropped atomic.Int64
+
+	// Abandoned 是已超过 TaskTimeout、结果已被放弃但 Run() 仍未返回的执行数量，为实时瞬时值。
+	// 持续增长说明有任务没有正确监听 ctx，其 goroutine 正在泄漏。
+	Abandoned atomic.Int64
+
 	// QueueDepth 是当前队列中等待被 Worker 取走的任务数量，为实时瞬时值。
 	QueueDepth atomic.Int64
 
 	// Workers 是当前活跃的 Worker goroutine 数量，为实时瞬时值。
 	Workers atomic.Int64
+
+	// ConcurrencyLimit 是自适应模式下的当前并发上限；未启用自适应模式时为 0。
+	ConcurrencyLimit atomic.Int64
+
+	// CurrentRate 是当前生效的每秒启动任务数上限（float64 的位模式）；未限速时为 0。
+	CurrentRate atomic.Uint64
+
+	// QueueWait 是任务从入队到被 Worker 取走的等待时间分布（秒）。
+	QueueWait *Histogram
+
+	// RunDuration 是任务所有执行轮次累计耗时的分布（秒），与 Result.Duration 一致。
+	RunDuration *Histogram
+
+	// Attempts 是每个已结束任务的执行次数分布，与 Result.Attempts 一致。
+	Attempts *Histogram
 }
 
 // Snapshot 对所有指标做一次原子快照，返回值类型（MetricsSnapshot）可安全打印和传递。
@@ -193,9 +340,22 @@ func (m *Metrics) Snapshot() MetricsSnapshot {
 		Succeeded:  m.Succeeded.Load(),
 		Failed:     m.Failed.Load(),
 		Retried:    m.Retried.Load(),
+		Cancelled:  m.Cancelled.Load(),
+		Panicked:   m.Panicked.Load(),
 		InFlight:   m.InFlight.Load(),
+		Abandoned:  m.Abandoned.Load(),
 		QueueDepth: m.QueueDepth.Load(),
 		Workers:    m.Workers.Load(),
+
+		EventsDropped:    m.EventsDropped.Load(),
+		BreakerRejected:  m.BreakerRejected.Load(),
+		BreakersOpen:     m.BreakersOpen.Load(),
+		ConcurrencyLimit: m.ConcurrencyLimit.Load(),
+		CurrentRate:      math.Float64frombits(m.CurrentRate.Load()),
+
+		QueueWait:   m.QueueWait.Snapshot(),
+		RunDuration: m.RunDuration.Snapshot(),
+		Attempts:    m.Attempts.Snapshot(),
 	}
 }
 
@@ -205,17 +365,40 @@ type MetricsSnapshot struct {
 	Succeeded  int64 // 累计成功任务数
 	Failed     int64 // 累计失败任务数
 	Retried    int64 // 累计重试次数
+	Cancelled  int64 // 累计被主动取消的任务数
+	Panicked   int64 // 累计捕获的 panic 次数
 	InFlight   int64 // 当前执行中任务数
+	Abandoned  int64 // 当前超时后仍未返回的执行数
 	QueueDepth int64 // 当前队列深度
 	Workers    int64 // 当前 Worker 数量
+
+	EventsDropped    int64   // 累计被丢弃的事件数
+	BreakerRejected  int64   // 累计被熔断器拒绝的次数
+	BreakersOpen     int64   // 当前断开或半开的熔断器数量
+	ConcurrencyLimit int64   // 自适应模式下的当前并发上限（未启用时为 0）
+	CurrentRate      float64 // 当前生效的速率上限（每秒任务数，未限速时为 0）
+
+	QueueWait   HistogramSnapshot // 排队等待时间分布（秒）
+	RunDuration HistogramSnapshot // 执行耗时分布（秒）
+	Attempts    HistogramSnapshot // 执行次数分布
 }
 
 // String 实现 fmt.Stringer，方便直接打印快照内容。
 func (s MetricsSnapshot) String() string {
-	return fmt.Sprintf(
-		"workers=%d queue=%d submitted=%d succeeded=%d failed=%d retried=%d in-flight=%d",
-		s.Workers, s.QueueDepth, s.Submitted, s.Succeeded, s.Failed, s.Retried, s.InFlight,
+	str := fmt.Sprintf(
+		"workers=%d queue=%d submitted=%d succeeded=%d failed=%d retried=%d cancelled=%d in-flight=%d",
+		s.Workers, s.QueueDepth, s.Submitted, s.Succeeded, s.Failed, s.Retried, s.Cancelled, s.InFlight,
 	)
+	if s.Panicked > 0 || s.Abandoned > 0 {
+		str += fmt.Sprintf(" panicked=%d abandoned=%d", s.Panicked, s.Abandoned)
+	}
+	if s.BreakerRejected > 0 || s.BreakersOpen > 0 {
+		str += fmt.Sprintf(" breaker-rejected=%d breakers-open=%d", s.BreakerRejected, s.BreakersOpen)
+	}
+	if s.ConcurrencyLimit > 0 {
+		str += fmt.Sprintf(" limit=%d", s.ConcurrencyLimit)
+	}
+	return str
 }
 
 // =============================================================================
@@ -230,6 +413,12 @@ type job[T any] struct {
 	// resultCh 是调用方可选提供的结果接收 channel。
 	// 若为 nil（使用 Submit 而非 SubmitWithResult），则任务结果直接丢弃（fire-and-forget 模式）。
 	resultCh chan<- Result[T]
+
+	// fut 是任务的结果句柄，同时持有任务专属的 context（见 workPoolFuture.go）。
+	fut *Future[T]
+
+	// enqueuedAt 是任务入队时间，用于统计排队等待时间。
+	enqueuedAt time.Time
 }
 
 // =============================================================================
@@ -263,22 +452,56 @@ type WorkerPool[T any] struct {
 	ctx    context.Context
 	cancel context.CancelFunc // 对应 ctx 的取消函数
 
-	workerWg sync.WaitGroup // 等待所有 Worker goroutine 退出
-	mu       sync.Mutex     // 保护 stopped 标志位的写操作
-	stopped  bool           // 标记 Pool 是否已停止，防止重复关闭
+	workerWg    sync.WaitGroup // 等待所有 Worker goroutine 退出
+	mu          sync.Mutex     // 保护 stopped/queueClosed 标志位的写操作
+	stopped     bool           // 标记 Pool 是否已停止，防止重复关闭
+	queueClosed bool           // 标记 queue 是否已关闭（Stop 与 StopGraceful 都会关闭）
+
+	// minWorkers 是 Worker 数量的下限：空闲回收和 Scale 缩容都不会低于它。
+	// 初始为 Options.Workers，调用 Scale(n) 后变为 n。
+	minWorkers atomic.Int64
 
-	// rateTicker 是限速用的时间间隔 ticker channel。
-	// Worker 每次执行任务前需等待此 channel 发出信号（令牌可用）。
+	// retire 是缩容信号：Scale 缩容时投递令牌，空闲的 Worker 收到后尝试退出。
+	// 容量为 MaxWorkers，发送为非阻塞，多余的令牌会因低于下限而被忽略。
+	retire chan struct{}
+
+	// rateLimiter 是全局限速令牌桶，Worker 每次执行任务前需取得一个令牌。
 	// 若 RateLimit == 0，此字段为 nil，Worker 无需等待。
-	rateTicker <-chan time.Time
+	rateLimiter *TokenBucket
 
-	// rateStop 用于通知限速 ticker 的清理 goroutine 退出，释放资源。
-	rateStop chan struct{}
+	// keys 是按 key 限流的状态，未配置 KeyConcurrency/KeyRateLimit 时为 nil（见 workPoolRateLimit.go）。
+	keys *keyLimiter[T]
 
 	// scaleSignal 是向动态扩容 goroutine 发送信号的 channel。
 	// 每次有新任务入队时，Submit 会向此 channel 发一个信号（非阻塞）。
 	// 扩容 goroutine 收到信号后评估是否需要新增 Worker。
 	scaleSignal chan struct{}
+
+	// scheduled 保存通过 SubmitAt/SubmitAfter/SubmitCron 登记、尚未结束的定时任务，
+	// key 为 TaskID，由 schedMu 保护（见 workPoolSchedule.go）。
+	schedMu   sync.Mutex
+	scheduled map[string]*scheduledEntry[T]
+
+	// futures 按 TaskID 索引所有尚未结束的任务句柄，供 Cancel(taskID) 使用，由 futMu 保护。
+	futMu   sync.Mutex
+	futures map[string]map[*Future[T]]struct{}
+
+	// breakers 是熔断器集合，未启用时为 nil（见 workPoolBreaker.go）。
+	breakers *breakerGroup
+
+	// events 管理 Events 订阅者，hasSubs 表示是否有过订阅，用于无消费方时快速跳过（见 workPoolEvents.go）。
+	events    eventHub
+	hasSubs   atomic.Bool
+	stopEvent sync.Once // 保证 EventStopped 只产生一次（Stop 与 StopGraceful 可能先后调用）
+
+	// adaptive 是自适应并发控制器，未启用时为 nil（见 workPoolAdaptive.go）。
+	adaptive *adaptiveController
+
+	// pause 记录 Pause/Resume 状态，暂停时 Worker 不从队列取任务（见 workPoolControl.go）。
+	pause pauseGate
+
+	// active 是已入队但尚未结束的任务数（排队中 + 执行中 + 结果发送中），供 Drain 等待排空。
+	active *activeCounter
 }
 
 // NewPool 创建并启动一个 WorkerPool，立即开始接受任务。
@@ -303,32 +526,38 @@ func NewPool[T any](opts Options) *WorkerPool[T] {
 		ctx:         ctx,
 		cancel:      cancel,
 		scaleSignal: make(chan struct{}, 1), // 容量为 1，防止信号堆积
+		retire:      make(chan struct{}, opts.MaxWorkers),
+		scheduled:   make(map[string]*scheduledEntry[T]),
+		futures:     make(map[string]map[*Future[T]]struct{}),
+		active:      newActiveCounter(),
 	}
 
+	// ---- 初始化延迟直方图 ----
+	p.metrics.QueueWait = NewHistogram(opts.LatencyBuckets)
+	p.metrics.RunDuration = NewHistogram(opts.LatencyBuckets)
+	p.metrics.Attempts = NewHistogram(DefaultAttemptBuckets)
+
 	// ---- 初始化限速器 ----
 	if opts.RateLimit > 0 {
-		// 将"每秒 N 个任务"换算为"每个任务之间的最小间隔"
-		// 例如 RateLimit=10 -> interval=100ms
-		interval := time.Duration(float64(time.Second) / opts.RateLimit)
-		ticker := time.NewTicker(interval)
-		p.rateTicker = ticker.C
-		p.rateStop = make(chan struct{})
-
-		// 启动一个专门负责停止 ticker 的 goroutine，避免资源泄漏
-		go func() {
-			<-p.rateStop  // 等待关闭信号
-			ticker.Stop() // 停止 ticker，释放定时器资源
-		}()
+		p.rateLimiter = NewTokenBucket(opts.RateLimit, opts.RateBurst)
+		p.metrics.CurrentRate.Store(math.Float64bits(opts.RateLimit))
 	}
+	p.keys = newKeyLimiter[T](opts)
+	p.breakers = p.newBreakerGroup()
 
 	// ---- 启动初始 Worker goroutine ----
+	p.minWorkers.Store(int64(opts.Workers))
+	p.metrics.Workers.Store(int64(opts.Workers))
 	for i := 0; i < opts.Workers; i++ {
 		p.startWorker()
 	}
-	p.metrics.Workers.Store(int64(opts.Workers))
 
-	// ---- 启动动态扩容监控 goroutine（仅在允许扩容时）----
-	if opts.MaxWorkers > opts.Workers {
+	// ---- 启动自适应控制或动态扩容监控 goroutine（二者互斥）----
+	if opts.Adaptive != nil {
+		p.adaptive = &adaptiveController{}
+		p.metrics.ConcurrencyLimit.Store(int64(opts.Workers))
+		go p.adaptiveLoop()
+	} else if opts.MaxWorkers > opts.Workers {
 		go p.scaler()
 	}
 
@@ -339,16 +568,16 @@ func NewPool[T any](opts Options) *WorkerPool[T] {
 // 公开 API
 // =============================================================================
 
-// Submit 将单个任务提交到队列（fire-and-forget 模式）。
+// Submit 将单个任务提交到队列，返回该任务的 Future 句柄。
 //
-// 任务结果不会返回给调用方，适用于只关心副作用、不需要收集结果的场景。
-// 若需要获取执行结果，请使用 SubmitWithResult 或 SubmitAndCollect。
+// 通过 Future 可以等待结果（Wait）、查询状态（Status）或单独取消任务（Cancel）；
+// 不关心结果时可直接忽略返回的 Future（fire-and-forget 模式）。
 //
 // 返回值：
-//   - nil：任务成功入队
-//   - error：Pool 已停止，或队列已满
-func (p *WorkerPool[T]) Submit(task Task[T]) error {
-	return p.SubmitWithResult(task, nil)
+//   - *Future[T], nil：任务成功入队
+//   - nil, error：Pool 已停止，或队列已满
+func (p *WorkerPool[T]) Submit(task Task[T]) (*Future[T], error) {
+	return p.submit(task, nil)
 }
 
 // SubmitWithResult 将任务提交到队列，并在任务完成后将 Result 发送到 resultCh。
@@ -363,32 +592,50 @@ func (p *WorkerPool[T]) Submit(task Task[T]) error {
 //	pool.SubmitWithResult(task2, ch)
 //	r1, r2 := <-ch, <-ch
 func (p *WorkerPool[T]) SubmitWithResult(task Task[T], resultCh chan<- Result[T]) error {
+	_, err := p.submit(task, resultCh)
+	return err
+}
+
+// submit 是所有提交方式的公共实现：创建 Future 并非阻塞地入队。
+func (p *WorkerPool[T]) submit(task Task[T], resultCh chan<- Result[T]) (*Future[T], error) {
 	// 检查 Pool 是否已停止（加锁保证原子性）
+	// 入队全程持有锁：入队是非阻塞的，持锁可避免与 StopGraceful 的 close(queue) 竞争
 	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.stopped {
-		p.mu.Unlock()
-		return errors.New("workerpool: pool is stopped")
+		return nil, errors.New("workerpool: pool is stopped")
 	}
-	p.mu.Unlock()
 
-	j := job[T]{task: task, resultCh: resultCh}
+	fut := newFuture[T](p.ctx, task.TaskID())
+	j := job[T]{task: task, resultCh: resultCh, fut: fut, enqueuedAt: time.Now()}
+
+	// 先登记再入队：Worker 可能在入队后立即完成任务并注销
+	p.trackFuture(fut)
+
+	// 先计数再入队：Worker 可能在入队后立即完成任务并减少计数
+	p.active.add(1)
 
 	// 非阻塞方式入队：队列满时立即返回错误，不阻塞调用方
 	select {
 	case p.queue <- j:
 		p.metrics.Submitted.Add(1)  // 计数：已提交总数 +1
 		p.metrics.QueueDepth.Add(1) // 计数：当前队列深度 +1
+		p.emit(Event{Type: EventQueued, TaskID: j.task.TaskID()})
 
 		// 向扩容 goroutine 发送信号（非阻塞：channel 满时直接跳过，避免阻塞提交路径）
 		select {
 		case p.scaleSignal <- struct{}{}:
 		default:
 		}
-		return nil
+		return fut, nil
 
 	default:
-		// 队列已满，返回描述性错误（包含容量信息，便于调用方决策）
-		return fmt.Errorf("workerpool: queue full (capacity %d)", cap(p.queue))
+		// 队列已满，撤销计数和登记并释放任务 context
+		p.active.add(-1)
+		p.untrackFuture(fut)
+		fut.cancel(nil)
+		// 返回描述性错误（包含容量信息，便于调用方决策）
+		return nil, fmt.Errorf("workerpool: queue full (capacity %d)", cap(p.queue))
 	}
 }
 
@@ -398,7 +645,7 @@ func (p *WorkerPool[T]) SubmitWithResult(task Task[T], resultCh chan<- Result[T]
 // 已成功提交的任务不会被撤回。
 func (p *WorkerPool[T]) SubmitMany(tasks []Task[T]) error {
 	for _, t := range tasks {
-		if err := p.Submit(t); err != nil {
+		if _, err := p.Submit(t); err != nil {
 			return err
 		}
 	}
@@ -409,31 +656,24 @@ func (p *WorkerPool[T]) SubmitMany(tasks []Task[T]) error {
 //
 // 结果切片的顺序与任务完成的顺序一致（非提交顺序），调用方应通过 Result.TaskID 区分任务。
 // 此方法会阻塞直到所有任务均执行完毕（包括重试），适合需要汇总所有结果再处理的场景。
+// 如需按提交顺序返回、边执行边处理或遇错提前终止，请使用 Stream / Collect（见 workPoolStream.go）。
 //
 // 注意：若部分任务永久失败，它们的 Result.Err 不为 nil，但仍会被收集进结果集，
-// 不会导致此方法提前返回或 panic。
+// 不会导致此方法提前返回或 panic。提交失败（队列满或 Pool 已停止）的任务同样会出现在结果中，
+// 其 Result.Err 为 *SubmitError。
 func (p *WorkerPool[T]) SubmitAndCollect(tasks []Task[T]) []Result[T] {
-	// 创建一个足够大的缓冲 channel，确保 Worker 发送结果时不会阻塞
-	ch := make(chan Result[T], len(tasks))
-
-	// 将所有任务与结果 channel 绑定后提交
-	for _, t := range tasks {
-		_ = p.SubmitWithResult(t, ch) // 忽略错误：Pool 停止时剩余任务跳过
-	}
-
-	// 按提交任务数收集等量结果（阻塞直到全部完成）
-	results := make([]Result[T], 0, len(tasks))
-	for range tasks {
-		results = append(results, <-ch)
-	}
+	results, _ := p.Collect(context.Background(), tasks, StreamOptions{})
 	return results
 }
 
 // Scale 在运行时动态调整 Worker 数量。
 //
-// 参数 n 必须满足：1 <= n <= MaxWorkers。
+// 参数 n 必须满足：1 <= n <= MaxWorkers，n 同时成为新的 Worker 下限（空闲回收不会低于 n）。
 // 扩容（n > 当前 Worker 数）：立即启动新 Worker goroutine，效果即时生效。
-// 缩容（n < 当前 Worker 数）：当前 Worker 会自然退出，此方法仅更新计数，不强制终止。
+// 缩容（n < 当前 Worker 数）：通知多余的 Worker 退出。空闲的 Worker 立即退出，
+// 正在执行任务的 Worker 会在当前任务完成后退出，不会中断执行中的任务。
+// 缩容后若队列持续积压，自动扩容仍可能把 Worker 数增加到 MaxWorkers。
+// 自适应模式下 n 会成为新的并发上限，之后仍由控制器继续调整。
 //
 // 适用场景：
 //   - 业务低峰期手动缩容节省资源
@@ -453,14 +693,34 @@ func (p *WorkerPool[T]) Scale(n int) error {
 		return fmt.Errorf("workerpool: %d exceeds MaxWorkers (%d)", n, p.opts.MaxWorkers)
 	}
 
+	p.resize(n)
+	return nil
+}
+
+// resize 把 Worker 数量调整为 n，并把 n 设为新的下限。调用方必须持有 p.mu 且已校验 n 的范围。
+func (p *WorkerPool[T]) resize(n int) {
+	p.minWorkers.Store(int64(n))
+	if p.adaptive != nil {
+		p.metrics.ConcurrencyLimit.Store(int64(n))
+	}
 	current := int(p.metrics.Workers.Load())
-	// 仅处理扩容：新目标数量 > 当前数量时，补充差额个 Worker
+
+	// 扩容：补充差额个 Worker
 	for i := current; i < n; i++ {
+		p.metrics.Workers.Add(1)
 		p.startWorker()
 	}
-	// 更新 Worker 计数（缩容时 Worker goroutine 会在下次空闲时自然退出）
-	p.metrics.Workers.Store(int64(n))
-	return nil
+
+	// 缩容：投递退出令牌，Worker 在 tryRetire 中以 CAS 方式扣减计数，保证不会低于 n
+	for i := n; i < current; i++ {
+		select {
+		case p.retire <- struct{}{}:
+		default:
+		}
+	}
+	if n > current {
+		p.emit(Event{Type: EventScaled, Workers: n})
+	}
 }
 
 // Metrics 返回当前 Pool 的实时运行指标快照。
@@ -480,25 +740,19 @@ func (p *WorkerPool[T]) Metrics() MetricsSnapshot {
 //
 // 行为：
 //   - 正在 Run() 中的任务会收到 ctx.Done() 信号（若任务正确监听 ctx）
-//   - 队列中尚未被取走的任务将被丢弃（不再执行）
+//   - 队列中尚未被取走的任务将被丢弃（不再执行），其 Future 以取消错误结束
+//   - Worker 在排空队列后退出，不会残留 goroutine
 //   - 不等待任务退出，立即返回
 //
 // 适用于需要快速退出的场景（如进程收到 SIGKILL）。
 // 若希望等待任务完成后再退出，请使用 StopGraceful()。
+// 可在 StopGraceful 进行中调用，用于放弃等待、强制中断剩余任务；重复调用无副作用。
 func (p *WorkerPool[T]) Stop() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	if p.stopped {
-		return // 防止重复调用 cancel() 和 close(rateStop)
-	}
-	p.stopped = true
 	p.cancel() // 取消 Pool 级别 context，所有 Worker 和任务均会感知
 
-	// 停止限速 ticker，释放定时器资源
-	if p.rateStop != nil {
-		close(p.rateStop)
-	}
+	// 关闭队列：Worker 会以取消错误快速结束剩余任务（完成其 Future）后退出，避免 goroutine 泄漏
+	p.closeQueue()
+	p.emitStopped()
 }
 
 // StopGraceful 优雅停止 Pool：
@@ -507,28 +761,34 @@ func (p *WorkerPool[T]) Stop() {
 //  3. 等待所有 Worker goroutine 正常退出后才返回
 //
 // 适用于进程收到 SIGTERM 时，希望"做完手头的活再退出"的场景。
-// 注意：若某个任务一直阻塞且不监听 ctx，StopGraceful 也会一直等待。
+// 注意：若某个任务一直阻塞且不监听 ctx，StopGraceful 也会一直等待；
+// 需要限定等待时间时请使用 StopGracefulContext。
+// Pool 处于暂停状态时会自动恢复，以便排空队列。
 //
 // 推荐配合 defer 使用：
 //
 //	pool := workerpool.New[string](opts)
 //	defer pool.StopGraceful()
 func (p *WorkerPool[T]) StopGraceful() {
+	_ = p.StopGracefulContext(context.Background())
+}
+
+// closeQueue 禁止新的提交并关闭任务队列，恢复暂停的 Worker 以便排空队列，
+// 并取消所有尚未触发的定时任务。重复调用无副作用。
+func (p *WorkerPool[T]) closeQueue() {
 	p.mu.Lock()
 	p.stopped = true // 禁止新的 Submit 调用
+	// 关闭 queue channel：Worker 的主循环会在队列排空后自动退出
+	if !p.queueClosed {
+		p.queueClosed = true
+		close(p.queue)
+	}
 	p.mu.Unlock()
 
-	// 关闭 queue channel：Worker 的 for range 循环会在队列排空后自动退出
-	close(p.queue)
-
-	// 阻塞等待所有 Worker goroutine 完成（包括正在执行的任务）
-	p.workerWg.Wait()
+	p.Resume()
 
-	// Worker 全部退出后，清理资源
-	p.cancel()
-	if p.rateStop != nil {
-		close(p.rateStop)
-	}
+	// 取消所有尚未触发的定时任务（需在释放 p.mu 后调用，定时器回调的加锁顺序为 schedMu -> mu）
+	p.cancelAllScheduled()
 }
 
 // =============================================================================
@@ -536,86 +796,182 @@ func (p *WorkerPool[T]) StopGraceful() {
 // =============================================================================
 
 // startWorker 启动一个新的 Worker goroutine，并将其注册到 WaitGroup。
-// 必须在持有 p.mu 锁或初始化阶段调用（New 和 Scale 中调用）。
+// 必须在持有 p.mu 锁或初始化阶段调用（New、Scale 和 scaler 中调用）。
+// 调用方负责同步增加 metrics.Workers 计数。
 func (p *WorkerPool[T]) startWorker() {
 	p.workerWg.Add(1)
 	go p.workerLoop()
 }
 
+// tryRetire 尝试让当前 Worker 退出：仅当 Worker 数高于下限时以 CAS 扣减计数并返回 true。
+func (p *WorkerPool[T]) tryRetire() bool {
+	for {
+		n := p.metrics.Workers.Load()
+		if n <= p.minWorkers.Load() {
+			return false
+		}
+		if p.metrics.Workers.CompareAndSwap(n, n-1) {
+			p.emit(Event{Type: EventScaled, Workers: int(n - 1)})
+			return true
+		}
+	}
+}
+
 // workerLoop 是每个 Worker goroutine 运行的主循环。
 //
 // 工作流程：
-//  1. 从 queue channel 取任务（queue 关闭且排空后 range 自动退出）
-//  2. 若启用了限速，等待令牌可用（或 ctx 取消）
-//  3. 调用 executeWithRetry 执行任务（含重试逻辑）
-//  4. 更新指标，触发回调，将结果写入 resultCh（如有）
+//  1. 从 queue channel 取任务（queue 关闭且排空后退出），暂停期间不取任务
+//  2. 收到缩容令牌或空闲超时时，若 Worker 数高于下限则退出
+//  3. 取到任务后交给 dispatch 执行（按 key 限流后调用 runJob）
 func (p *WorkerPool[T]) workerLoop() {
 	defer p.workerWg.Done() // goroutine 退出时通知 WaitGroup
 
-	// range 语法：当 queue channel 被关闭且所有元素均被消费后，循环自动结束
-	for j := range p.queue {
-
-		// ---- 限速等待：获取执行令牌 ----
-		if p.rateTicker != nil {
-			select {
-			case <-p.rateTicker:
-				// 成功获取令牌，继续执行
+	// 空闲计时器：每执行完一个任务重置一次；未启用空闲回收时 idleC 为 nil，永不触发
+	var idle *time.Timer
+	var idleC <-chan time.Time
+	if p.opts.IdleTimeout > 0 {
+		idle = time.NewTimer(p.opts.IdleTimeout)
+		defer idle.Stop()
+		idleC = idle.C
+	}
+	resetIdle := func() {
+		if idle != nil {
+			idle.Reset(p.opts.IdleTimeout)
+		}
+	}
 
-			case <-p.ctx.Done():
-				// Pool 被强制停止，放弃执行此任务（但仍需修正队列深度计数）
-				p.metrics.QueueDepth.Add(-1)
-				continue
-			}
+	for {
+		// 暂停时 queueC 为 nil，不再取任务，只等待恢复、缩容或空闲超时
+		pausing, resuming := p.pause.chans()
+		queueC := p.queue
+		if resuming != nil {
+			queueC = nil
 		}
 
-		// ---- 执行任务（含超时和重试） ----
-		p.metrics.QueueDepth.Add(-1) // 任务已离队，队列深度 -1
-		p.metrics.InFlight.Add(1)    // 标记任务进入执行状态
+		select {
+		case j, ok := <-queueC:
+			if !ok {
+				return
+			}
+			p.dispatch(j)
+			resetIdle()
 
-		result := p.executeWithRetry(j.task)
+		case <-pausing:
+			// 进入暂停，重新获取状态
 
-		p.metrics.InFlight.Add(-1) // 任务执行完毕（无论成功或失败）
+		case <-resuming:
+			resetIdle() // 暂停期间的空闲不计入
 
-		// ---- 更新指标 & 触发回调 ----
-		if result.Err == nil {
-			p.metrics.Succeeded.Add(1)
-			if p.opts.OnSuccess != nil {
-				// 在独立 goroutine 中调用，避免阻塞 Worker
-				go p.opts.OnSuccess(result.TaskID, result.Duration)
+		case <-p.retire:
+			if p.tryRetire() {
+				p.opts.Logger("[workerpool] 缩容：Worker 退出，当前 Worker 数 = %d", p.metrics.Workers.Load())
+				return
 			}
-		} else {
-			p.metrics.Failed.Add(1)
-			if p.opts.OnFailure != nil {
-				go p.opts.OnFailure(result.TaskID, result.Err, result.Attempts)
+
+		case <-idleC:
+			if p.tryRetire() {
+				p.opts.Logger("[workerpool] 自动缩容：Worker 空闲超过 %s 退出，当前 Worker 数 = %d",
+					p.opts.IdleTimeout, p.metrics.Workers.Load())
+				return
 			}
+			resetIdle()
 		}
+	}
+}
 
-		// ---- 将结果发送给调用方（如有需要）----
-		// 若调用方传入了 resultCh（通过 SubmitWithResult/SubmitAndCollect），则发送结果
-		if j.resultCh != nil {
-			j.resultCh <- result
+// runJob 执行单个任务的完整流程。
+//
+// key 为任务的限流 key（未启用按 key 限流时为空），调用方已为其占用并发名额。
+//
+// 工作流程：
+//  1. 若启用了按 key 限速或全局限速，等待令牌可用（或任务被取消）
+//  2. 调用 executeWithRetry 执行任务（含重试逻辑）
+//  3. 更新指标，触发回调，完成 Future，将结果写入 resultCh（如有）
+func (p *WorkerPool[T]) runJob(j job[T], key string) {
+	// ---- 限速等待：获取执行令牌（任务被取消时提前返回，下方按已取消处理）----
+	p.waitRate(j.fut.ctx, key)
+
+	p.metrics.QueueDepth.Add(-1) // 任务已离队，队列深度 -1
+	wait := time.Since(j.enqueuedAt)
+	p.metrics.QueueWait.Observe(wait.Seconds())
+
+	var result Result[T]
+	if j.fut.ctx.Err() != nil {
+		// ---- 排队期间已被取消（或 Pool 已 Stop），不再执行 ----
+		result = Result[T]{TaskID: j.task.TaskID(), Err: context.Cause(j.fut.ctx)}
+	} else {
+		// ---- 执行任务（含超时和重试） ----
+		p.emit(Event{Type: EventStarted, TaskID: j.task.TaskID(), Attempt: 1, QueueWait: wait})
+		p.metrics.InFlight.Add(1) // 标记任务进入执行状态
+		result = p.executeWithRetry(j.fut.ctx, j.task, j.fut)
+		p.metrics.InFlight.Add(-1) // 任务执行完毕（无论成功或失败）
+		p.metrics.RunDuration.Observe(result.Duration.Seconds())
+		p.metrics.Attempts.Observe(float64(result.Attempts))
+	}
+
+	// ---- 更新指标 & 触发回调 ----
+	final := Event{TaskID: result.TaskID, Attempt: result.Attempts, Duration: result.Duration, Err: result.Err}
+	if errors.Is(result.Err, ErrTaskCancelled) {
+		// 主动取消不算失败，不触发 OnFailure
+		p.metrics.Cancelled.Add(1)
+		final.Type = EventCancelled
+	} else if result.Err == nil {
+		p.metrics.Succeeded.Add(1)
+		final.Type = EventSucceeded
+		if p.opts.OnSuccess != nil {
+			// 在独立 goroutine 中调用，避免阻塞 Worker
+			go p.opts.OnSuccess(result.TaskID, result.Duration)
+		}
+	} else {
+		p.metrics.Failed.Add(1)
+		final.Type = EventFailed
+		if p.opts.OnFailure != nil {
+			go p.opts.OnFailure(result.TaskID, result.Err, result.Attempts)
 		}
 	}
+	p.emit(final)
+
+	// ---- 完成 Future，并将结果发送给调用方（如有需要）----
+	p.untrackFuture(j.fut)
+	j.fut.complete(result)
+
+	// 若调用方传入了 resultCh（通过 SubmitWithResult/SubmitAndCollect），则发送结果
+	if j.resultCh != nil {
+		j.resultCh <- result
+	}
+	// 结果送达后才算结束，Drain 返回时调用方已能收到全部结果
+	p.active.add(-1)
 }
 
 // executeWithRetry 在指数退避策略下执行任务，直到成功或耗尽重试次数。
 //
+// ctx 是任务专属的 context（Pool ctx 的子 context），fut 用于上报运行状态。
+//
 // 重试策略：
 //   - 每次失败后等待 delay 时间，delay 每次翻倍（指数退避）
-//   - 若在退避等待期间 Pool 被取消，立即返回取消错误
+//   - 若在退避等待期间 Pool 被停止或任务被取消，立即返回取消错误
+//   - 任务被取消后不再重试
 //   - 所有尝试均失败则返回最后一次的错误
-func (p *WorkerPool[T]) executeWithRetry(task Task[T]) Result[T] {
+func (p *WorkerPool[T]) executeWithRetry(ctx context.Context, task Task[T], fut *Future[T]) Result[T] {
 	result := Result[T]{TaskID: task.TaskID()}
 	delay := p.opts.RetryDelay           // 首次重试等待时间（后续翻倍）
 	maxAttempts := p.opts.MaxRetries + 1 // 总尝试次数 = 重试次数 + 首次执行
+	breaker := p.breakerFor(task)        // 未启用熔断器时为 nil
+	defer p.releaseBreaker(breaker)
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		result.Attempts = attempt
+		fut.setStatus(TaskRunning)
 
 		// 记录本次执行耗时
 		start := time.Now()
-		val, err := p.runOnce(task) // 执行一次任务（含超时控制）
-		result.Duration += time.Since(start)
+		val, err := p.runWithBreaker(ctx, task, breaker) // 执行一次任务（含熔断与超时控制）
+		elapsed := time.Since(start)
+		result.Duration += elapsed
+		if ctx.Err() == nil {
+			// 被取消的执行不反映下游负载，不计入自适应样本
+			p.observeAdaptive(elapsed, err)
+		}
 
 		if err == nil {
 			// 执行成功，填充结果并立即返回
@@ -626,19 +982,38 @@ func (p *WorkerPool[T]) executeWithRetry(task Task[T]) Result[T] {
 
 		// 本次执行失败
 		result.Err = err
+		if errors.Is(err, ErrTaskTimeout) {
+			p.emit(Event{Type: EventTimedOut, TaskID: task.TaskID(), Attempt: attempt, Duration: elapsed, Err: err})
+		}
+
+		var pe *PanicError
+		if errors.As(err, &pe) || errors.Is(err, ErrBreakerOpen) {
+			// panic 和熔断拒绝都不重试：前者重试仍会 panic，后者重试只会放大下游压力
+			return result
+		}
+
+		if ctx.Err() != nil {
+			// 任务被取消或 Pool 被停止，不再重试
+			if cause := context.Cause(ctx); errors.Is(cause, ErrTaskCancelled) {
+				result.Err = cause
+			}
+			return result
+		}
 
 		if attempt < maxAttempts {
 			// 还有重试机会：记录日志，等待退避时间后重试
 			p.metrics.Retried.Add(1)
 			p.opts.Logger("[workerpool] 任务 %q 第 %d 次执行失败: %v - 将在 %s 后重试",
 				task.TaskID(), attempt, err, delay)
+			p.emit(Event{Type: EventRetrying, TaskID: task.TaskID(), Attempt: attempt, Duration: elapsed, Delay: delay, Err: err})
 
+			fut.setStatus(TaskRetrying)
 			select {
 			case <-time.After(delay):
 				delay *= 2 // 指数退避：每次等待时间翻倍
-			case <-p.ctx.Done():
-				// 退避等待期间 Pool 被取消，包装错误并提前返回
-				result.Err = fmt.Errorf("在重试退避期间被取消: %w", p.ctx.Err())
+			case <-ctx.Done():
+				// 退避等待期间 Pool 被停止或任务被取消，包装错误并提前返回
+				result.Err = fmt.Errorf("在重试退避期间被取消: %w", context.Cause(ctx))
 				return result
 			}
 		}
@@ -655,20 +1030,23 @@ func (p *WorkerPool[T]) executeWithRetry(task Task[T]) Result[T] {
 // 若 TaskTimeout > 0：
 //   - 创建带超时的子 context
 //   - 在独立 goroutine 中运行 task.Run()
-//   - 若超时先到，立即返回超时错误（task.Run 的 goroutine 继续运行直到感知 ctx 取消）
+//   - 若超时先到，立即返回超时错误（task.Run 的 goroutine 继续运行直到感知 ctx 取消，
+//     期间计入 Metrics.Abandoned）
+//
+// 两种情况下 Run() 中的 panic 都会被 safeRun 捕获并转换为 *PanicError。
 //
 // 若 TaskTimeout == 0：
-//   - 直接用 Pool 级别的 ctx 调用 task.Run()，不附加额外超时
-func (p *WorkerPool[T]) runOnce(task Task[T]) (T, error) {
+//   - 直接用任务专属的 ctx 调用 task.Run()，不附加额外超时
+func (p *WorkerPool[T]) runOnce(parent context.Context, task Task[T]) (T, error) {
 	var zero T // 错误时的零值返回
 
 	if p.opts.TaskTimeout <= 0 {
-		// 未设置超时：直接执行，任务可运行到 Pool 被 Stop() 为止
-		return task.Run(p.ctx)
+		// 未设置超时：直接执行，任务可运行到被取消或 Pool 被 Stop() 为止
+		return p.safeRun(parent, task)
 	}
 
 	// 创建带超时的子 context（超时后自动取消，defer cancel 确保资源释放）
-	ctx, cancel := context.WithTimeout(p.ctx, p.opts.TaskTimeout)
+	ctx, cancel := context.WithTimeout(parent, p.opts.TaskTimeout)
 	defer cancel()
 
 	// 用匿名结构体承载 goroutine 的执行结果
@@ -678,10 +1056,18 @@ func (p *WorkerPool[T]) runOnce(task Task[T]) (T, error) {
 	}
 	ch := make(chan outcome, 1) // 缓冲为 1，避免 goroutine 泄漏
 
+	// state 记录执行 goroutine 与超时分支谁先结束：0 执行中，1 已返回，2 已被放弃
+	var state atomic.Int32
+
 	// 在独立 goroutine 中执行任务，确保 select 能同时等待结果和超时
 	go func() {
-		v, e := task.Run(ctx)
+		v, e := p.safeRun(ctx, task)
 		ch <- outcome{v, e}
+		if !state.CompareAndSwap(0, 1) {
+			// 超时分支已放弃此次执行，Run() 此时才返回
+			p.metrics.Abandoned.Add(-1)
+			p.opts.Logger("[workerpool] 任务 %q 在超时后才返回（结果已被丢弃）", task.TaskID())
+		}
 	}()
 
 	select {
@@ -690,13 +1076,37 @@ func (p *WorkerPool[T]) runOnce(task Task[T]) (T, error) {
 		return o.val, o.err
 
 	case <-ctx.Done():
-		// 超时（或 Pool 被 Stop()）先触发，返回超时错误
+		// 超时、任务被取消或 Pool 被 Stop() 先触发
 		// 注意：执行任务的 goroutine 仍在运行，但其持有的 ctx 已取消，
 		// 若任务正确实现了 ctx 监听，它会很快退出。
-		return zero, fmt.Errorf("任务执行超时（限制 %s）", p.opts.TaskTimeout)
+		p.metrics.Abandoned.Add(1) // 先计数再标记，避免 goroutine 先扣减出现负数
+		if !state.CompareAndSwap(0, 2) {
+			// Run() 恰好与超时同时返回，仍以实际结果为准
+			p.metrics.Abandoned.Add(-1)
+			o := <-ch
+			return o.val, o.err
+		}
+		if parent.Err() != nil {
+			return zero, context.Cause(parent)
+		}
+		return zero, fmt.Errorf("%w（限制 %s）", ErrTaskTimeout, p.opts.TaskTimeout)
 	}
 }
 
+// safeRun 调用 task.Run()，并把其中的 panic 转换为 *PanicError，保证 Worker 不会崩溃。
+func (p *WorkerPool[T]) safeRun(ctx context.Context, task Task[T]) (val T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pe := &PanicError{TaskID: task.TaskID(), Value: r, Stack: debug.Stack()}
+			var zero T
+			val, err = zero, pe
+			p.metrics.Panicked.Add(1)
+			p.opts.Logger("[workerpool] 任务 %q 发生 panic: %v\n%s", pe.TaskID, r, pe.Stack)
+		}
+	}()
+	return task.Run(ctx)
+}
+
 // scaler 是动态扩容的监控 goroutine，在 New() 中启动（仅当 MaxWorkers > Workers 时）。
 //
 // 扩容触发条件（每次收到 scaleSignal 时评估）：
@@ -717,14 +1127,14 @@ func (p *WorkerPool[T]) scaler() {
 			queueDepth := p.metrics.QueueDepth.Load()
 			currentWorkers := p.metrics.Workers.Load()
 
-			// 仅当队列积压超过当前 Worker 数且未达上限时才扩容
-			if queueDepth > currentWorkers && currentWorkers < int64(p.opts.MaxWorkers) {
+			// 仅当队列积压超过当前 Worker 数且未达上限时才扩容（暂停期间积压不代表 Worker 不足）
+			if !p.pause.isPaused() && queueDepth > currentWorkers && currentWorkers < int64(p.opts.MaxWorkers) {
 				p.mu.Lock()
 				if !p.stopped { // 再次确认 Pool 未停止（双重检查）
 					p.startWorker()
-					p.metrics.Workers.Add(1)
-					p.opts.Logger("[workerpool] 自动扩容：当前 Worker 数 = %d（队列积压 = %d）",
-						p.metrics.Workers.Load(), queueDepth)
+					n := p.metrics.Workers.Add(1)
+					p.opts.Logger("[workerpool] 自动扩容：当前 Worker 数 = %d（队列积压 = %d）", n, queueDepth)
+					p.emit(Event{Type: EventScaled, Workers: int(n)})
 				}
 				p.mu.Unlock()
 			}
@@ -0,0 +1,225 @@
+package tools
+
+// 自适应并发控制：根据观测到的执行耗时和错误率自动调整并发上限与启动速率。
+//
+// 采用 AIMD（加性增、乘性减）策略：
+//   - 每个调整周期统计一次单次执行耗时的平均值和错误率
+//   - 过载（错误率超过阈值，或耗时超过目标 / 明显高于历史基线）时，并发上限乘以 Decrease
+//   - 未过载且处于饱和状态（有任务排队或执行中任务已达上限）时，并发上限加 Increase
+//   - 并发上限始终介于 Options.Workers 和 Options.MaxWorkers 之间
+//
+// 若同时配置了 RateLimit，启动速率按同样的方向调整，介于 MinRate 和 RateLimit 之间。
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// AdaptiveOptions 配置自适应并发控制，作为 Options.Adaptive 使用。
+// 所有字段均有默认值，零值即可使用：Options{Adaptive: &AdaptiveOptions{}}。
+type AdaptiveOptions struct {
+	// Interval 是调整周期，每个周期最多调整一次。
+	// 默认值：1s。
+	Interval time.Duration
+
+	// MinSamples 是一个周期内至少需要完成的执行次数，样本不足时不做调整。
+	// 默认值：5。
+	MinSamples int
+
+	// LatencyTarget 是单次执行的目标平均耗时，超过即视为过载。
+	// 设为 0 时使用梯度模式：与历史最低平均耗时（基线）比较，超过基线 Tolerance 倍即视为过载。
+	LatencyTarget time.Duration
+
+	// Tolerance 是梯度模式下允许的耗时放大倍数。
+	// 默认值：2.0。
+	Tolerance float64
+
+	// ErrorThreshold 是过载判定的错误率阈值（0~1），超时也计为错误。
+	// 默认值：0.1。
+	ErrorThreshold float64
+
+	// Increase 是每次加性增加的并发数。
+	// 默认值：1。
+	Increase int
+
+	// Decrease 是过载时并发上限和速率的乘性缩减系数，取值 (0, 1)。
+	// 默认值：0.7。
+	Decrease float64
+
+	// MinRate 是速率下调的下限（每秒任务数），仅在 Options.RateLimit > 0 时生效。
+	// 默认值：RateLimit / 10。
+	MinRate float64
+}
+
+// setDefaults 为未设置的字段填充默认值，rateLimit 为 Options.RateLimit。
+func (a *AdaptiveOptions) setDefaults(rateLimit float64) {
+	if a.Interval <= 0 {
+		a.Interval = time.Second
+	}
+	if a.MinSamples <= 0 {
+		a.MinSamples = 5
+	}
+	if a.Tolerance <= 1 {
+		a.Tolerance = 2.0
+	}
+	if a.ErrorThreshold <= 0 {
+		a.ErrorThreshold = 0.1
+	}
+	if a.Increase <= 0 {
+		a.Increase = 1
+	}
+	if a.Decrease <= 0 || a.Decrease >= 1 {
+		a.Decrease = 0.7
+	}
+	if a.MinRate <= 0 || a.MinRate > rateLimit {
+		a.MinRate = rateLimit / 10
+	}
+}
+
+// adaptiveWindow 是一个调整周期内的统计数据。
+type adaptiveWindow struct {
+	samples   int           // 完成的执行次数（不含被主动取消的）
+	errors    int           // 其中失败的次数
+	latency   time.Duration // 执行耗时之和
+	saturated bool          // 周期内是否出现过执行中任务数达到并发上限
+}
+
+// adaptiveController 收集 Worker 上报的执行样本，由 adaptiveLoop 周期性取出并决策。
+type adaptiveController struct {
+	mu     sync.Mutex
+	window adaptiveWindow
+
+	// baseline 是梯度模式下的历史最低平均耗时，只在 adaptiveLoop 中读写，无需加锁。
+	baseline time.Duration
+}
+
+// observe 记录一次执行的耗时和结果。
+func (c *adaptiveController) observe(d time.Duration, failed, saturated bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.window.samples++
+	c.window.latency += d
+	if failed {
+		c.window.errors++
+	}
+	if saturated {
+		c.window.saturated = true
+	}
+}
+
+// take 取出当前周期的统计数据并重新开始计数。
+func (c *adaptiveController) take() adaptiveWindow {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := c.window
+	c.window = adaptiveWindow{}
+	return w
+}
+
+// =============================================================================
+// WorkerPool 集成
+// =============================================================================
+
+// observeAdaptive 在每次执行（含重试的每一轮）结束后上报样本，未启用自适应模式时不做任何事。
+func (p *WorkerPool[T]) observeAdaptive(d time.Duration, err error) {
+	if p.adaptive == nil {
+		return
+	}
+	// InFlight 此时仍包含当前任务，达到上限说明并发已被用满
+	saturated := p.metrics.InFlight.Load() >= p.metrics.ConcurrencyLimit.Load()
+	p.adaptive.observe(d, err != nil, saturated)
+}
+
+// adaptiveLoop 是自适应控制的决策 goroutine，在 NewPool 中启动（仅当 Options.Adaptive != nil 时）。
+func (p *WorkerPool[T]) adaptiveLoop() {
+	ticker := time.NewTicker(p.opts.Adaptive.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.adjustAdaptive()
+		}
+	}
+}
+
+// adjustAdaptive 根据上一个周期的统计数据执行一次 AIMD 调整。
+func (p *WorkerPool[T]) adjustAdaptive() {
+	a := p.opts.Adaptive
+	w := p.adaptive.take()
+	if w.samples < a.MinSamples {
+		return
+	}
+
+	avg := w.latency / time.Duration(w.samples)
+	errRate := float64(w.errors) / float64(w.samples)
+	saturated := w.saturated || p.metrics.QueueDepth.Load() > 0
+
+	// ---- 过载判定 ----
+	overloaded := errRate > a.ErrorThreshold
+	if a.LatencyTarget > 0 {
+		overloaded = overloaded || avg > a.LatencyTarget
+	} else {
+		base := p.adaptive.baseline
+		overloaded = overloaded || (base > 0 && float64(avg) > float64(base)*a.Tolerance)
+
+		// 基线取历史最低值，并缓慢上浮，避免下游永久变慢后一直判定为过载
+		if base == 0 || avg < base {
+			p.adaptive.baseline = avg
+		} else {
+			p.adaptive.baseline = base + (avg-base)/100
+		}
+	}
+
+	// ---- 计算新的并发上限 ----
+	limit := int(p.metrics.ConcurrencyLimit.Load())
+	next := limit
+	switch {
+	case overloaded:
+		next = int(float64(limit) * a.Decrease)
+		if next >= limit {
+			next = limit - 1
+		}
+		next = max(next, p.opts.Workers)
+	case saturated:
+		next = min(limit+a.Increase, p.opts.MaxWorkers)
+	}
+
+	// ---- 计算新的启动速率 ----
+	rate := math.Float64frombits(p.metrics.CurrentRate.Load())
+	nextRate := rate
+	if p.opts.RateLimit > 0 {
+		switch {
+		case overloaded:
+			nextRate = max(rate*a.Decrease, a.MinRate)
+		case saturated:
+			nextRate = min(rate+(p.opts.RateLimit-a.MinRate)/10, p.opts.RateLimit)
+		}
+	}
+
+	if next == limit && nextRate == rate {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	if next != limit {
+		p.resize(next)
+		p.opts.Logger("[workerpool] 自适应调整：并发上限 %d -> %d（平均耗时 %s，错误率 %.1f%%）",
+			limit, next, avg, errRate*100)
+	}
+	if nextRate != rate {
+		p.metrics.CurrentRate.Store(math.Float64bits(nextRate))
+		p.rateLimiter.SetRate(nextRate)
+		p.opts.Logger("[workerpool] 自适应调整：速率 %.2f/s -> %.2f/s（平均耗时 %s，错误率 %.1f%%）",
+			rate, nextRate, avg, errRate*100)
+	}
+}
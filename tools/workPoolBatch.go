@@ -0,0 +1,294 @@
+package tools
+
+// BatchPool：把逐条提交的数据攒成批次执行，适合数据库批量插入、批量 API 等按批计费的下游。
+//
+// 核心特性：
+//   - 双触发：攒够 MaxBatchSize 条或距离批次第一条数据超过 MaxWait 即发车
+//   - 复用 WorkerPool：批次作为任务在内部 Pool 中执行，超时、重试、限速、熔断、指标均按批次生效
+//   - 逐条回传：批次结果按下标拆分，分别发送到每条数据提交时指定的 resultCh
+//   - 部分失败：RunBatch 返回 BatchErrors 时只让对应数据失败，其余数据照常成功（且不会重试整批）
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchTask 是批量任务接口：一次处理一批数据，返回与 items 一一对应的结果。
+//
+// 返回的 []Out 长度必须等于 len(items)。
+// 若只有部分数据失败，返回 BatchErrors（下标与 items 对应，nil 表示该条成功）；
+// 返回其他错误表示整批失败，所有数据都会得到该错误，并按 Options.MaxRetries 重试整批。
+type BatchTask[In, Out any] interface {
+	RunBatch(ctx context.Context, items []In) ([]Out, error)
+}
+
+// BatchFunc 把普通函数适配为 BatchTask。
+type BatchFunc[In, Out any] func(ctx context.Context, items []In) ([]Out, error)
+
+// RunBatch 实现 BatchTask。
+func (f BatchFunc[In, Out]) RunBatch(ctx context.Context, items []In) ([]Out, error) {
+	return f(ctx, items)
+}
+
+// BatchErrors 是批次内逐条的错误，下标与 items 对应，nil 表示该条成功。
+type BatchErrors []error
+
+func (e BatchErrors) Error() string {
+	failed := 0
+	var first error
+	for _, err := range e {
+		if err != nil {
+			failed++
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return fmt.Sprintf("batch: %d of %d items failed, first error: %v", failed, len(e), first)
+}
+
+// BatchOptions 配置 BatchPool。
+type BatchOptions struct {
+	// Options 是执行批次的内部 WorkerPool 配置：
+	// Workers 为同时执行的批次数，TaskTimeout/MaxRetries/RateLimit 等均按批次生效，
+	// QueueSize 为等待攒批的数据条数上限。
+	Options
+
+	// MaxBatchSize 是单个批次的最大数据条数，攒满即发车。
+	// 默认值：100。
+	MaxBatchSize int
+
+	// MaxWait 是批次第一条数据到达后最多等待的时间，超时后不足 MaxBatchSize 也会发车。
+	// 默认值：100ms。
+	MaxWait time.Duration
+}
+
+// batchItem 是等待攒批的单条数据。
+type batchItem[In, Out any] struct {
+	taskID   string
+	value    In
+	resultCh chan<- Result[Out]
+}
+
+// batchOutcome 是批次在内部 Pool 中的执行结果。
+type batchOutcome[Out any] struct {
+	outs []Out
+	errs BatchErrors // 部分失败时非 nil
+}
+
+// BatchPool 把逐条提交的数据攒批后交给 BatchTask 执行。
+//
+// 使用示例：
+//
+//	insert := BatchFunc[Row, int64](func(ctx context.Context, rows []Row) ([]int64, error) {
+//	    return db.BulkInsert(ctx, rows) // 返回每行的自增 ID
+//	})
+//	bp := NewBatchPool[Row, int64](insert, BatchOptions{MaxBatchSize: 500, MaxWait: 200 * time.Millisecond})
+//	defer bp.StopGraceful()
+//	resCh := make(chan Result[int64], 1)
+//	_ = bp.Submit("row-1", row, resCh)
+//	r := <-resCh
+type BatchPool[In, Out any] struct {
+	opts BatchOptions
+	task BatchTask[In, Out]
+	pool *WorkerPool[batchOutcome[Out]]
+
+	input  chan batchItem[In, Out] // 等待攒批的数据
+	tokens chan struct{}           // 限制同时在内部 Pool 中的批次数，内部队列满时攒批 goroutine 阻塞
+
+	mu      sync.Mutex // 保护 stopped 与 close(input)
+	stopped bool
+
+	wg  sync.WaitGroup // 等待攒批 goroutine 和所有结果分发 goroutine
+	seq atomic.Int64   // 批次序号，用作批次 TaskID
+}
+
+// NewBatchPool 创建并启动一个 BatchPool。使用完毕后务必调用 Stop() 或 StopGraceful()。
+func NewBatchPool[In, Out any](task BatchTask[In, Out], opts BatchOptions) *BatchPool[In, Out] {
+	opts.Options.setDefaults()
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = 100
+	}
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = 100 * time.Millisecond
+	}
+
+	pool := NewPool[batchOutcome[Out]](opts.Options)
+	b := &BatchPool[In, Out]{
+		opts:   opts,
+		task:   task,
+		pool:   pool,
+		input:  make(chan batchItem[In, Out], opts.QueueSize),
+		tokens: make(chan struct{}, cap(pool.queue)),
+	}
+
+	b.wg.Add(1)
+	go b.loop()
+	return b
+}
+
+// Submit 提交一条数据，结果会发送到 resultCh（可为 nil，表示不关心结果）。
+//
+// 与 WorkerPool.Submit 一致，提交是非阻塞的：Pool 已停止或等待攒批的数据已满时立即返回错误。
+// resultCh 需要有足够的缓冲或被持续消费，否则会阻塞结果分发。
+func (b *BatchPool[In, Out]) Submit(taskID string, item In, resultCh chan<- Result[Out]) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return errors.New("workerpool: pool is stopped")
+	}
+
+	select {
+	case b.input <- batchItem[In, Out]{taskID: taskID, value: item, resultCh: resultCh}:
+		return nil
+	default:
+		return fmt.Errorf("workerpool: batch queue full (capacity %d)", cap(b.input))
+	}
+}
+
+// Name 返回 Pool 名称，与内部 WorkerPool 一致。
+func (b *BatchPool[In, Out]) Name() string { return b.pool.Name() }
+
+// Metrics 返回内部 WorkerPool 的指标快照，计数单位为批次而非单条数据。
+func (b *BatchPool[In, Out]) Metrics() MetricsSnapshot { return b.pool.Metrics() }
+
+// Stop 立即停止：不再接受提交，执行中的批次被取消，尚未执行的数据以错误结束。
+func (b *BatchPool[In, Out]) Stop() {
+	b.closeInput()
+	b.pool.Stop()
+}
+
+// StopGraceful 优雅停止：不再接受提交，把已提交的数据全部攒批执行完、结果全部分发后再返回。
+func (b *BatchPool[In, Out]) StopGraceful() {
+	b.closeInput()
+	b.wg.Wait()
+	b.pool.StopGraceful()
+}
+
+// closeInput 标记停止并关闭 input，攒批 goroutine 会把剩余数据作为最后一批发出后退出。
+func (b *BatchPool[In, Out]) closeInput() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.stopped {
+		b.stopped = true
+		close(b.input)
+	}
+}
+
+// loop 是攒批 goroutine：按条数或等待时间把数据切成批次交给 dispatch。
+func (b *BatchPool[In, Out]) loop() {
+	defer b.wg.Done()
+
+	var batch []batchItem[In, Out]
+	timer := time.NewTimer(b.opts.MaxWait)
+	timer.Stop()
+	defer timer.Stop()
+
+	flush := func() {
+		timer.Stop()
+		b.dispatch(batch)
+		batch = nil
+	}
+
+	for {
+		select {
+		case it, ok := <-b.input:
+			if !ok {
+				if len(batch) > 0 {
+					flush()
+				}
+				return
+			}
+			batch = append(batch, it)
+			if len(batch) == 1 {
+				timer.Reset(b.opts.MaxWait) // 以批次第一条数据为起点计时
+			}
+			if len(batch) >= b.opts.MaxBatchSize {
+				flush()
+			}
+
+		case <-timer.C:
+			if len(batch) > 0 {
+				flush()
+			}
+		}
+	}
+}
+
+// dispatch 把一个批次提交到内部 Pool，并启动 goroutine 等待结果后逐条分发。
+func (b *BatchPool[In, Out]) dispatch(batch []batchItem[In, Out]) {
+	b.tokens <- struct{}{} // 内部 Pool 已满时在此阻塞，形成背压
+
+	values := make([]In, len(batch))
+	for i, it := range batch {
+		values[i] = it.value
+	}
+	run := &batchRun[In, Out]{
+		id:    "batch-" + strconv.FormatInt(b.seq.Add(1), 10),
+		items: values,
+		task:  b.task,
+	}
+
+	fut, err := b.pool.Submit(run)
+	if err != nil {
+		<-b.tokens
+		b.fanOut(batch, Result[batchOutcome[Out]]{TaskID: run.id, Err: err})
+		return
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		r, _ := fut.Wait(context.Background())
+		<-b.tokens
+		b.fanOut(batch, r)
+	}()
+}
+
+// fanOut 把批次结果按下标拆分，发送到每条数据的 resultCh。
+func (b *BatchPool[In, Out]) fanOut(batch []batchItem[In, Out], r Result[batchOutcome[Out]]) {
+	err := r.Err
+	if err == nil && len(r.Value.outs) != len(batch) {
+		err = fmt.Errorf("batch: RunBatch returned %d results for %d items", len(r.Value.outs), len(batch))
+	}
+
+	for i, it := range batch {
+		if it.resultCh == nil {
+			continue
+		}
+		res := Result[Out]{TaskID: it.taskID, Attempts: r.Attempts, Duration: r.Duration}
+		switch {
+		case err != nil:
+			res.Err = err
+		case i < len(r.Value.errs) && r.Value.errs[i] != nil:
+			res.Err = r.Value.errs[i]
+		default:
+			res.Value = r.Value.outs[i]
+		}
+		it.resultCh <- res
+	}
+}
+
+// batchRun 把一个批次适配为内部 Pool 的 Task。
+type batchRun[In, Out any] struct {
+	id    string
+	items []In
+	task  BatchTask[In, Out]
+}
+
+func (t *batchRun[In, Out]) TaskID() string { return t.id }
+
+func (t *batchRun[In, Out]) Run(ctx context.Context) (batchOutcome[Out], error) {
+	outs, err := t.task.RunBatch(ctx, t.items)
+	var errs BatchErrors
+	if errors.As(err, &errs) {
+		// 部分失败：视为批次成功，逐条错误在 fanOut 中分发，不重试整批
+		return batchOutcome[Out]{outs: outs, errs: errs}, nil
+	}
+	return batchOutcome[Out]{outs: outs}, err
+}
@@ -0,0 +1,382 @@
+package tools
+
+// 熔断器：下游故障时快速失败，避免重试放大负载。
+//
+// 状态机：
+//   - Closed（闭合）：正常放行，连续失败达到 FailureThreshold 次后转为 Open
+//   - Open（断开）：直接拒绝，任务以 ErrBreakerOpen 快速失败；经过 CoolDown 后转为 HalfOpen
+//   - HalfOpen（半开）：最多放行 HalfOpenMax 个试探执行，连续成功 SuccessThreshold 次后转为 Closed，
+//     任意一次失败立即回到 Open 并重新计时
+//
+// 熔断器可挂在整个 Pool 上，也可按任务 key（Keyed 接口）分别熔断，某个域名故障不影响其他域名。
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBreakerOpen 表示熔断器处于断开（或半开且试探名额已满）状态，任务未被执行，可用 errors.Is 判断。
+var ErrBreakerOpen = errors.New("workerpool: circuit breaker is open")
+
+// BreakerState 表示熔断器状态。
+type BreakerState int32
+
+const (
+	BreakerClosed   BreakerState = iota // 闭合：正常放行
+	BreakerOpen                         // 断开：全部拒绝
+	BreakerHalfOpen                     // 半开：放行少量试探执行
+)
+
+// String 实现 fmt.Stringer。
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerOptions 配置熔断器，作为 Options.Breaker 使用，也可传给 NewCircuitBreaker 单独使用。
+type BreakerOptions struct {
+	// FailureThreshold 是闭合状态下触发断开的连续失败次数。
+	// 默认值：5。
+	FailureThreshold int
+
+	// CoolDown 是断开后进入半开状态前的冷却时间。
+	// 默认值：30s。
+	CoolDown time.Duration
+
+	// HalfOpenMax 是半开状态下允许同时进行的试探执行数。
+	// 默认值：1。
+	HalfOpenMax int
+
+	// SuccessThreshold 是半开状态下恢复闭合所需的连续成功次数，不超过 HalfOpenMax 时更易达成。
+	// 默认值：1。
+	SuccessThreshold int
+
+	// PerKey 为 true 时按任务 key（见 Keyed 接口）分别熔断；未实现 Keyed 的任务共用 key 为 "" 的熔断器。
+	PerKey bool
+
+	// IdleTTL 是 PerKey 时熔断器的空闲回收时间：闭合、没有执行中的任务且超过该时长未被使用的熔断器会被移除，
+	// 避免按主机、URL 等高基数 key 熔断时内存无限增长。移除后同一 key 再次出现时重新创建（闭合状态）。
+	// 默认值：10 × CoolDown。
+	IdleTTL time.Duration
+
+	// IsFailure 判断一次执行的错误是否计为失败。默认所有非 nil 错误均计为失败。
+	// 可用于排除业务错误（如 404），只对下游故障熔断。
+	IsFailure func(err error) bool
+
+	// OnStateChange 是状态变化时触发的回调，在独立 goroutine 中异步调用。
+	// key 为熔断器对应的任务 key（Pool 级别熔断器为 ""）。
+	OnStateChange func(key string, from, to BreakerState)
+}
+
+// setDefaults 为未设置的字段填充默认值。
+func (o *BreakerOptions) setDefaults() {
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = 5
+	}
+	if o.CoolDown <= 0 {
+		o.CoolDown = 30 * time.Second
+	}
+	if o.IdleTTL <= 0 {
+		o.IdleTTL = 10 * o.CoolDown
+	}
+	if o.HalfOpenMax <= 0 {
+		o.HalfOpenMax = 1
+	}
+	if o.SuccessThreshold <= 0 {
+		o.SuccessThreshold = 1
+	}
+	if o.IsFailure == nil {
+		o.IsFailure = func(err error) bool { return err != nil }
+	}
+}
+
+// =============================================================================
+// CircuitBreaker
+// =============================================================================
+
+// CircuitBreaker 是并发安全的熔断器。
+//
+// 单独使用示例：
+//
+//	b := NewCircuitBreaker("db", BreakerOptions{FailureThreshold: 3, CoolDown: 10 * time.Second})
+//	err := b.Do(func() error { return db.PingContext(ctx) })
+//	if errors.Is(err, ErrBreakerOpen) { ... }
+type CircuitBreaker struct {
+	key  string
+	opts BreakerOptions
+
+	mu        sync.Mutex
+	state     BreakerState
+	gen       uint64    // 每次状态变化加 1，用于丢弃旧状态下发出的执行结果
+	failures  int       // 闭合状态下的连续失败次数
+	successes int       // 半开状态下的连续成功次数
+	probes    int       // 半开状态下正在进行的试探执行数
+	openedAt  time.Time // 最近一次断开的时间
+	lastUsed  time.Time // 最近一次 allow 的时间，用于空闲回收
+
+	refs int // 持有该熔断器的任务数（含重试退避期间），由 breakerGroup.mu 保护
+
+	// onTransition 是 Pool 内部的状态变化钩子（更新指标、打日志），在持锁时调用，不可阻塞。
+	onTransition func(from, to BreakerState)
+}
+
+// NewCircuitBreaker 创建一个处于闭合状态的熔断器，key 会传给 OnStateChange 回调。
+func NewCircuitBreaker(key string, opts BreakerOptions) *CircuitBreaker {
+	opts.setDefaults()
+	return &CircuitBreaker{key: key, opts: opts}
+}
+
+// State 返回熔断器当前状态（断开且冷却期已过时返回 BreakerHalfOpen）。
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkCoolDown(time.Now())
+	return b.state
+}
+
+// Do 在熔断器允许时执行 fn 并记录结果；被拒绝时不执行 fn，直接返回 ErrBreakerOpen。
+func (b *CircuitBreaker) Do(fn func() error) error {
+	gen, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.record(gen, b.opts.IsFailure(err), false)
+	return err
+}
+
+// allow 判断是否放行一次执行，返回放行时的状态代号，供 record 使用。
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.lastUsed = now
+	b.checkCoolDown(now)
+	switch b.state {
+	case BreakerOpen:
+		return 0, ErrBreakerOpen
+	case BreakerHalfOpen:
+		if b.probes >= b.opts.HalfOpenMax {
+			return 0, ErrBreakerOpen
+		}
+		b.probes++
+	}
+	return b.gen, nil
+}
+
+// record 记录一次已放行执行的结果。ignore 为 true 表示结果不反映下游健康状况（如被主动取消），
+// 只归还半开试探名额。放行后状态已发生变化的结果会被忽略。
+func (b *CircuitBreaker) record(gen uint64, failed, ignore bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if gen != b.gen {
+		return
+	}
+	switch b.state {
+	case BreakerClosed:
+		if ignore {
+			return
+		}
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.opts.FailureThreshold {
+			b.transition(BreakerOpen)
+		}
+
+	case BreakerHalfOpen:
+		b.probes--
+		if ignore {
+			return
+		}
+		if failed {
+			b.transition(BreakerOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.opts.SuccessThreshold {
+			b.transition(BreakerClosed)
+		}
+	}
+}
+
+// checkCoolDown 在断开状态冷却期结束时转为半开。调用方必须持有 b.mu。
+func (b *CircuitBreaker) checkCoolDown(now time.Time) {
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.opts.CoolDown {
+		b.transition(BreakerHalfOpen)
+	}
+}
+
+// idle 判断熔断器是否闭合且自 now 起空闲超过 IdleTTL。
+func (b *CircuitBreaker) idle(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state == BreakerClosed && now.Sub(b.lastUsed) >= b.opts.IdleTTL
+}
+
+// transition 切换状态并重置计数。调用方必须持有 b.mu。
+func (b *CircuitBreaker) transition(to BreakerState) {
+	from := b.state
+	b.state = to
+	b.gen++
+	b.failures, b.successes, b.probes = 0, 0, 0
+	if to == BreakerOpen {
+		b.openedAt = time.Now()
+	}
+
+	if b.onTransition != nil {
+		b.onTransition(from, to)
+	}
+	if b.opts.OnStateChange != nil {
+		go b.opts.OnStateChange(b.key, from, to)
+	}
+}
+
+// =============================================================================
+// WorkerPool 集成
+// =============================================================================
+
+// breakerGroup 管理 Pool 的熔断器：Pool 级别只有一个，按 key 熔断时每个 key 一个。
+type breakerGroup struct {
+	opts     BreakerOptions
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+	swept    time.Time // 最近一次回收空闲熔断器的时间
+
+	open   *atomic.Int64                    // 指向 Metrics.BreakersOpen
+	logger func(format string, args ...any) // Pool 的日志函数
+}
+
+// acquire 返回 key 对应的熔断器并增加引用计数，不存在时创建。用完需调用 release。
+func (g *breakerGroup) acquire(key string) *CircuitBreaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.opts.PerKey {
+		g.sweep(time.Now())
+	}
+	b, ok := g.breakers[key]
+	if !ok {
+		b = NewCircuitBreaker(key, g.opts)
+		b.onTransition = func(from, to BreakerState) {
+			// 非闭合（断开或半开）的熔断器计入 BreakersOpen
+			switch {
+			case from == BreakerClosed:
+				g.open.Add(1)
+			case to == BreakerClosed:
+				g.open.Add(-1)
+			}
+			g.logger("[workerpool] 熔断器 %q 状态变化: %s -> %s", key, from, to)
+		}
+		g.breakers[key] = b
+	}
+	b.refs++
+	return b
+}
+
+// release 归还 acquire 取得的熔断器。
+func (g *breakerGroup) release(b *CircuitBreaker) {
+	g.mu.Lock()
+	b.refs--
+	g.mu.Unlock()
+}
+
+// sweep 移除没有任务持有、闭合且空闲的熔断器，每个 CoolDown 最多执行一次。调用方必须持有 g.mu。
+// 没有任务持有的熔断器不会再被调用，移除后不会再发生状态变化，不影响 BreakersOpen 计数。
+func (g *breakerGroup) sweep(now time.Time) {
+	if now.Sub(g.swept) < g.opts.CoolDown {
+		return
+	}
+	g.swept = now
+	for key, b := range g.breakers {
+		if b.refs == 0 && b.idle(now) {
+			delete(g.breakers, key)
+		}
+	}
+}
+
+// newBreakerGroup 根据配置创建 breakerGroup；未配置熔断器时返回 nil。
+func (p *WorkerPool[T]) newBreakerGroup() *breakerGroup {
+	if p.opts.Breaker == nil {
+		return nil
+	}
+	opts := *p.opts.Breaker
+	opts.setDefaults()
+	return &breakerGroup{
+		opts:     opts,
+		breakers: make(map[string]*CircuitBreaker),
+		open:     &p.metrics.BreakersOpen,
+		logger:   p.opts.Logger,
+	}
+}
+
+// breakerFor 返回任务对应的熔断器，用完需调用 releaseBreaker；未配置熔断器时返回 nil。
+func (p *WorkerPool[T]) breakerFor(task Task[T]) *CircuitBreaker {
+	if p.breakers == nil {
+		return nil
+	}
+	key := ""
+	if p.breakers.opts.PerKey {
+		if k, ok := task.(Keyed); ok {
+			key = k.TaskKey()
+		}
+	}
+	return p.breakers.acquire(key)
+}
+
+// releaseBreaker 归还 breakerFor 取得的熔断器，b 为 nil 时什么也不做。
+func (p *WorkerPool[T]) releaseBreaker(b *CircuitBreaker) {
+	if b != nil {
+		p.breakers.release(b)
+	}
+}
+
+// BreakerState 返回 key 对应熔断器的当前状态（Pool 级别熔断器的 key 为 ""）。
+// 未配置熔断器或该 key 尚无任务执行过时返回 BreakerClosed。
+func (p *WorkerPool[T]) BreakerState(key string) BreakerState {
+	if p.breakers == nil {
+		return BreakerClosed
+	}
+	p.breakers.mu.Lock()
+	b, ok := p.breakers.breakers[key]
+	p.breakers.mu.Unlock()
+	if !ok {
+		return BreakerClosed
+	}
+	return b.State()
+}
+
+// runWithBreaker 在熔断器保护下执行一次任务：被拒绝时不执行，直接返回包装了 ErrBreakerOpen 的错误。
+func (p *WorkerPool[T]) runWithBreaker(ctx context.Context, task Task[T], b *CircuitBreaker) (T, error) {
+	if b == nil {
+		return p.runOnce(ctx, task)
+	}
+	gen, err := b.allow()
+	if err != nil {
+		p.metrics.BreakerRejected.Add(1)
+		var zero T
+		return zero, fmt.Errorf("任务 %q 被熔断器 %q 拒绝: %w", task.TaskID(), b.key, err)
+	}
+	val, err := p.runOnce(ctx, task)
+	// 取消导致的失败不反映下游健康状况
+	b.record(gen, b.opts.IsFailure(err), ctx.Err() != nil)
+	return val, err
+}
@@ -0,0 +1,183 @@
+package tools
+
+// 运维控制：暂停/恢复取任务、等待排空，以及带截止时间的优雅停止。
+//
+//   - Pause：Worker 不再从队列取新任务，执行中的任务照常完成，Submit 仍可入队（队列不丢失）
+//   - Resume：恢复取任务
+//   - Drain：等待已提交的任务全部结束（队列为空且无执行中任务），不关闭 Pool
+//   - StopGracefulContext：优雅停止，ctx 到期后退化为 Stop()
+
+import (
+	"context"
+	"sync"
+)
+
+// pauseGate 记录暂停状态。两个 channel 分别在进入暂停和恢复时关闭，
+// Worker 在 select 中同时等待它们，状态变化时能立即感知。
+type pauseGate struct {
+	mu       sync.Mutex
+	paused   bool
+	pausing  chan struct{} // 未暂停时有效：进入暂停时关闭
+	resuming chan struct{} // 暂停时有效：恢复时关闭
+}
+
+// chans 返回 Worker 当前应等待的状态变化 channel：未暂停时只有 pausing 非 nil，暂停时只有 resuming 非 nil。
+func (g *pauseGate) chans() (pausing, resuming <-chan struct{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.pausing == nil {
+		g.pausing = make(chan struct{})
+	}
+	if g.paused {
+		return nil, g.resuming
+	}
+	return g.pausing, nil
+}
+
+// set 切换暂停状态，状态确实发生变化时返回 true。
+func (g *pauseGate) set(paused bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused == paused {
+		return false
+	}
+	if g.pausing == nil {
+		g.pausing = make(chan struct{})
+	}
+	g.paused = paused
+	if paused {
+		close(g.pausing)
+		g.resuming = make(chan struct{})
+	} else {
+		close(g.resuming)
+		g.pausing = make(chan struct{})
+	}
+	return true
+}
+
+// isPaused 返回是否处于暂停状态。
+func (g *pauseGate) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// Pause 暂停取任务：执行中的任务照常完成，队列中的任务保留，Submit 仍可继续入队。
+// 暂停期间不会按队列积压自动扩容。重复调用无副作用。
+func (p *WorkerPool[T]) Pause() {
+	if p.pause.set(true) {
+		p.opts.Logger("[workerpool] 已暂停取任务（队列积压 = %d）", p.metrics.QueueDepth.Load())
+		p.emit(Event{Type: EventPaused, Workers: int(p.metrics.Workers.Load())})
+	}
+}
+
+// Resume 恢复取任务。Stop/StopGraceful 会自动恢复，以便排空队列。重复调用无副作用。
+func (p *WorkerPool[T]) Resume() {
+	if p.pause.set(false) {
+		p.opts.Logger("[workerpool] 已恢复取任务（队列积压 = %d）", p.metrics.QueueDepth.Load())
+		p.emit(Event{Type: EventResumed, Workers: int(p.metrics.Workers.Load())})
+		// 暂停期间可能积压了大量任务，提醒扩容 goroutine 重新评估
+		select {
+		case p.scaleSignal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Paused 返回 Pool 是否处于暂停状态。
+func (p *WorkerPool[T]) Paused() bool {
+	return p.pause.isPaused()
+}
+
+// activeCounter 是未结束任务的计数，归零时关闭 zero channel 通知等待方，代替轮询。
+type activeCounter struct {
+	mu   sync.Mutex
+	n    int64
+	zero chan struct{} // n 为 0 时已关闭；n 由 0 变为正数时替换为新的 channel
+}
+
+// newActiveCounter 创建计数为 0 的计数器。
+func newActiveCounter() *activeCounter {
+	c := &activeCounter{zero: make(chan struct{})}
+	close(c.zero)
+	return c
+}
+
+// add 调整计数，归零时唤醒所有等待方。
+func (c *activeCounter) add(delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	was := c.n
+	c.n += delta
+	switch {
+	case c.n == 0:
+		close(c.zero)
+	case was == 0:
+		c.zero = make(chan struct{})
+	}
+}
+
+// load 返回当前计数。
+func (c *activeCounter) load() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// done 返回下一次归零时关闭的 channel（当前为 0 时返回已关闭的 channel）。
+func (c *activeCounter) done() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.zero
+}
+
+// Drain 阻塞等待所有已提交的任务结束（队列为空、没有执行中的任务，且 SubmitWithResult 的结果已送达），
+// Pool 保持运行、可继续提交。
+//
+// 尚未触发的定时任务（SubmitAt/SubmitCron）不计入。暂停期间队列不会减少，
+// 此时 Drain 会一直等待到 ctx 结束，返回 context.Cause(ctx)。
+func (p *WorkerPool[T]) Drain(ctx context.Context) error {
+	select {
+	case <-p.active.done():
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// StopGracefulContext 与 StopGraceful 相同，但最多等待到 ctx 结束：
+// 到期后调用 Stop() 取消剩余任务并立即返回 context.Cause(ctx)；在此之前正常完成则返回 nil。
+//
+// 使用示例：
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := pool.StopGracefulContext(ctx); err != nil {
+//	    log.Printf("优雅停止超时，已强制停止: %v", err)
+//	}
+func (p *WorkerPool[T]) StopGracefulContext(ctx context.Context) error {
+	p.closeQueue()
+
+	done := make(chan struct{})
+	go func() {
+		// 阻塞等待所有 Worker goroutine 完成（包括正在执行的任务）
+		p.workerWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		// Worker 全部退出后，释放 context 资源
+		p.cancel()
+		p.emitStopped()
+		return nil
+	case <-ctx.Done():
+		p.opts.Logger("[workerpool] 优雅停止超时，强制停止（执行中 = %d，队列积压 = %d）",
+			p.metrics.InFlight.Load(), p.metrics.QueueDepth.Load())
+		p.Stop()
+		return context.Cause(ctx)
+	}
+}
@@ -0,0 +1,316 @@
+package tools
+
+// DAG 在 WorkerPool 之上提供有向无环图（依赖关系）任务编排能力。
+//
+// 核心特性：
+//   - 依赖声明：任务通过 DependsOn() 按 TaskID 声明上游任务
+//   - 结果传递：上游任务的 Result 会以 map 形式传给下游任务
+//   - 环检测：运行前校验依赖关系，存在环或缺失依赖时返回错误
+//   - 失败传播：上游失败时可选择跳过下游（DAGSkipDependents）或继续执行（DAGContinue）
+//   - 统一汇总：整张图执行完毕后返回 map[TaskID]Result[T]
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// DAGTask 接口
+// =============================================================================
+
+// DAGTask 是可以声明依赖关系的任务。
+//
+// 与 Task 的区别在于 Run 时会额外收到所有上游任务的执行结果，
+// key 为上游任务的 TaskID。
+//
+// 使用示例：
+//
+//	type ParseTask struct{}
+//	func (t *ParseTask) TaskID() string      { return "parse" }
+//	func (t *ParseTask) DependsOn() []string { return []string{"fetch"} }
+//	func (t *ParseTask) RunDAG(ctx context.Context, up map[string]Result[string]) (string, error) {
+//	    return parse(up["fetch"].Value), nil
+//	}
+type DAGTask[T any] interface {
+	// TaskID 返回任务在图中的唯一标识，下游任务通过它声明依赖。
+	TaskID() string
+
+	// DependsOn 返回当前任务依赖的上游 TaskID 列表，无依赖时返回 nil。
+	DependsOn() []string
+
+	// RunDAG 执行任务，upstream 包含所有直接上游任务的 Result。
+	// 在 DAGContinue 策略下，upstream 中可能包含 Err != nil 的结果，需要自行判断。
+	RunDAG(ctx context.Context, upstream map[string]Result[T]) (T, error)
+}
+
+// DAGFunc 是 DAGTask 的函数式实现，适合无需定义结构体的简单节点。
+//
+// 使用示例：
+//
+//	dag.Add(&DAGFunc[int]{ID: "sum", Deps: []string{"a", "b"}, Fn: func(ctx context.Context, up map[string]Result[int]) (int, error) {
+//	    return up["a"].Value + up["b"].Value, nil
+//	}})
+type DAGFunc[T any] struct {
+	ID   string                                                              // 任务唯一标识
+	Deps []string                                                            // 上游 TaskID 列表
+	Fn   func(ctx context.Context, upstream map[string]Result[T]) (T, error) // 任务逻辑
+}
+
+func (f *DAGFunc[T]) TaskID() string      { return f.ID }
+func (f *DAGFunc[T]) DependsOn() []string { return f.Deps }
+func (f *DAGFunc[T]) RunDAG(ctx context.Context, upstream map[string]Result[T]) (T, error) {
+	return f.Fn(ctx, upstream)
+}
+
+// =============================================================================
+// 失败传播策略与错误定义
+// =============================================================================
+
+// DAGFailurePolicy 决定上游任务失败时如何处理其下游任务。
+type DAGFailurePolicy int
+
+const (
+	// DAGSkipDependents 上游失败时跳过所有（直接或间接）下游任务，
+	// 被跳过任务的 Result.Err 为包装了 ErrDAGSkipped 的错误，Attempts 为 0。
+	DAGSkipDependents DAGFailurePolicy = iota
+
+	// DAGContinue 上游失败时下游任务照常执行，由下游自行检查 upstream 中的 Err。
+	DAGContinue
+)
+
+var (
+	// ErrDAGSkipped 表示任务因上游失败而被跳过，可用 errors.Is 判断。
+	ErrDAGSkipped = errors.New("workerpool: skipped because an upstream task failed")
+
+	// ErrDAGCycle 表示依赖关系中存在环，可用 errors.Is 判断。
+	ErrDAGCycle = errors.New("workerpool: dependency cycle detected")
+)
+
+// =============================================================================
+// DAG：依赖图执行器
+// =============================================================================
+
+// DAG 保存一组带依赖关系的任务，并在指定的 WorkerPool 上按拓扑顺序执行。
+//
+// 没有依赖关系的任务会被同时提交到 Pool 中并行执行；
+// 某个任务的全部上游完成后，它才会被提交。
+//
+// 注意：Add 不是并发安全的，应在 Run 之前由单个 goroutine 完成构图。
+//
+// 使用示例：
+//
+//	pool := NewPool[string](Options{Workers: 4})
+//	defer pool.StopGraceful()
+//
+//	dag := NewDAG(pool, DAGSkipDependents)
+//	_ = dag.Add(fetchTask)
+//	_ = dag.Add(parseTask) // DependsOn() 返回 []string{"fetch"}
+//	results, err := dag.Run(context.Background())
+type DAG[T any] struct {
+	pool   *WorkerPool[T]        // 实际执行任务的 Pool
+	policy DAGFailurePolicy      // 失败传播策略
+	nodes  map[string]DAGTask[T] // TaskID -> 任务
+	order  []string              // 任务添加顺序，保证提交顺序稳定
+}
+
+// NewDAG 创建一个在 pool 上执行的依赖图，policy 指定上游失败时的处理方式。
+func NewDAG[T any](pool *WorkerPool[T], policy DAGFailurePolicy) *DAG[T] {
+	return &DAG[T]{
+		pool:   pool,
+		policy: policy,
+		nodes:  make(map[string]DAGTask[T]),
+	}
+}
+
+// Add 向图中添加一个任务，TaskID 重复时返回错误。
+// 依赖的上游任务可以晚于当前任务添加，依赖完整性在 Validate/Run 时统一检查。
+func (d *DAG[T]) Add(task DAGTask[T]) error {
+	id := task.TaskID()
+	if _, exists := d.nodes[id]; exists {
+		return fmt.Errorf("workerpool: duplicate dag task %q", id)
+	}
+	d.nodes[id] = task
+	d.order = append(d.order, id)
+	return nil
+}
+
+// Validate 校验依赖图：所有依赖必须已添加，且不能存在环。
+// 发现环时返回的错误包装了 ErrDAGCycle，并在消息中给出环路径，例如 "a -> b -> a"。
+func (d *DAG[T]) Validate() error {
+	for _, id := range d.order {
+		for _, dep := range d.nodes[id].DependsOn() {
+			if _, ok := d.nodes[dep]; !ok {
+				return fmt.Errorf("workerpool: dag task %q depends on unknown task %q", id, dep)
+			}
+		}
+	}
+
+	// 三色 DFS：0=未访问 1=访问中（在当前路径上） 2=已完成
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := make(map[string]int, len(d.nodes))
+	var path []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		color[id] = grey
+		path = append(path, id)
+		for _, dep := range d.nodes[id].DependsOn() {
+			switch color[dep] {
+			case grey:
+				// 回边：从路径中截取环
+				start := 0
+				for i, p := range path {
+					if p == dep {
+						start = i
+						break
+					}
+				}
+				cycle := append(append([]string{}, path[start:]...), dep)
+				return fmt.Errorf("%w: %s", ErrDAGCycle, strings.Join(cycle, " -> "))
+			case white:
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		color[id] = black
+		return nil
+	}
+
+	for _, id := range d.order {
+		if color[id] == white {
+			if err := visit(id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Run 校验并执行整张依赖图，阻塞直到所有任务完成（或被跳过），返回 TaskID -> Result 的映射。
+//
+// 返回的 error 仅表示图本身无效（环、缺失依赖）或 ctx 被取消；
+// 单个任务的失败记录在对应 Result.Err 中，不会使 Run 返回错误。
+//
+// ctx 取消时：尚未提交的任务以 ctx.Err() 作为结果，正在执行的任务会收到取消信号，
+// Run 立即返回当前已收集的结果和 ctx.Err()。
+func (d *DAG[T]) Run(ctx context.Context) (map[string]Result[T], error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	// ---- 构建反向邻接表和入度 ----
+	pending := make(map[string]int, len(d.nodes))         // 尚未完成的上游数量
+	dependents := make(map[string][]string, len(d.nodes)) // 上游 -> 下游列表
+	for _, id := range d.order {
+		deps := d.nodes[id].DependsOn()
+		pending[id] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+
+	results := make(map[string]Result[T], len(d.nodes))
+	// 缓冲足够大，Run 提前返回时 Worker 也不会因发送结果而阻塞
+	ch := make(chan Result[T], len(d.nodes))
+	running := 0
+
+	// complete 记录一个任务的最终结果，并推进其下游；返回新就绪的任务
+	var complete func(r Result[T]) []string
+	complete = func(r Result[T]) []string {
+		results[r.TaskID] = r
+		var ready []string
+		for _, next := range dependents[r.TaskID] {
+			pending[next]--
+			if pending[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+		return ready
+	}
+
+	// schedule 提交就绪任务；若策略要求跳过或提交失败，则直接生成结果并继续推进
+	var schedule func(ids []string)
+	schedule = func(ids []string) {
+		for _, id := range ids {
+			task := d.nodes[id]
+			upstream := make(map[string]Result[T], len(task.DependsOn()))
+			var failedDep string
+			for _, dep := range task.DependsOn() {
+				upstream[dep] = results[dep]
+				if results[dep].Err != nil && failedDep == "" {
+					failedDep = dep
+				}
+			}
+
+			if failedDep != "" && d.policy == DAGSkipDependents {
+				schedule(complete(Result[T]{
+					TaskID: id,
+					Err:    fmt.Errorf("%w: %q", ErrDAGSkipped, failedDep),
+				}))
+				continue
+			}
+
+			j := &dagJob[T]{task: task, upstream: upstream, ctx: ctx}
+			if err := d.pool.SubmitWithResult(j, ch); err != nil {
+				schedule(complete(Result[T]{TaskID: id, Err: err}))
+				continue
+			}
+			running++
+		}
+	}
+
+	// 提交所有无依赖的根任务
+	var roots []string
+	for _, id := range d.order {
+		if pending[id] == 0 {
+			roots = append(roots, id)
+		}
+	}
+	schedule(roots)
+
+	for running > 0 {
+		select {
+		case r := <-ch:
+			running--
+			schedule(complete(r))
+
+		case <-ctx.Done():
+			// 未完成的任务统一标记为取消
+			for _, id := range d.order {
+				if _, done := results[id]; !done {
+					results[id] = Result[T]{TaskID: id, Err: ctx.Err()}
+				}
+			}
+			return results, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+// dagJob 将 DAGTask 适配为 Task，供 WorkerPool 执行。
+// 它把上游结果和 DAG.Run 的 ctx 一并带入任务。
+type dagJob[T any] struct {
+	task     DAGTask[T]
+	upstream map[string]Result[T]
+	ctx      context.Context // DAG.Run 的 ctx，取消时同步取消任务
+}
+
+func (j *dagJob[T]) TaskID() string { return j.task.TaskID() }
+
+func (j *dagJob[T]) Run(ctx context.Context) (T, error) {
+	// 任务 ctx 同时受 Pool（超时/Stop）和 DAG.Run 的 ctx 控制
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(j.ctx, cancel)
+	defer stop()
+
+	return j.task.RunDAG(ctx, j.upstream)
+}
@@ -0,0 +1,206 @@
+package tools
+
+// 结构化事件：以类型化的 Event 描述 WorkerPool 的生命周期，替代只能打印文本的 Logger。
+//
+// 三种消费方式可同时使用：
+//   - Options.OnEvent：同步回调，在产生事件的 goroutine 中直接调用，必须快速返回
+//   - WorkerPool.Events：订阅 channel，消费不及时的事件会被丢弃并计入 Metrics.EventsDropped
+//   - Options.Slog：直接输出为 log/slog 结构化日志，此时默认的 printf Logger 不再输出
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// EventType 表示事件类型。
+type EventType int
+
+const (
+	EventQueued    EventType = iota // 任务已入队
+	EventStarted                    // Worker 开始执行任务（已通过限速等待）
+	EventRetrying                   // 本次执行失败，将在退避后重试
+	EventTimedOut                   // 本次执行超过 TaskTimeout
+	EventSucceeded                  // 任务最终成功
+	EventFailed                     // 任务最终失败（耗尽重试、panic、熔断拒绝等）
+	EventCancelled                  // 任务被主动取消
+	EventScaled                     // Worker 数量发生变化
+	EventStopped                    // Pool 已停止
+	EventPaused                     // Pool 已暂停取任务
+	EventResumed                    // Pool 已恢复取任务
+)
+
+// String 实现 fmt.Stringer。
+func (t EventType) String() string {
+	switch t {
+	case EventQueued:
+		return "queued"
+	case EventStarted:
+		return "started"
+	case EventRetrying:
+		return "retrying"
+	case EventTimedOut:
+		return "timed-out"
+	case EventSucceeded:
+		return "succeeded"
+	case EventFailed:
+		return "failed"
+	case EventCancelled:
+		return "cancelled"
+	case EventScaled:
+		return "scaled"
+	case EventStopped:
+		return "stopped"
+	case EventPaused:
+		return "paused"
+	case EventResumed:
+		return "resumed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 是一条生命周期事件，未涉及的字段为零值。
+type Event struct {
+	Type EventType
+	Pool string    // Pool 名称（Options.Name）
+	Time time.Time // 事件产生时间
+
+	TaskID  string // 任务事件的 TaskID；Pool 级事件（scaled/stopped/paused/resumed）为空
+	Attempt int    // 第几次执行（从 1 开始）；started 为 1，最终事件为总执行次数
+
+	QueueWait time.Duration // started：在队列中等待的时间
+	Duration  time.Duration // 单次执行耗时（retrying/timed-out）或累计执行耗时（最终事件）
+	Delay     time.Duration // retrying：重试前的退避时间
+
+	Err     error // retrying/timed-out/failed/cancelled 的错误
+	Workers int   // Pool 级事件：当前 Worker 数量
+}
+
+// eventHub 管理 Events 订阅者。
+type eventHub struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool // Pool 停止后关闭所有订阅 channel，之后不再投递
+}
+
+// Events 订阅 Pool 的事件流，buffer 为 channel 缓冲大小（小于 1 按 1 处理）。
+//
+// 投递是非阻塞的：channel 满时事件被丢弃并计入 Metrics.EventsDropped。
+// 调用返回的 cancel 取消订阅并关闭 channel；Pool 停止时在投递 EventStopped 后也会关闭所有订阅 channel。
+// Stop() 不等待 Worker 退出，其后产生的事件只会交给 OnEvent 和 Slog。
+func (p *WorkerPool[T]) Events(buffer int) (<-chan Event, func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ch := make(chan Event, buffer)
+
+	h := &p.events
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs == nil {
+		h.subs = make(map[chan Event]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	p.hasSubs.Store(true)
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := h.subs[ch]; ok {
+				delete(h.subs, ch)
+				close(ch)
+			}
+		})
+	}
+}
+
+// emit 填充公共字段并把事件交给所有消费方。没有任何消费方时直接返回，开销可忽略。
+func (p *WorkerPool[T]) emit(e Event) {
+	if p.opts.OnEvent == nil && p.opts.Slog == nil && !p.hasSubs.Load() {
+		return
+	}
+	e.Pool = p.opts.Name
+	e.Time = time.Now()
+
+	if p.opts.OnEvent != nil {
+		p.opts.OnEvent(e)
+	}
+	if p.opts.Slog != nil {
+		logEvent(p.opts.Slog, e)
+	}
+
+	h := &p.events
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+			p.metrics.EventsDropped.Add(1)
+		}
+	}
+	if e.Type == EventStopped {
+		// 停止事件是最后一条，投递后关闭所有订阅
+		for ch := range h.subs {
+			close(ch)
+		}
+		h.subs = nil
+		h.closed = true
+	}
+}
+
+// emitStopped 产生 EventStopped，多次调用只生效一次。
+func (p *WorkerPool[T]) emitStopped() {
+	p.stopEvent.Do(func() {
+		p.emit(Event{Type: EventStopped, Workers: int(p.metrics.Workers.Load())})
+	})
+}
+
+// logEvent 把事件输出为 slog 结构化日志：最终失败为 Error，重试和超时为 Warn，
+// 入队和开始执行为 Debug，其余为 Info。
+func logEvent(l *slog.Logger, e Event) {
+	level := slog.LevelInfo
+	switch e.Type {
+	case EventQueued, EventStarted:
+		level = slog.LevelDebug
+	case EventRetrying, EventTimedOut:
+		level = slog.LevelWarn
+	case EventFailed:
+		level = slog.LevelError
+	}
+
+	attrs := []slog.Attr{slog.String("pool", e.Pool)}
+	if e.TaskID != "" {
+		attrs = append(attrs, slog.String("task_id", e.TaskID))
+	}
+	if e.Attempt > 0 {
+		attrs = append(attrs, slog.Int("attempt", e.Attempt))
+	}
+	if e.QueueWait > 0 {
+		attrs = append(attrs, slog.Duration("queue_wait", e.QueueWait))
+	}
+	if e.Duration > 0 {
+		attrs = append(attrs, slog.Duration("duration", e.Duration))
+	}
+	if e.Delay > 0 {
+		attrs = append(attrs, slog.Duration("delay", e.Delay))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.Any("error", e.Err))
+	}
+	if e.TaskID == "" {
+		attrs = append(attrs, slog.Int("workers", e.Workers))
+	}
+	l.LogAttrs(context.Background(), level, "workerpool "+e.Type.String(), attrs...)
+}
@@ -0,0 +1,170 @@
+package tools
+
+// Future：已提交任务的句柄，用于等待结果、查询状态和单独取消任务。
+//
+// 每个通过 Submit/SubmitWithResult 入队的任务都拥有一个独立的 Future，
+// 以及一个从 Pool ctx 派生出的专属 context：取消某个任务只会取消它自己的 context，
+// 不会影响 Pool 中的其他任务。
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrTaskCancelled 表示任务被 Future.Cancel 或 WorkerPool.Cancel 主动取消，可用 errors.Is 判断。
+var ErrTaskCancelled = errors.New("workerpool: task cancelled")
+
+// TaskStatus 表示任务在 Pool 中的生命周期状态。
+type TaskStatus int32
+
+const (
+	TaskQueued   TaskStatus = iota // 已入队，等待 Worker 取走
+	TaskRunning                    // 正在执行 Run()
+	TaskRetrying                   // 上一次执行失败，正在等待退避时间后重试
+	TaskDone                       // 已结束（成功、失败或被取消），结果可用
+)
+
+// String 实现 fmt.Stringer。
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskQueued:
+		return "queued"
+	case TaskRunning:
+		return "running"
+	case TaskRetrying:
+		return "retrying"
+	case TaskDone:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
+// Future 是单个任务的结果句柄，由 Submit 返回。
+//
+// 使用示例：
+//
+//	f, err := pool.Submit(task)
+//	if err != nil { ... }
+//	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+//	defer cancel()
+//	r, err := f.Wait(ctx)
+//	if err != nil {
+//	    f.Cancel() // 等不及了，取消任务
+//	}
+type Future[T any] struct {
+	taskID string
+	status atomic.Int32
+
+	// ctx 是任务专属的 context（Pool ctx 的子 context），Cancel 只取消它。
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	done   chan struct{} // 任务结束时关闭
+	result Result[T]     // done 关闭后只读
+}
+
+// newFuture 创建一个以 parent 为父 context 的 Future，初始状态为 TaskQueued。
+func newFuture[T any](parent context.Context, taskID string) *Future[T] {
+	ctx, cancel := context.WithCancelCause(parent)
+	return &Future[T]{
+		taskID: taskID,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// TaskID 返回任务的唯一标识。
+func (f *Future[T]) TaskID() string { return f.taskID }
+
+// Status 返回任务当前状态。
+func (f *Future[T]) Status() TaskStatus { return TaskStatus(f.status.Load()) }
+
+// Done 返回一个在任务结束时关闭的 channel，可配合 select 使用。
+func (f *Future[T]) Done() <-chan struct{} { return f.done }
+
+// Wait 阻塞等待任务结束并返回结果。
+//
+// 若 ctx 先于任务结束被取消，返回 ctx 的错误，任务本身不受影响（仍会继续执行）；
+// 如需同时放弃任务，请再调用 Cancel()。
+func (f *Future[T]) Wait(ctx context.Context) (Result[T], error) {
+	select {
+	case <-f.done:
+		return f.result, nil
+	case <-ctx.Done():
+		return Result[T]{TaskID: f.taskID}, ctx.Err()
+	}
+}
+
+// Cancel 取消任务，返回是否在任务结束前成功发出取消信号。
+//
+//   - 排队中：Worker 取到任务后不再执行，直接以 ErrTaskCancelled 结束
+//   - 执行中：任务的 ctx 被取消（需任务正确监听 ctx），且不会再重试
+//   - 重试等待中：立即结束退避等待，以 ErrTaskCancelled 结束
+//   - 已结束：无任何效果，返回 false
+func (f *Future[T]) Cancel() bool {
+	select {
+	case <-f.done:
+		return false
+	default:
+	}
+	f.cancel(ErrTaskCancelled)
+	return true
+}
+
+// setStatus 更新任务状态。
+func (f *Future[T]) setStatus(s TaskStatus) { f.status.Store(int32(s)) }
+
+// complete 记录最终结果、唤醒等待方并释放任务 context。只能调用一次。
+func (f *Future[T]) complete(r Result[T]) {
+	f.result = r
+	f.setStatus(TaskDone)
+	close(f.done)
+	f.cancel(nil)
+}
+
+// =============================================================================
+// 按 TaskID 取消
+// =============================================================================
+
+// Cancel 按 TaskID 取消所有尚未结束的同名任务，返回被取消的任务数量。
+// 其他任务不受影响。
+func (p *WorkerPool[T]) Cancel(taskID string) int {
+	p.futMu.Lock()
+	defer p.futMu.Unlock()
+
+	n := 0
+	for f := range p.futures[taskID] {
+		if f.Cancel() {
+			n++
+		}
+	}
+	return n
+}
+
+// trackFuture 登记一个尚未结束的 Future，供 Cancel(taskID) 查找。
+func (p *WorkerPool[T]) trackFuture(f *Future[T]) {
+	p.futMu.Lock()
+	defer p.futMu.Unlock()
+
+	set, ok := p.futures[f.taskID]
+	if !ok {
+		set = make(map[*Future[T]]struct{})
+		p.futures[f.taskID] = set
+	}
+	set[f] = struct{}{}
+}
+
+// untrackFuture 在任务结束（或入队失败）时移除登记。
+func (p *WorkerPool[T]) untrackFuture(f *Future[T]) {
+	p.futMu.Lock()
+	defer p.futMu.Unlock()
+
+	set := p.futures[f.taskID]
+	delete(set, f)
+	if len(set) == 0 {
+		delete(p.futures, f.taskID)
+	}
+}
@@ -0,0 +1,239 @@
+package tools
+
+// Prometheus / OpenMetrics 指标导出。
+//
+// 核心特性：
+//   - 延迟直方图：排队等待时间、执行耗时、执行次数三类分布，补充 Metrics 中的计数器
+//   - 文本导出：按 Prometheus text format 0.0.4 输出，Accept 头请求 OpenMetrics 时自动切换格式
+//   - 多 Pool 汇总：一个 Handler 可同时导出多个不同结果类型的 Pool，以 pool 标签区分
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// =============================================================================
+// Histogram：无锁直方图
+// =============================================================================
+
+// DefaultLatencyBuckets 是排队等待时间和执行耗时直方图的默认桶上界（单位：秒）。
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// DefaultAttemptBuckets 是执行次数直方图的默认桶上界。
+var DefaultAttemptBuckets = []float64{1, 2, 3, 5, 10}
+
+// Histogram 是固定分桶的直方图，Observe 使用原子操作，可被多个 Worker 并发调用。
+type Histogram struct {
+	bounds []float64      // 升序排列的桶上界（不含 +Inf）
+	counts []atomic.Int64 // 每个桶的非累计计数，最后一个元素对应 +Inf
+	sum    atomic.Uint64  // 观测值总和（float64 的位模式）
+}
+
+// NewHistogram 使用给定的桶上界创建直方图，bounds 会被复制并排序。
+func NewHistogram(bounds []float64) *Histogram {
+	b := append([]float64(nil), bounds...)
+	sort.Float64s(b)
+	return &Histogram{
+		bounds: b,
+		counts: make([]atomic.Int64, len(b)+1),
+	}
+}
+
+// Observe 记录一个观测值。
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v) // 第一个 >= v 的桶
+	h.counts[i].Add(1)
+	for {
+		old := h.sum.Load()
+		if h.sum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Snapshot 返回直方图的值类型快照，Counts 为累计计数（与 Prometheus 的 le 语义一致）。
+// Count 取自累计计数的最后一项，而不是单独计数，保证并发 Observe 时 +Inf 桶不会小于其他桶。
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	if h == nil {
+		return HistogramSnapshot{}
+	}
+	s := HistogramSnapshot{
+		Bounds: h.bounds,
+		Counts: make([]int64, len(h.counts)),
+		Sum:    math.Float64frombits(h.sum.Load()),
+	}
+	var cum int64
+	for i := range h.counts {
+		cum += h.counts[i].Load()
+		s.Counts[i] = cum
+	}
+	s.Count = cum
+	return s
+}
+
+// HistogramSnapshot 是 Histogram 的值类型快照。
+type HistogramSnapshot struct {
+	Bounds []float64 // 桶上界（不含 +Inf）
+	Counts []int64   // 累计计数，len(Counts) == len(Bounds)+1，最后一个对应 +Inf
+	Sum    float64   // 观测值总和
+	Count  int64     // 观测次数
+}
+
+// =============================================================================
+// 指标导出
+// =============================================================================
+
+// MetricsSource 是可被导出指标的 Pool，所有 *WorkerPool[T] 均实现了该接口。
+// 由于不依赖类型参数 T，不同结果类型的 Pool 可以放进同一个 Handler。
+type MetricsSource interface {
+	Name() string
+	Metrics() MetricsSnapshot
+}
+
+// Name 返回 Pool 的名称（Options.Name），用作导出指标的 pool 标签。
+func (p *WorkerPool[T]) Name() string {
+	return p.opts.Name
+}
+
+// MetricsHandler 返回导出当前 Pool 指标的 http.Handler，等价于 NewMetricsHandler(p)。
+//
+// 使用示例：
+//
+//	http.Handle("/metrics", pool.MetricsHandler())
+func (p *WorkerPool[T]) MetricsHandler() http.Handler {
+	return NewMetricsHandler(p)
+}
+
+// NewMetricsHandler 返回一个同时导出多个 Pool 指标的 http.Handler。
+//
+// 默认输出 Prometheus text format 0.0.4；
+// 若请求的 Accept 头包含 application/openmetrics-text，则输出 OpenMetrics 1.0.0 格式。
+//
+// 使用示例：
+//
+//	http.Handle("/metrics", NewMetricsHandler(fetchPool, parsePool))
+func NewMetricsHandler(sources ...MetricsSource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		}
+		_ = writeMetrics(w, openMetrics, sources)
+	})
+}
+
+// WritePrometheus 将多个 Pool 的指标以 Prometheus text format 写入 w，
+// 适合不经过 HTTP 的场景（如推送到 Pushgateway 或写入文件）。
+func WritePrometheus(w io.Writer, sources ...MetricsSource) error {
+	return writeMetrics(w, false, sources)
+}
+
+// metricDesc 描述一个计数器或仪表盘类指标。
+type metricDesc struct {
+	name  string // 不含 _total 后缀的指标族名称
+	help  string
+	typ   string // counter 或 gauge
+	value func(s MetricsSnapshot) int64
+
+	// float 非 nil 时代替 value 输出浮点数值
+	float func(s MetricsSnapshot) float64
+}
+
+var poolMetricDescs = []metricDesc{
+	{"workerpool_tasks_submitted", "Total number of tasks submitted to the pool.", "counter", func(s MetricsSnapshot) int64 { return s.Submitted }, nil},
+	{"workerpool_tasks_succeeded", "Total number of tasks that succeeded.", "counter", func(s MetricsSnapshot) int64 { return s.Succeeded }, nil},
+	{"workerpool_tasks_failed", "Total number of tasks that failed after all retries.", "counter", func(s MetricsSnapshot) int64 { return s.Failed }, nil},
+	{"workerpool_tasks_retried", "Total number of task retries.", "counter", func(s MetricsSnapshot) int64 { return s.Retried }, nil},
+	{"workerpool_tasks_cancelled", "Total number of tasks cancelled by the caller.", "counter", func(s MetricsSnapshot) int64 { return s.Cancelled }, nil},
+	{"workerpool_tasks_panicked", "Total number of task runs that panicked.", "counter", func(s MetricsSnapshot) int64 { return s.Panicked }, nil},
+	{"workerpool_breaker_rejected", "Total number of task runs rejected by an open circuit breaker.", "counter", func(s MetricsSnapshot) int64 { return s.BreakerRejected }, nil},
+	{"workerpool_breakers_open", "Number of circuit breakers currently open or half-open.", "gauge", func(s MetricsSnapshot) int64 { return s.BreakersOpen }, nil},
+	{"workerpool_events_dropped", "Total number of events dropped because a subscriber channel was full.", "counter", func(s MetricsSnapshot) int64 { return s.EventsDropped }, nil},
+	{"workerpool_tasks_in_flight", "Number of tasks currently running.", "gauge", func(s MetricsSnapshot) int64 { return s.InFlight }, nil},
+	{"workerpool_tasks_abandoned", "Number of timed-out task runs whose goroutine has not returned yet.", "gauge", func(s MetricsSnapshot) int64 { return s.Abandoned }, nil},
+	{"workerpool_queue_depth", "Number of tasks waiting in the queue.", "gauge", func(s MetricsSnapshot) int64 { return s.QueueDepth }, nil},
+	{"workerpool_workers", "Number of active worker goroutines.", "gauge", func(s MetricsSnapshot) int64 { return s.Workers }, nil},
+	{"workerpool_concurrency_limit", "Current adaptive concurrency limit (0 when adaptive mode is off).", "gauge", func(s MetricsSnapshot) int64 { return s.ConcurrencyLimit }, nil},
+	{"workerpool_rate_limit", "Current task start rate limit per second (0 when unlimited).", "gauge", nil, func(s MetricsSnapshot) float64 { return s.CurrentRate }},
+}
+
+// histogramDesc 描述一个直方图类指标。
+type histogramDesc struct {
+	name  string
+	help  string
+	value func(s MetricsSnapshot) HistogramSnapshot
+}
+
+var poolHistogramDescs = []histogramDesc{
+	{"workerpool_queue_wait_seconds", "Time tasks spent waiting in the queue before a worker picked them up.", func(s MetricsSnapshot) HistogramSnapshot { return s.QueueWait }},
+	{"workerpool_task_duration_seconds", "Total run time of a task across all attempts, excluding retry back-off.", func(s MetricsSnapshot) HistogramSnapshot { return s.RunDuration }},
+	{"workerpool_task_attempts", "Number of attempts made per finished task.", func(s MetricsSnapshot) HistogramSnapshot { return s.Attempts }},
+}
+
+// writeMetrics 按指标族分组输出所有 Pool 的指标。
+func writeMetrics(w io.Writer, openMetrics bool, sources []MetricsSource) error {
+	bw := bufio.NewWriter(w)
+
+	snaps := make([]MetricsSnapshot, len(sources))
+	labels := make([]string, len(sources))
+	for i, src := range sources {
+		snaps[i] = src.Metrics()
+		labels[i] = `pool="` + escapeLabelValue(src.Name()) + `"`
+	}
+
+	for _, d := range poolMetricDescs {
+		family, sample := d.name, d.name
+		if d.typ == "counter" {
+			// Prometheus 文本格式中计数器族名本身带 _total；OpenMetrics 中族名不带、样本带
+			sample += "_total"
+			if !openMetrics {
+				family = sample
+			}
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", family, d.help, family, d.typ)
+		for i := range sources {
+			if d.float != nil {
+				fmt.Fprintf(bw, "%s{%s} %s\n", sample, labels[i], formatFloat(d.float(snaps[i])))
+				continue
+			}
+			fmt.Fprintf(bw, "%s{%s} %d\n", sample, labels[i], d.value(snaps[i]))
+		}
+	}
+
+	for _, d := range poolHistogramDescs {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s histogram\n", d.name, d.help, d.name)
+		for i := range sources {
+			h := d.value(snaps[i])
+			for j, bound := range h.Bounds {
+				fmt.Fprintf(bw, "%s_bucket{%s,le=\"%s\"} %d\n", d.name, labels[i], formatFloat(bound), h.Counts[j])
+			}
+			fmt.Fprintf(bw, "%s_bucket{%s,le=\"+Inf\"} %d\n", d.name, labels[i], h.Count)
+			fmt.Fprintf(bw, "%s_sum{%s} %s\n", d.name, labels[i], formatFloat(h.Sum))
+			fmt.Fprintf(bw, "%s_count{%s} %d\n", d.name, labels[i], h.Count)
+		}
+	}
+
+	if openMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+// escapeLabelValue 按 Prometheus 规则转义标签值中的反斜杠、双引号和换行。
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatFloat 以最短且无损的形式格式化浮点数。
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,275 @@
+package tools
+
+// Pipeline 在 WorkerPool 之上提供多阶段流水线（如 抓取 → 解析 → 入库）。
+//
+// 核心特性：
+//   - 类型安全：每个阶段的输入输出类型独立，由 AddStage 的类型参数推导
+//   - 独立并发：每个阶段拥有自己的 WorkerPool，Worker 数、超时、重试等均可单独配置
+//   - 背压控制：每个阶段最多同时容纳 QueueSize 个数据，下游处理不过来时上游自动阻塞
+//   - 有序输出：可选按输入顺序输出结果，或按完成顺序输出以获得更低延迟
+//   - 优雅停止：输入 channel 关闭后逐级排空每个阶段，全部处理完毕再关闭输出
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// PipelineResult 是流水线最终输出的单条结果。
+type PipelineResult[T any] struct {
+	// Seq 是数据在输入 channel 中的序号（从 0 开始），可用于与输入对应。
+	Seq int64
+
+	// Value 是最后一个阶段的输出值；Err != nil 时为零值。
+	Value T
+
+	// Err 是数据在某个阶段失败的错误，类型为 *PipelineError。
+	// 数据在某阶段失败后，后续阶段不会再处理它，而是直接把错误传递到输出。
+	Err error
+}
+
+// PipelineError 记录数据在哪个阶段失败。
+type PipelineError struct {
+	Stage string // 失败的阶段名称
+	Seq   int64  // 数据的输入序号
+	Err   error  // 阶段返回的原始错误
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("pipeline: stage %q item %d: %v", e.Stage, e.Seq, e.Err)
+}
+
+// Unwrap 支持 errors.Is / errors.As 访问原始错误。
+func (e *PipelineError) Unwrap() error { return e.Err }
+
+// pipelineCore 是同一条流水线所有阶段共享的状态。
+type pipelineCore struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	ordered bool           // 是否按输入顺序输出
+	wg      sync.WaitGroup // 等待所有阶段退出
+}
+
+// Pipeline 表示流水线当前末端的输出，T 为末端数据类型。
+//
+// 每调用一次 AddStage 都会返回一个新的 Pipeline（类型可能不同），
+// 但它们共享同一个 ctx 和生命周期，对任意一个调用 Stop/Wait 效果相同。
+//
+// 使用示例：
+//
+//	src := make(chan string)
+//	p0 := NewPipeline(ctx, src, true)
+//	p1 := AddStage(p0, "fetch", Options{Workers: 8, QueueSize: 32}, fetch) // string -> []byte
+//	p2 := AddStage(p1, "parse", Options{Workers: 2, QueueSize: 32}, parse) // []byte -> Item
+//	go func() { for _, u := range urls { src <- u }; close(src) }()
+//	for r := range p2.Results() { ... }
+type Pipeline[T any] struct {
+	core *pipelineCore
+	out  <-chan PipelineResult[T]
+}
+
+// NewPipeline 以 input 为数据源创建流水线。
+//
+// ordered=true 时所有阶段按输入顺序输出结果（会为乱序完成的数据做有限缓冲）；
+// ordered=false 时按完成顺序输出。
+//
+// 关闭 input 即表示数据已全部提交，流水线会在处理完所有数据后关闭 Results()。
+// ctx 取消或调用 Stop() 会立即终止所有阶段，未处理的数据被丢弃。
+func NewPipeline[In any](ctx context.Context, input <-chan In, ordered bool) *Pipeline[In] {
+	ctx, cancel := context.WithCancel(ctx)
+	core := &pipelineCore{ctx: ctx, cancel: cancel, ordered: ordered}
+
+	out := make(chan PipelineResult[In])
+	core.wg.Add(1)
+	go func() {
+		defer core.wg.Done()
+		defer close(out)
+
+		var seq int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-input:
+				if !ok {
+					return
+				}
+				select {
+				case out <- PipelineResult[In]{Seq: seq, Value: v}:
+					seq++
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return &Pipeline[In]{core: core, out: out}
+}
+
+// AddStage 在流水线末端追加一个阶段，fn 把 In 转换为 Out。
+//
+// opts 用于创建该阶段专属的 WorkerPool：
+//   - Workers / MaxWorkers 控制该阶段的并发度
+//   - QueueSize 是该阶段最多同时容纳的数据量（排队 + 执行中 + 等待下游），即背压缓冲大小
+//   - TaskTimeout / MaxRetries / RetryDelay / RateLimit 等按单条数据生效
+//
+// name 用于错误信息和日志，建议每个阶段唯一。
+func AddStage[In, Out any](p *Pipeline[In], name string, opts Options, fn func(ctx context.Context, in In) (Out, error)) *Pipeline[Out] {
+	core := p.core
+	pool := NewPool[Out](opts)
+	capacity := cap(pool.queue) // setDefaults 之后的实际队列容量
+
+	// tokens 限制本阶段同时持有的数据量：入队前获取，发往下游后释放。
+	// 由于持有量不超过队列容量，SubmitWithResult 永远不会因队列满而失败。
+	tokens := make(chan struct{}, capacity)
+	resCh := make(chan Result[Out], capacity)
+	fed := make(chan int64, 1) // feeder 结束时发送已接收的数据总量
+	out := make(chan PipelineResult[Out])
+
+	// ---- feeder：从上游读取数据并提交到本阶段的 Pool ----
+	core.wg.Add(1)
+	go func() {
+		defer core.wg.Done()
+
+		var total int64
+		defer func() { fed <- total }()
+
+		for item := range p.out {
+			select {
+			case tokens <- struct{}{}:
+			case <-core.ctx.Done():
+				return
+			}
+			total++
+
+			id := strconv.FormatInt(item.Seq, 10)
+			if item.Err != nil {
+				// 上游已失败的数据直接透传，不再执行本阶段
+				resCh <- Result[Out]{TaskID: id, Err: item.Err}
+				continue
+			}
+
+			task := &stageTask[In, Out]{id: id, in: item.Value, fn: fn}
+			if err := pool.SubmitWithResult(task, resCh); err != nil {
+				resCh <- Result[Out]{TaskID: id, Err: err}
+			}
+		}
+	}()
+
+	// ---- emitter：把本阶段结果发往下游（可选重排序） ----
+	core.wg.Add(1)
+	go func() {
+		defer core.wg.Done()
+		defer close(out)
+
+		var emitted int64
+		total := int64(-1) // feeder 结束前未知
+		var next int64     // ordered 模式下期望输出的下一个序号
+		buffered := make(map[int64]PipelineResult[Out])
+
+		emit := func(r PipelineResult[Out]) bool {
+			select {
+			case out <- r:
+				<-tokens
+				emitted++
+				return true
+			case <-core.ctx.Done():
+				return false
+			}
+		}
+
+		for total < 0 || emitted < total {
+			select {
+			case n := <-fed:
+				total = n
+
+			case r := <-resCh:
+				seq, _ := strconv.ParseInt(r.TaskID, 10, 64)
+				item := PipelineResult[Out]{Seq: seq, Value: r.Value, Err: r.Err}
+				var pe *PipelineError
+				if item.Err != nil && !errors.As(item.Err, &pe) {
+					item.Err = &PipelineError{Stage: name, Seq: seq, Err: item.Err}
+				}
+
+				if !core.ordered {
+					if !emit(item) {
+						pool.Stop()
+						return
+					}
+					continue
+				}
+
+				// 有序模式：先缓存，再把连续的序号依次输出
+				buffered[seq] = item
+				for {
+					ready, ok := buffered[next]
+					if !ok {
+						break
+					}
+					delete(buffered, next)
+					if !emit(ready) {
+						pool.Stop()
+						return
+					}
+					next++
+				}
+
+			case <-core.ctx.Done():
+				pool.Stop()
+				return
+			}
+		}
+
+		// 所有数据均已发往下游，本阶段 Pool 已无任务，优雅关闭
+		pool.StopGraceful()
+	}()
+
+	return &Pipeline[Out]{core: core, out: out}
+}
+
+// Results 返回流水线末端的结果 channel。
+// 所有数据处理完毕（或流水线被停止）后 channel 会被关闭。
+//
+// 调用方必须持续消费此 channel，否则背压会逐级传递，最终阻塞数据源。
+func (p *Pipeline[T]) Results() <-chan PipelineResult[T] {
+	return p.out
+}
+
+// Collect 消费全部结果并以切片形式返回，阻塞直到流水线结束。
+// ordered=true 时切片顺序即输入顺序。
+func (p *Pipeline[T]) Collect() []PipelineResult[T] {
+	var results []PipelineResult[T]
+	for r := range p.out {
+		results = append(results, r)
+	}
+	return results
+}
+
+// Stop 立即终止流水线：所有阶段停止读取数据，各阶段 Pool 调用 Stop()，Results() 随后关闭。
+func (p *Pipeline[T]) Stop() {
+	p.core.cancel()
+}
+
+// Wait 阻塞等待所有阶段的内部 goroutine 退出。
+// 通常在 Results() 被读完或调用 Stop() 之后使用，确保资源已全部释放。
+func (p *Pipeline[T]) Wait() {
+	p.core.wg.Wait()
+	p.core.cancel() // 释放 ctx 资源
+}
+
+// stageTask 将单条数据和阶段函数适配为 Task，供阶段 Pool 执行。
+// TaskID 即数据的输入序号，emitter 据此恢复顺序。
+type stageTask[In, Out any] struct {
+	id string
+	in In
+	fn func(ctx context.Context, in In) (Out, error)
+}
+
+func (t *stageTask[In, Out]) TaskID() string { return t.id }
+
+func (t *stageTask[In, Out]) Run(ctx context.Context) (Out, error) {
+	return t.fn(ctx, t.in)
+}
@@ -0,0 +1,285 @@
+package tools
+
+// 限速与按 key 限流。
+//
+// 核心特性：
+//   - 令牌桶：按固定速率补充令牌，桶容量即允许的突发数量，空闲期间积累的令牌不会丢失
+//   - 按 key 限并发：实现了 Keyed 接口的任务按 key 分组，同一 key 同时执行的任务数有上限；
+//     达到上限的任务会被暂存，Worker 立即去处理其他任务，一个慢域名不会占满整个 Pool
+//   - 按 key 限速：每个 key 拥有独立的令牌桶
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// TokenBucket：令牌桶
+// =============================================================================
+
+// TokenBucket 是并发安全的令牌桶限速器。
+//
+// 使用示例：
+//
+//	b := NewTokenBucket(10, 5) // 每秒 10 个，最多突发 5 个
+//	if err := b.Wait(ctx); err != nil { return err }
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64   // 每秒补充的令牌数
+	burst  float64   // 桶容量
+	tokens float64   // 当前令牌数，可为负数（表示已被预订的等待者）
+	last   time.Time // 上次结算令牌的时间
+}
+
+// NewTokenBucket 创建一个每秒补充 rate 个令牌、容量为 burst 的令牌桶，初始为满桶。
+// burst < 1 时按 1 处理。
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// advance 按经过的时间补充令牌。调用方必须持有 b.mu。
+func (b *TokenBucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// Allow 尝试立即取走一个令牌，成功返回 true，不会阻塞。
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
+}
+
+// Wait 阻塞直到取得一个令牌，或 ctx 被取消（此时返回 context.Cause(ctx)，预订的令牌会被归还）。
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.advance(time.Now())
+	b.tokens-- // 先预订，令牌不足时按欠额计算等待时间
+	deficit := -b.tokens
+	rate := b.rate
+	b.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(deficit / rate * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.advance(time.Now())
+		b.tokens = min(b.burst, b.tokens+1) // 归还预订
+		b.mu.Unlock()
+		return context.Cause(ctx)
+	}
+}
+
+// SetRate 修改令牌补充速率，已积累的令牌保留。rate 必须大于 0。
+// 已在 Wait 中等待的调用方仍按旧速率计算的时间醒来。
+func (b *TokenBucket) SetRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+	b.rate = rate
+}
+
+// Rate 返回当前的令牌补充速率（每秒）。
+func (b *TokenBucket) Rate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
+
+// full 判断桶是否已满（长时间未使用）。
+func (b *TokenBucket) full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+	return b.tokens >= b.burst
+}
+
+// =============================================================================
+// Keyed：按 key 限流
+// =============================================================================
+
+// Keyed 是任务可选实现的接口，返回用于分组限流的 key（例如目标主机名）。
+// 未实现该接口或返回空字符串的任务不受按 key 限制约束。
+//
+// 使用示例：
+//
+//	func (t *FetchTask) TaskKey() string { return t.URL.Host }
+type Keyed interface {
+	TaskKey() string
+}
+
+// keyState 是单个 key 的限流状态。
+type keyState[T any] struct {
+	active  int          // 正在执行的任务数
+	pending []job[T]     // 因并发已满而暂存的任务，按到达顺序执行
+	bucket  *TokenBucket // 按 key 限速的令牌桶，未配置 KeyRateLimit 时为 nil
+}
+
+// keyLimiter 管理所有 key 的限流状态。
+type keyLimiter[T any] struct {
+	mu          sync.Mutex
+	keys        map[string]*keyState[T]
+	concurrency int     // 每个 key 的最大并发数，0 表示不限制
+	rate        float64 // 每个 key 的每秒启动任务数，0 表示不限速
+	burst       int     // 每个 key 的令牌桶容量
+}
+
+// newKeyLimiter 根据配置创建 keyLimiter；未配置任何按 key 限制时返回 nil。
+func newKeyLimiter[T any](opts Options) *keyLimiter[T] {
+	if opts.KeyConcurrency <= 0 && opts.KeyRateLimit <= 0 {
+		return nil
+	}
+	return &keyLimiter[T]{
+		keys:        make(map[string]*keyState[T]),
+		concurrency: opts.KeyConcurrency,
+		rate:        opts.KeyRateLimit,
+		burst:       opts.KeyRateBurst,
+	}
+}
+
+// acquire 为任务占用一个 key 并发名额。名额已满时任务被暂存并返回 false，
+// 稍后由同一 key 的任务在 release 时取出执行。
+func (l *keyLimiter[T]) acquire(key string, j job[T]) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st, ok := l.keys[key]
+	if !ok {
+		st = &keyState[T]{}
+		if l.rate > 0 {
+			st.bucket = NewTokenBucket(l.rate, l.burst)
+		}
+		l.keys[key] = st
+	}
+	if l.concurrency > 0 && st.active >= l.concurrency {
+		st.pending = append(st.pending, j)
+		return false
+	}
+	st.active++
+	return true
+}
+
+// release 归还一个 key 并发名额。若该 key 有暂存任务，则把名额直接转交给最早的一个并返回它。
+func (l *keyLimiter[T]) release(key string) (job[T], bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.keys[key]
+	if len(st.pending) > 0 {
+		next := st.pending[0]
+		st.pending[0] = job[T]{}
+		st.pending = st.pending[1:]
+		return next, true // active 不变：名额转交
+	}
+	st.active--
+	if st.active == 0 && (st.bucket == nil || st.bucket.full()) {
+		// 空闲且令牌桶已回满，删除状态避免 key 无限增长
+		delete(l.keys, key)
+	}
+	return job[T]{}, false
+}
+
+// bucket 返回 key 的令牌桶，未配置按 key 限速时返回 nil。调用方必须已通过 acquire 占用名额。
+func (l *keyLimiter[T]) bucket(key string) *TokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.keys[key].bucket
+}
+
+// =============================================================================
+// WorkerPool 集成
+// =============================================================================
+
+// taskKey 返回任务的限流 key；未启用按 key 限流或任务未实现 Keyed 时返回空字符串。
+func (p *WorkerPool[T]) taskKey(task Task[T]) string {
+	if p.keys == nil {
+		return ""
+	}
+	if k, ok := task.(Keyed); ok {
+		return k.TaskKey()
+	}
+	return ""
+}
+
+// dispatch 处理 Worker 取到的任务：按 key 占用并发名额后执行，
+// 执行完毕后若同一 key 有暂存任务则接着执行，名额已满时暂存任务并立即返回。
+// Pool 暂停时不再接力，暂存任务放回队列，恢复后由 Worker 重新取走。
+func (p *WorkerPool[T]) dispatch(j job[T]) {
+	key := p.taskKey(j.task)
+	if key == "" {
+		p.runJob(j, "")
+		return
+	}
+	if !p.keys.acquire(key, j) {
+		return // 已暂存，由该 key 正在执行的任务结束后接力执行
+	}
+	p.runJob(j, key)
+	for {
+		next, ok := p.keys.release(key)
+		if !ok {
+			return
+		}
+		// 放回队列后转交来的名额随之归还，继续处理下一个暂存任务
+		if p.pause.isPaused() && p.requeue(next) {
+			continue
+		}
+		p.runJob(next, key)
+	}
+}
+
+// requeue 把暂存任务非阻塞地放回队列，队列已满或已关闭时返回 false，由调用方直接执行。
+// 任务仍计入 QueueDepth，无需重新计数。
+func (p *WorkerPool[T]) requeue(j job[T]) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.queueClosed {
+		return false
+	}
+	select {
+	case p.queue <- j:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitRate 依次等待按 key 限速和全局限速的令牌，任务被取消时提前返回。
+func (p *WorkerPool[T]) waitRate(ctx context.Context, key string) {
+	if key != "" {
+		if b := p.keys.bucket(key); b != nil {
+			if b.Wait(ctx) != nil {
+				return
+			}
+		}
+	}
+	if p.rateLimiter != nil {
+		_ = p.rateLimiter.Wait(ctx)
+	}
+}
@@ -0,0 +1,953 @@
+package tools
+
+// 分布式模式：Coordinator 通过 HTTP 对外提供任务队列，多个进程中的 RemoteWorker 租用任务执行并回报结果。
+//
+// 核心特性：
+//   - 租约 + 可见性超时：任务被租出后对其他 Worker 不可见，超过 VisibilityTimeout 未回报即重新入队
+//   - 心跳续约：RemoteWorker 定期为执行中的任务续约，长任务不会被误判为超时；
+//     任务在 Coordinator 端被取消或租约已失效时，心跳会通知 Worker 取消本地执行
+//   - 任务编解码：任务类型通过 TaskRegistry 注册，按类型名 + JSON 在网络上传输，结果值同样以 JSON 编码
+//   - 复用本地 Pool：RemoteWorker 在内部 WorkerPool 中执行任务，超时、重试、限速、熔断、panic 隔离均照常生效
+//   - 至少一次语义：Worker 崩溃或回报失败时任务会被重新租出，任务实现应保证幂等
+//   - 请求体大小有上限（CoordinatorOptions.MaxRequestBytes）
+//
+// 协议（JSON over HTTP，均为 POST，路径相对于 Coordinator 的挂载点）：
+//   - /lease：长轮询租用最多 max 个任务
+//   - /heartbeat：为执行中的租约续期，返回已失效的租约
+//   - /complete：回报任务结果
+//
+// 多进程部署示例：
+//
+//	// 协调进程
+//	reg := NewTaskRegistry[string]()
+//	reg.Register("crawl", func() Task[string] { return &CrawlTask{} })
+//	coord := NewCoordinator[string](reg, CoordinatorOptions{VisibilityTimeout: time.Minute})
+//	go http.ListenAndServe(":9000", coord)
+//	f, _ := coord.Submit(&CrawlTask{ID: "1", URL: "https://example.com"})
+//
+//	// 每台执行机（可启动任意多个进程）
+//	w := NewRemoteWorker[string]("http://coordinator:9000", reg, RemoteWorkerOptions{Options: Options{Workers: 8}})
+//	log.Fatal(w.Run(ctx))
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLeaseExpired 表示任务多次租出后均未在可见性超时内回报，已放弃执行，可用 errors.Is 判断。
+var ErrLeaseExpired = errors.New("workerpool: remote lease expired")
+
+// RemoteError 是远程 Worker 回报的任务错误。错误跨进程传输后只保留文本，
+// 无法再用 errors.Is 匹配原始错误。
+type RemoteError struct {
+	Worker string // 执行任务的 RemoteWorker ID
+	Msg    string // 原始错误的 Error() 文本
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("remote worker %q: %s", e.Worker, e.Msg)
+}
+
+// =============================================================================
+// 任务编解码
+// =============================================================================
+
+// TaskCodec 把任务编码为可在网络上传输的类型名和数据，并在 RemoteWorker 端还原。
+// Coordinator 与 RemoteWorker 必须使用兼容的 TaskCodec。
+type TaskCodec[T any] interface {
+	Encode(task Task[T]) (typ string, payload []byte, err error)
+	Decode(typ string, payload []byte) (Task[T], error)
+}
+
+// TaskRegistry 是基于 JSON 的 TaskCodec：按类型名注册任务构造函数，任务以 JSON 编码传输。
+//
+// 任务需要在网络上传输的字段必须是导出字段（或实现 json.Marshaler/json.Unmarshaler），
+// 不可导出的字段在 RemoteWorker 端为零值。
+type TaskRegistry[T any] struct {
+	mu        sync.RWMutex
+	factories map[string]func() Task[T]
+	names     map[reflect.Type]string
+}
+
+// NewTaskRegistry 创建一个空的 TaskRegistry。
+func NewTaskRegistry[T any]() *TaskRegistry[T] {
+	return &TaskRegistry[T]{
+		factories: make(map[string]func() Task[T]),
+		names:     make(map[reflect.Type]string),
+	}
+}
+
+// Register 注册一种任务类型。newTask 必须每次返回一个新的指针类型零值任务，用于 JSON 解码。
+// 类型名或任务类型重复注册时 panic（与 http.Handle 一致，属于编程错误）。
+func (r *TaskRegistry[T]) Register(name string, newTask func() Task[T]) {
+	typ := reflect.TypeOf(newTask())
+	if typ == nil || typ.Kind() != reflect.Pointer {
+		panic(fmt.Sprintf("workerpool: task type %q must be a pointer, got %v", name, typ))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[name]; ok {
+		panic(fmt.Sprintf("workerpool: task type %q registered twice", name))
+	}
+	if old, ok := r.names[typ]; ok {
+		panic(fmt.Sprintf("workerpool: %v already registered as %q", typ, old))
+	}
+	r.factories[name] = newTask
+	r.names[typ] = name
+}
+
+// Encode 实现 TaskCodec。
+func (r *TaskRegistry[T]) Encode(task Task[T]) (string, []byte, error) {
+	r.mu.RLock()
+	name, ok := r.names[reflect.TypeOf(task)]
+	r.mu.RUnlock()
+	if !ok {
+		return "", nil, fmt.Errorf("workerpool: task type %T is not registered", task)
+	}
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return "", nil, fmt.Errorf("workerpool: encode task %q: %w", task.TaskID(), err)
+	}
+	return name, payload, nil
+}
+
+// Decode 实现 TaskCodec。
+func (r *TaskRegistry[T]) Decode(typ string, payload []byte) (Task[T], error) {
+	r.mu.RLock()
+	newTask, ok := r.factories[typ]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("workerpool: unknown task type %q", typ)
+	}
+	task := newTask()
+	if err := json.Unmarshal(payload, task); err != nil {
+		return nil, fmt.Errorf("workerpool: decode task type %q: %w", typ, err)
+	}
+	return task, nil
+}
+
+// =============================================================================
+// 协议报文
+// =============================================================================
+
+type leaseRequest struct {
+	Worker string `json:"worker"`
+	Max    int    `json:"max"`     // 最多租用的任务数
+	WaitMs int64  `json:"wait_ms"` // 没有任务时最多等待的毫秒数
+}
+
+type wireLease struct {
+	LeaseID      string `json:"lease_id"`
+	TaskID       string `json:"task_id"`
+	Type         string `json:"type"`
+	Payload      []byte `json:"payload"`
+	Attempt      int    `json:"attempt"`       // 第几次租出（从 1 开始）
+	VisibilityMs int64  `json:"visibility_ms"` // 租约有效期，需在此之前心跳或回报
+}
+
+type leaseResponse struct {
+	Leases []wireLease `json:"leases"`
+}
+
+type heartbeatRequest struct {
+	Worker   string   `json:"worker"`
+	LeaseIDs []string `json:"lease_ids"`
+}
+
+type heartbeatResponse struct {
+	Lost []string `json:"lost"` // 已失效的租约（过期重新入队，或任务已被取消）
+}
+
+type completeRequest struct {
+	Worker   string          `json:"worker"`
+	LeaseID  string          `json:"lease_id"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Err      string          `json:"error,omitempty"`
+	Attempts int             `json:"attempts"`    // Worker 本地执行次数（含本地重试）
+	Duration int64           `json:"duration_ns"` // Worker 本地执行耗时
+}
+
+// =============================================================================
+// Coordinator
+// =============================================================================
+
+// CoordinatorOptions 配置 Coordinator。
+type CoordinatorOptions struct {
+	// Name 是 Coordinator 名称，用作租约 ID 前缀和日志标识。
+	// 默认值："default"。
+	Name string
+
+	// QueueSize 是等待租出的任务上限，超过时 Submit 返回错误。
+	// 默认值：1024。
+	QueueSize int
+
+	// VisibilityTimeout 是租约有效期：租出后超过此时间既未心跳也未回报，任务重新入队。
+	// 默认值：30s。
+	VisibilityTimeout time.Duration
+
+	// MaxLeases 是单个任务最多被租出的次数，租约连续过期达到此次数后以 ErrLeaseExpired 失败。
+	// 远程执行返回的错误不会在此重试（Worker 本地已按其 MaxRetries 重试）。
+	// 默认值：3。
+	MaxLeases int
+
+	// MaxLeaseWait 是 /lease 长轮询的最长等待时间，Worker 请求的等待时间超过时按此截断。
+	// 默认值：30s。
+	MaxLeaseWait time.Duration
+
+	// MaxRequestBytes 是单个请求体的最大字节数（/complete 携带任务结果），超过时返回 413。
+	// 默认值：8 MiB。
+	MaxRequestBytes int64
+
+	// Logger 是日志函数。默认值：log.Printf。
+	Logger func(format string, args ...any)
+}
+
+// setDefaults 为未设置的字段填充默认值。
+func (o *CoordinatorOptions) setDefaults() {
+	if o.Name == "" {
+		o.Name = "default"
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	if o.VisibilityTimeout <= 0 {
+		o.VisibilityTimeout = 30 * time.Second
+	}
+	if o.MaxLeases <= 0 {
+		o.MaxLeases = 3
+	}
+	if o.MaxLeaseWait <= 0 {
+		o.MaxLeaseWait = 30 * time.Second
+	}
+	if o.MaxRequestBytes <= 0 {
+		o.MaxRequestBytes = 8 << 20
+	}
+	if o.Logger == nil {
+		o.Logger = log.Printf
+	}
+}
+
+// remoteJob 是 Coordinator 中的一个任务。
+type remoteJob[T any] struct {
+	task       Task[T]
+	typ        string
+	payload    []byte
+	fut        *Future[T]
+	enqueuedAt time.Time
+	leases     int    // 已租出次数
+	leaseID    string // 当前租约，未租出时为空
+	worker     string // 当前租约的持有者
+	deadline   time.Time
+	finished   bool
+	stopWatch  func() bool // 停止监听 fut.ctx 的取消
+}
+
+// Coordinator 持有任务队列，并通过 HTTP（实现 http.Handler）把任务租给 RemoteWorker。
+//
+// 可直接作为 http.Server 的 Handler，也可用 http.StripPrefix 挂载到已有路由下。
+type Coordinator[T any] struct {
+	opts  CoordinatorOptions
+	codec TaskCodec[T]
+	mux   *http.ServeMux
+
+	ctx    context.Context // Stop() 时取消，所有任务的 Future 均从它派生
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool                     // 不再接受提交；Stop() 后也不再租出任务
+	pending []*remoteJob[T]          // 等待租出的任务，FIFO
+	leased  map[string]*remoteJob[T] // 租出中的任务，key 为租约 ID
+	notify  chan struct{}            // 有新任务入队时关闭并替换，唤醒长轮询
+	seen    map[string]time.Time     // RemoteWorker 最近一次请求时间，用于统计在线 Worker 数
+
+	active  *activeCounter // 尚未结束的任务数，StopGracefulContext 等待其归零
+	seq     atomic.Int64   // 租约序号
+	metrics Metrics
+	wg      sync.WaitGroup // 等待过期检查 goroutine
+}
+
+// NewCoordinator 创建并启动一个 Coordinator。使用完毕后务必调用 Stop() 或 StopGraceful()。
+func NewCoordinator[T any](codec TaskCodec[T], opts CoordinatorOptions) *Coordinator[T] {
+	opts.setDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Coordinator[T]{
+		opts:   opts,
+		codec:  codec,
+		mux:    http.NewServeMux(),
+		ctx:    ctx,
+		cancel: cancel,
+		leased: make(map[string]*remoteJob[T]),
+		notify: make(chan struct{}),
+		seen:   make(map[string]time.Time),
+		active: newActiveCounter(),
+	}
+	c.metrics.QueueWait = NewHistogram(DefaultLatencyBuckets)
+	c.metrics.RunDuration = NewHistogram(DefaultLatencyBuckets)
+	c.metrics.Attempts = NewHistogram(DefaultAttemptBuckets)
+
+	c.mux.HandleFunc("POST /lease", c.handleLease)
+	c.mux.HandleFunc("POST /heartbeat", c.handleHeartbeat)
+	c.mux.HandleFunc("POST /complete", c.handleComplete)
+
+	c.wg.Add(1)
+	go c.reaper()
+	return c
+}
+
+// ServeHTTP 实现 http.Handler。
+func (c *Coordinator[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, c.opts.MaxRequestBytes)
+	c.mux.ServeHTTP(w, r)
+}
+
+// Submit 编码任务并入队等待 RemoteWorker 租用，返回任务的 Future。
+// 任务类型未注册、Coordinator 已停止或队列已满时返回错误。
+func (c *Coordinator[T]) Submit(task Task[T]) (*Future[T], error) {
+	typ, payload, err := c.codec.Encode(task)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return nil, errors.New("workerpool: coordinator is stopped")
+	}
+	if len(c.pending) >= c.opts.QueueSize {
+		return nil, fmt.Errorf("workerpool: queue full (capacity %d)", c.opts.QueueSize)
+	}
+
+	j := &remoteJob[T]{
+		task:       task,
+		typ:        typ,
+		payload:    payload,
+		fut:        newFuture[T](c.ctx, task.TaskID()),
+		enqueuedAt: time.Now(),
+	}
+	// Future 被取消（或 Coordinator 停止）时立即结束任务，不必等到被租出
+	j.stopWatch = context.AfterFunc(j.fut.ctx, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.finish(j, Result[T]{TaskID: task.TaskID(), Attempts: j.leases, Err: context.Cause(j.fut.ctx)})
+	})
+
+	c.active.add(1)
+	c.metrics.Submitted.Add(1)
+	c.push(j)
+	return j.fut, nil
+}
+
+// Metrics 返回实时指标快照：QueueDepth 为等待租出的任务数，InFlight 为租出中的任务数，
+// Workers 为最近一个 VisibilityTimeout 内有过请求的 RemoteWorker 数，Retried 为租约过期后重新入队的次数。
+func (c *Coordinator[T]) Metrics() MetricsSnapshot {
+	s := c.metrics.Snapshot()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cutoff := time.Now().Add(-c.opts.VisibilityTimeout)
+	for id, t := range c.seen {
+		if t.Before(cutoff) {
+			delete(c.seen, id)
+		}
+	}
+	s.Workers = int64(len(c.seen))
+	return s
+}
+
+// Stop 立即停止：不再接受提交和租用，所有未结束任务的 Future 以取消错误结束，
+// 租出中的任务会在下次心跳时被 RemoteWorker 取消。不等待，立即返回。重复调用无副作用。
+func (c *Coordinator[T]) Stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+
+	c.cancel() // 触发每个任务的 AfterFunc，以取消错误结束
+	c.wg.Wait()
+}
+
+// StopGraceful 优雅停止：不再接受提交，等待所有已提交任务被远程执行完毕后再停止。
+// 若没有任何 RemoteWorker 在线，会一直等待；需要限定等待时间时请使用 StopGracefulContext。
+func (c *Coordinator[T]) StopGraceful() {
+	_ = c.StopGracefulContext(context.Background())
+}
+
+// StopGracefulContext 与 StopGraceful 相同，但最多等待到 ctx 结束：
+// 到期后调用 Stop() 取消剩余任务并返回 context.Cause(ctx)；在此之前全部完成则返回 nil。
+func (c *Coordinator[T]) StopGracefulContext(ctx context.Context) error {
+	c.mu.Lock()
+	c.stopped = true // 禁止新的 Submit，已入队的任务仍可被租出
+	c.mu.Unlock()
+
+	select {
+	case <-c.active.done():
+		c.Stop()
+		return nil
+	case <-ctx.Done():
+		c.opts.Logger("[coordinator %s] 优雅停止超时，强制停止（未完成任务 = %d）", c.opts.Name, c.active.load())
+		c.Stop()
+		return context.Cause(ctx)
+	}
+}
+
+// push 把任务放到队尾并唤醒长轮询。调用方必须持有 c.mu。
+func (c *Coordinator[T]) push(j *remoteJob[T]) {
+	c.pending = append(c.pending, j)
+	c.metrics.QueueDepth.Add(1)
+	close(c.notify)
+	c.notify = make(chan struct{})
+}
+
+// finish 记录任务最终结果，从队列或租约中移除并完成 Future。调用方必须持有 c.mu。
+// 任务已结束时直接返回，保证每个任务只完成一次。
+func (c *Coordinator[T]) finish(j *remoteJob[T], r Result[T]) {
+	if j.finished {
+		return
+	}
+	j.finished = true
+	j.stopWatch()
+
+	if j.leaseID != "" {
+		delete(c.leased, j.leaseID)
+		c.metrics.InFlight.Add(-1)
+		j.leaseID = ""
+	} else if i := slices.Index(c.pending, j); i >= 0 {
+		c.pending = slices.Delete(c.pending, i, i+1)
+		c.metrics.QueueDepth.Add(-1)
+	}
+
+	switch {
+	case errors.Is(r.Err, ErrTaskCancelled) || errors.Is(r.Err, context.Canceled):
+		c.metrics.Cancelled.Add(1)
+	case r.Err == nil:
+		c.metrics.Succeeded.Add(1)
+	default:
+		c.metrics.Failed.Add(1)
+	}
+	if r.Attempts > 0 {
+		c.metrics.Attempts.Observe(float64(r.Attempts))
+	}
+
+	j.fut.complete(r)
+	c.active.add(-1)
+}
+
+// reaper 定期检查过期租约：未达 MaxLeases 的任务重新放回队首，否则以 ErrLeaseExpired 失败。
+func (c *Coordinator[T]) reaper() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(max(c.opts.VisibilityTimeout/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.expireLeases(now)
+		}
+	}
+}
+
+// expireLeases 处理截至 now 已过期的租约。
+func (c *Coordinator[T]) expireLeases(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var requeue []*remoteJob[T]
+	for id, j := range c.leased {
+		if now.Before(j.deadline) {
+			continue
+		}
+		c.opts.Logger("[coordinator %s] 任务 %q 的租约 %s 已过期（Worker %q，第 %d 次租出）",
+			c.opts.Name, j.task.TaskID(), id, j.worker, j.leases)
+		if j.leases >= c.opts.MaxLeases {
+			c.finish(j, Result[T]{
+				TaskID:   j.task.TaskID(),
+				Attempts: j.leases,
+				Err:      fmt.Errorf("%w（任务 %q 已租出 %d 次）", ErrLeaseExpired, j.task.TaskID(), j.leases),
+			})
+			continue
+		}
+		delete(c.leased, id)
+		c.metrics.InFlight.Add(-1)
+		c.metrics.Retried.Add(1)
+		j.leaseID, j.worker = "", ""
+		j.fut.setStatus(TaskRetrying)
+		requeue = append(requeue, j)
+	}
+	if len(requeue) == 0 {
+		return
+	}
+
+	// 过期任务已等待较久，优先于新任务租出
+	c.pending = append(requeue, c.pending...)
+	c.metrics.QueueDepth.Add(int64(len(requeue)))
+	close(c.notify)
+	c.notify = make(chan struct{})
+}
+
+// take 从队首取出最多 n 个任务并租给 worker。调用方必须持有 c.mu。
+func (c *Coordinator[T]) take(worker string, n int) []wireLease {
+	n = min(n, len(c.pending))
+	if n == 0 || c.ctx.Err() != nil {
+		return nil
+	}
+
+	now := time.Now()
+	leases := make([]wireLease, 0, n)
+	for _, j := range c.pending[:n] {
+		j.leases++
+		j.leaseID = c.opts.Name + "-" + strconv.FormatInt(c.seq.Add(1), 10)
+		j.worker = worker
+		j.deadline = now.Add(c.opts.VisibilityTimeout)
+		j.fut.setStatus(TaskRunning)
+		c.leased[j.leaseID] = j
+		if j.leases == 1 {
+			c.metrics.QueueWait.Observe(now.Sub(j.enqueuedAt).Seconds())
+		}
+
+		leases = append(leases, wireLease{
+			LeaseID:      j.leaseID,
+			TaskID:       j.task.TaskID(),
+			Type:         j.typ,
+			Payload:      j.payload,
+			Attempt:      j.leases,
+			VisibilityMs: c.opts.VisibilityTimeout.Milliseconds(),
+		})
+	}
+	c.pending = slices.Delete(c.pending, 0, n)
+	c.metrics.QueueDepth.Add(-int64(n))
+	c.metrics.InFlight.Add(int64(n))
+	return leases
+}
+
+func (c *Coordinator[T]) handleLease(w http.ResponseWriter, r *http.Request) {
+	var req leaseRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Max <= 0 {
+		req.Max = 1
+	}
+	wait := min(time.Duration(req.WaitMs)*time.Millisecond, c.opts.MaxLeaseWait)
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	for {
+		c.mu.Lock()
+		c.seen[req.Worker] = time.Now()
+		if c.ctx.Err() != nil || (c.stopped && c.active.load() == 0) {
+			// 已停止（或优雅停止且已无任务）：通知 Worker 退出
+			c.mu.Unlock()
+			http.Error(w, "coordinator is stopped", http.StatusGone)
+			return
+		}
+		leases := c.take(req.Worker, req.Max)
+		notify := c.notify
+		c.mu.Unlock()
+
+		if len(leases) > 0 {
+			writeJSON(w, leaseResponse{Leases: leases})
+			return
+		}
+
+		// 长轮询：等待新任务入队、超时或客户端断开
+		select {
+		case <-notify:
+		case <-deadline.C:
+			writeJSON(w, leaseResponse{})
+			return
+		case <-r.Context().Done():
+			return
+		case <-c.ctx.Done():
+		}
+	}
+}
+
+func (c *Coordinator[T]) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req heartbeatRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.seen[req.Worker] = now
+
+	var resp heartbeatResponse
+	for _, id := range req.LeaseIDs {
+		j, ok := c.leased[id]
+		if !ok || j.worker != req.Worker {
+			resp.Lost = append(resp.Lost, id)
+			continue
+		}
+		j.deadline = now.Add(c.opts.VisibilityTimeout)
+	}
+	writeJSON(w, resp)
+}
+
+func (c *Coordinator[T]) handleComplete(w http.ResponseWriter, r *http.Request) {
+	var req completeRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen[req.Worker] = time.Now()
+
+	j, ok := c.leased[req.LeaseID]
+	if !ok || j.worker != req.Worker {
+		// 租约已过期并重新租出，或任务已被取消：丢弃此结果
+		http.Error(w, "lease is no longer valid", http.StatusConflict)
+		return
+	}
+
+	result := Result[T]{
+		TaskID: j.task.TaskID(),
+		// 之前过期的租约也算作执行过
+		Attempts: j.leases - 1 + max(req.Attempts, 1),
+		Duration: time.Duration(req.Duration),
+	}
+	switch {
+	case req.Err != "":
+		result.Err = &RemoteError{Worker: req.Worker, Msg: req.Err}
+	case len(req.Value) > 0:
+		if err := json.Unmarshal(req.Value, &result.Value); err != nil {
+			result.Err = fmt.Errorf("workerpool: decode result of task %q: %w", result.TaskID, err)
+		}
+	}
+	c.metrics.RunDuration.Observe(result.Duration.Seconds())
+	c.finish(j, result)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRequest 解析 JSON 请求体（大小已由 ServeHTTP 限制），失败时写入 400 或 413 响应并返回 false。
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		if mbe := (*http.MaxBytesError)(nil); errors.As(err, &mbe) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeJSON 以 JSON 写入 200 响应。
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// =============================================================================
+// RemoteWorker
+// =============================================================================
+
+// RemoteWorkerOptions 配置 RemoteWorker。
+type RemoteWorkerOptions struct {
+	// Options 是执行任务的内部 WorkerPool 配置：Workers 为同时执行的任务数（即最多同时持有的租约数），
+	// TaskTimeout/MaxRetries/RateLimit/Breaker 等在本地生效。
+	Options
+
+	// ID 是 Worker 的唯一标识，租约与之绑定。
+	// 默认值："主机名-进程号"。
+	ID string
+
+	// Client 是访问 Coordinator 的 HTTP 客户端，其 Timeout 必须大于 PollWait。
+	// 默认值：无超时的 http.Client（请求由 ctx 控制）。
+	Client *http.Client
+
+	// PollWait 是 /lease 长轮询的等待时间。
+	// 默认值：10s。
+	PollWait time.Duration
+
+	// HeartbeatInterval 是为执行中任务续约的间隔，必须小于 Coordinator 的 VisibilityTimeout。
+	// 默认值：租约有效期的 1/3。
+	HeartbeatInterval time.Duration
+}
+
+// RemoteWorker 从 Coordinator 租用任务，在本地 WorkerPool 中执行并回报结果。
+type RemoteWorker[T any] struct {
+	base  string
+	codec TaskCodec[T]
+	opts  RemoteWorkerOptions
+
+	mu      sync.Mutex
+	running map[string]*Future[T] // 执行中的租约，key 为租约 ID
+
+	visibility atomic.Int64   // 最近一次租约的有效期（纳秒），用于推算心跳间隔
+	hbWake     chan struct{}  // 租约有效期变化时发信号，让心跳循环重新计算间隔
+	slots      chan struct{}  // 任务结束时发信号，唤醒等待空闲名额的租用循环
+	wg         sync.WaitGroup // 等待结果回报 goroutine
+}
+
+// NewRemoteWorker 创建一个 RemoteWorker，baseURL 为 Coordinator 的挂载地址。调用 Run 后开始工作。
+func NewRemoteWorker[T any](baseURL string, codec TaskCodec[T], opts RemoteWorkerOptions) *RemoteWorker[T] {
+	opts.Options.setDefaults()
+	if opts.ID == "" {
+		host, _ := os.Hostname()
+		opts.ID = host + "-" + strconv.Itoa(os.Getpid())
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+	if opts.PollWait <= 0 {
+		opts.PollWait = 10 * time.Second
+	}
+	return &RemoteWorker[T]{
+		base:    strings.TrimSuffix(baseURL, "/"),
+		codec:   codec,
+		opts:    opts,
+		running: make(map[string]*Future[T]),
+		hbWake:  make(chan struct{}, 1),
+		slots:   make(chan struct{}, 1),
+	}
+}
+
+// ID 返回 Worker 的唯一标识。
+func (w *RemoteWorker[T]) ID() string { return w.opts.ID }
+
+// Run 持续租用并执行任务，直到 ctx 结束或 Coordinator 停止，阻塞期间同一个 RemoteWorker 不可重复调用 Run。
+//
+// Coordinator 停止时返回 nil；ctx 结束时取消执行中的任务（不回报，租约过期后由其他 Worker 接手），
+// 返回 context.Cause(ctx)。与 Coordinator 的网络错误会在退避后自动重试。
+func (w *RemoteWorker[T]) Run(ctx context.Context) error {
+	pool := NewPool[T](w.opts.Options)
+	defer pool.Stop()
+
+	hbCtx, stopHeartbeat := context.WithCancel(ctx)
+	defer stopHeartbeat()
+	go w.heartbeatLoop(hbCtx)
+
+	err := w.leaseLoop(ctx, pool)
+	if err == nil {
+		// Coordinator 已停止：等待已租到的任务回报完毕
+		w.wg.Wait()
+		return nil
+	}
+	pool.Stop()
+	w.wg.Wait()
+	return err
+}
+
+// leaseLoop 在有空闲名额时向 Coordinator 租用任务并提交到本地 pool。
+func (w *RemoteWorker[T]) leaseLoop(ctx context.Context, pool *WorkerPool[T]) error {
+	minBackoff := max(w.opts.RetryDelay, 100*time.Millisecond)
+	backoff := minBackoff
+	for {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		w.mu.Lock()
+		free := w.opts.Workers - len(w.running)
+		w.mu.Unlock()
+		if free <= 0 {
+			select {
+			case <-w.slots:
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		var resp leaseResponse
+		status, err := w.call(ctx, "/lease", leaseRequest{Worker: w.opts.ID, Max: free, WaitMs: w.opts.PollWait.Milliseconds()}, &resp)
+		switch {
+		case status == http.StatusGone:
+			return nil
+		case err != nil:
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			w.opts.Logger("[remote-worker %s] 租用任务失败，%s 后重试: %v", w.opts.ID, backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+			}
+			backoff = min(backoff*2, 30*time.Second)
+			continue
+		}
+		backoff = minBackoff
+
+		for _, l := range resp.Leases {
+			vis := int64(time.Duration(l.VisibilityMs) * time.Millisecond)
+			if w.visibility.Swap(vis) != vis {
+				select {
+				case w.hbWake <- struct{}{}:
+				default:
+				}
+			}
+			w.start(pool, l)
+		}
+	}
+}
+
+// start 解码并在本地 pool 中执行一个租约，结束后回报结果。
+func (w *RemoteWorker[T]) start(pool *WorkerPool[T], l wireLease) {
+	task, err := w.codec.Decode(l.Type, l.Payload)
+	var fut *Future[T]
+	if err == nil {
+		fut, err = pool.Submit(task)
+	}
+	if err != nil {
+		w.opts.Logger("[remote-worker %s] 无法执行任务 %q: %v", w.opts.ID, l.TaskID, err)
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.report(l.LeaseID, Result[T]{TaskID: l.TaskID, Err: err, Attempts: 1})
+		}()
+		return
+	}
+
+	w.mu.Lock()
+	w.running[l.LeaseID] = fut
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		r, _ := fut.Wait(context.Background())
+
+		w.mu.Lock()
+		_, valid := w.running[l.LeaseID]
+		delete(w.running, l.LeaseID)
+		w.mu.Unlock()
+		select {
+		case w.slots <- struct{}{}:
+		default:
+		}
+
+		// 租约已失效（心跳告知）或 Worker 正在退出时不回报，任务由 Coordinator 处理
+		if valid && !errors.Is(r.Err, context.Canceled) {
+			w.report(l.LeaseID, r)
+		}
+	}()
+}
+
+// report 把结果回报给 Coordinator，网络错误时重试 3 次。放弃后租约会过期，任务将被重新租出。
+func (w *RemoteWorker[T]) report(leaseID string, r Result[T]) {
+	req := completeRequest{Worker: w.opts.ID, LeaseID: leaseID, Attempts: r.Attempts, Duration: int64(r.Duration)}
+	if r.Err != nil {
+		req.Err = r.Err.Error()
+	} else {
+		v, err := json.Marshal(r.Value)
+		if err != nil {
+			req.Err = fmt.Sprintf("encode result: %v", err)
+		}
+		req.Value = v
+	}
+
+	var err error
+	for attempt := range 3 {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+		var status int
+		status, err = w.call(context.Background(), "/complete", req, nil)
+		if status == http.StatusConflict {
+			w.opts.Logger("[remote-worker %s] 任务 %q 的租约已失效，结果被丢弃", w.opts.ID, r.TaskID)
+			return
+		}
+		if err == nil {
+			return
+		}
+	}
+	w.opts.Logger("[remote-worker %s] 回报任务 %q 结果失败: %v", w.opts.ID, r.TaskID, err)
+}
+
+// heartbeatLoop 定期为执行中的租约续约，并取消 Coordinator 告知已失效的任务。
+func (w *RemoteWorker[T]) heartbeatLoop(ctx context.Context) {
+	for {
+		interval := w.opts.HeartbeatInterval
+		if interval <= 0 {
+			interval = max(time.Duration(w.visibility.Load())/3, 10*time.Millisecond)
+			if w.visibility.Load() == 0 {
+				interval = time.Second // 尚未租到任务，租到后由 hbWake 唤醒
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.hbWake:
+			continue
+		case <-time.After(interval):
+		}
+
+		w.mu.Lock()
+		ids := make([]string, 0, len(w.running))
+		for id := range w.running {
+			ids = append(ids, id)
+		}
+		w.mu.Unlock()
+		if len(ids) == 0 {
+			continue
+		}
+
+		var resp heartbeatResponse
+		if _, err := w.call(ctx, "/heartbeat", heartbeatRequest{Worker: w.opts.ID, LeaseIDs: ids}, &resp); err != nil {
+			if ctx.Err() == nil {
+				w.opts.Logger("[remote-worker %s] 心跳失败: %v", w.opts.ID, err)
+			}
+			continue
+		}
+		for _, id := range resp.Lost {
+			w.mu.Lock()
+			fut, ok := w.running[id]
+			delete(w.running, id)
+			w.mu.Unlock()
+			if ok {
+				w.opts.Logger("[remote-worker %s] 租约 %s 已失效，取消任务 %q", w.opts.ID, id, fut.TaskID())
+				fut.Cancel()
+			}
+		}
+	}
+}
+
+// call 以 JSON POST 调用 Coordinator 接口，out 为 nil 时忽略响应体。
+// 返回 HTTP 状态码；非 2xx 响应以 error 返回，状态码仍可用于判断。
+func (w *RemoteWorker[T]) call(ctx context.Context, path string, in, out any) (int, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.base+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("%s: %s %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("%s: decode response: %w", path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,517 @@
+package tools
+
+// 定时任务：为 WorkerPool 提供延迟执行、指定时间执行和 cron 周期执行能力。
+//
+// 核心特性：
+//   - 延迟执行：SubmitAfter(task, 5*time.Minute, nil)
+//   - 定点执行：SubmitAt(task, time.Date(...), nil)
+//   - 周期执行：SubmitCron("*/5 * * * *", task, CronOptions{}, nil)
+//   - 错过策略：进程休眠、系统卡顿导致触发延迟时，可选择跳过、补一次或全部补跑
+//   - 随机抖动：Jitter 为每次触发增加随机延迟，避免大量任务在同一时刻扎堆
+//   - 管理接口：ScheduledEntries() 列出、CancelScheduled(taskID) 取消已登记的定时任务
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Cron 表达式解析
+// =============================================================================
+
+// CronSchedule 是解析后的 cron 表达式，可计算下一次触发时间。
+//
+// 支持的格式：
+//   - 5 段：分 时 日 月 周，例如 "30 2 * * 1-5"（工作日 02:30）
+//   - 6 段：秒 分 时 日 月 周，例如 "*/10 * * * * *"（每 10 秒）
+//   - 预定义：@yearly @monthly @weekly @daily @hourly，以及 "@every 1h30m"
+//   - 时区前缀："CRON_TZ=Asia/Shanghai 0 9 * * *"，未指定时使用 time.Local
+//
+// 每段支持 *、?、数字、范围 a-b、列表 a,b,c、步长 */n 或 a-b/n；
+// 月份和星期支持英文缩写（JAN-DEC、SUN-SAT），星期中 0 和 7 均表示周日。
+// 与 vixie cron 一致：日和周都被限定时，两者满足其一即可触发；以 * 开头的字段（含 */n）不算限定。
+type CronSchedule struct {
+	second, minute, hour, dom, month, dow uint64 // 各字段允许值的位图
+
+	domStar, dowStar bool           // 日/周字段是否以 * 开头（含 */n，决定二者是"且"还是"或"）
+	every            time.Duration  // @every 模式的固定间隔，> 0 时忽略位图
+	loc              *time.Location // 计算触发时间所用的时区
+}
+
+// cronField 描述 cron 表达式中一个字段的取值范围和名称别名。
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronSecond = cronField{name: "second", min: 0, max: 59}
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day-of-month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	cronDow = cronField{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// cronDescriptors 是预定义表达式到 6 段表达式的映射。
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseCron 解析 cron 表达式，格式说明见 CronSchedule。
+//
+// 示例：
+//
+//	s, err := ParseCron("0 9 * * MON-FRI")
+//	next := s.Next(time.Now()) // 下一个工作日 09:00
+func ParseCron(spec string) (*CronSchedule, error) {
+	s := &CronSchedule{loc: time.Local}
+	spec = strings.TrimSpace(spec)
+
+	// ---- 时区前缀 ----
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexByte(spec, ' ')
+		if i < 0 {
+			return nil, fmt.Errorf("cron: missing fields after time zone in %q", spec)
+		}
+		name := spec[strings.IndexByte(spec, '=')+1 : i]
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("cron: invalid time zone %q: %w", name, err)
+		}
+		s.loc = loc
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	// ---- 预定义表达式 ----
+	if strings.HasPrefix(spec, "@") {
+		if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(rest))
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("cron: invalid @every duration %q", rest)
+			}
+			s.every = d
+			return s, nil
+		}
+		expanded, ok := cronDescriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("cron: unknown descriptor %q", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...) // 5 段表达式在第 0 秒触发
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, got %d in %q", len(fields), spec)
+	}
+
+	var err error
+	targets := []*uint64{&s.second, &s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	defs := []cronField{cronSecond, cronMinute, cronHour, cronDom, cronMonth, cronDow}
+	for i, f := range fields {
+		if *targets[i], err = parseCronField(f, defs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// 星期中的 7 等同于 0（周日）
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = strings.HasPrefix(fields[3], "*") || fields[3] == "?"
+	s.dowStar = strings.HasPrefix(fields[5], "*") || fields[5] == "?"
+	return s, nil
+}
+
+// parseCronField 将单个字段解析为位图，第 n 位为 1 表示值 n 被允许。
+func parseCronField(expr string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		step := 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron: invalid step in %s field %q", f.name, expr)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			a, b, _ := strings.Cut(part, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := f.value(part)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v // 单个值；"a/n" 表示从 a 开始到最大值，每 n 个取一个
+			}
+		}
+
+		if lo > hi {
+			return 0, fmt.Errorf("cron: invalid range in %s field %q", f.name, expr)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value 解析字段中的单个值（数字或名称别名），并检查取值范围。
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("cron: invalid %s value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: %s value %d out of range [%d, %d]", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next 返回严格晚于 t 的下一次触发时间（位于表达式的时区中）。
+// 若 5 年内不存在满足条件的时间（如 "0 0 30 2 *"），返回零值 time.Time。
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+
+	t = t.In(s.loc).Truncate(time.Second).Add(time.Second)
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		y, m, d := t.Date()
+		switch {
+		case s.month&(1<<uint(m)) == 0:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, s.loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = time.Date(y, m, d, t.Hour(), t.Minute()+1, 0, 0, s.loc)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches 判断日期是否同时满足"日"和"周"字段（vixie cron 语义）。
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// =============================================================================
+// 定时任务配置
+// =============================================================================
+
+// MissedRunPolicy 决定定时任务触发延迟（错过了计划时间）时如何处理。
+type MissedRunPolicy int
+
+const (
+	// MissedRunOnce 无论错过多少次，都只补执行一次，然后按计划继续（默认）。
+	MissedRunOnce MissedRunPolicy = iota
+
+	// MissedRunSkip 错过的触发全部丢弃，直接等待下一个计划时间。
+	MissedRunSkip
+
+	// MissedRunAll 每一次错过的触发都补执行（单次最多补 1000 次，防止长时间休眠后瞬间灌满队列）。
+	MissedRunAll
+)
+
+// maxCatchUp 是 MissedRunAll 单次触发最多补跑的次数。
+const maxCatchUp = 1000
+
+// CronOptions 配置周期任务的触发行为。
+type CronOptions struct {
+	// Location 覆盖表达式的时区；为 nil 时使用表达式中的 CRON_TZ 或 time.Local。
+	Location *time.Location
+
+	// Jitter 为每次触发增加 [0, Jitter) 的随机延迟，用于打散大量同时触发的任务。
+	Jitter time.Duration
+
+	// MissedRun 是触发延迟时的补跑策略，默认 MissedRunOnce。
+	MissedRun MissedRunPolicy
+
+	// MisfireThreshold 是判定"错过"的容忍时间：实际触发晚于计划时间超过该值即视为错过。
+	// 默认值：1s。
+	MisfireThreshold time.Duration
+}
+
+// ScheduledEntry 是已登记定时任务的只读快照，由 ScheduledEntries 返回。
+type ScheduledEntry struct {
+	TaskID string    // 任务唯一标识
+	Spec   string    // cron 表达式；一次性任务（SubmitAt/SubmitAfter）为空
+	Next   time.Time // 下一次计划触发时间（含抖动）
+	Runs   int64     // 已成功提交到队列的次数
+	Missed int64     // 被错过策略丢弃、或因队列满/Pool 停止而提交失败的次数
+}
+
+// scheduledEntry 是定时任务的内部状态，所有字段由 WorkerPool.schedMu 保护。
+type scheduledEntry[T any] struct {
+	task     Task[T]
+	resultCh chan<- Result[T]
+	spec     string        // 原始 cron 表达式，一次性任务为空
+	schedule *CronSchedule // 一次性任务为 nil
+	opts     CronOptions
+	due      time.Time   // 本次计划触发时间（不含抖动）
+	fireAt   time.Time   // 实际定时器触发时间（含抖动）
+	timer    *time.Timer // 到期后调用 fire
+	runs     int64
+	missed   int64
+}
+
+// =============================================================================
+// 公开 API
+// =============================================================================
+
+// SubmitAt 登记一个在 at 时刻执行的一次性任务，at 已过去时立即提交。
+//
+// 任务到期后通过 SubmitWithResult 入队，resultCh 可以为 nil。
+// 在触发之前可通过 CancelScheduled(task.TaskID()) 取消。
+// 同一 TaskID 已有未结束的定时任务时返回错误。
+func (p *WorkerPool[T]) SubmitAt(task Task[T], at time.Time, resultCh chan<- Result[T]) error {
+	e := &scheduledEntry[T]{task: task, resultCh: resultCh, due: at, fireAt: at}
+	return p.addScheduled(e)
+}
+
+// SubmitAfter 登记一个在 delay 之后执行的一次性任务，等价于 SubmitAt(task, time.Now().Add(delay), resultCh)。
+func (p *WorkerPool[T]) SubmitAfter(task Task[T], delay time.Duration, resultCh chan<- Result[T]) error {
+	return p.SubmitAt(task, time.Now().Add(delay), resultCh)
+}
+
+// SubmitCron 按 cron 表达式周期性地提交同一个任务，直到被 CancelScheduled 取消或 Pool 停止。
+//
+// 每次触发都会把 task 重新入队一次，因此 task 的 Run 方法需要支持被多次调用；
+// 若上一次执行尚未结束，新一次仍会正常入队（可能并发执行）。
+//
+// 使用示例：
+//
+//	err := pool.SubmitCron("CRON_TZ=Asia/Shanghai 0 */2 * * *", reportTask, CronOptions{
+//	    Jitter:    30 * time.Second,
+//	    MissedRun: MissedRunSkip,
+//	}, nil)
+func (p *WorkerPool[T]) SubmitCron(spec string, task Task[T], opts CronOptions, resultCh chan<- Result[T]) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	if opts.Location != nil {
+		schedule.loc = opts.Location
+	}
+	if opts.MisfireThreshold <= 0 {
+		opts.MisfireThreshold = time.Second
+	}
+
+	due := schedule.Next(time.Now())
+	if due.IsZero() {
+		return fmt.Errorf("cron: expression %q never fires", spec)
+	}
+
+	e := &scheduledEntry[T]{
+		task:     task,
+		resultCh: resultCh,
+		spec:     spec,
+		schedule: schedule,
+		opts:     opts,
+		due:      due,
+		fireAt:   due.Add(jitter(opts.Jitter)),
+	}
+	return p.addScheduled(e)
+}
+
+// ScheduledEntries 返回所有尚未结束的定时任务快照，按下一次触发时间升序排列。
+func (p *WorkerPool[T]) ScheduledEntries() []ScheduledEntry {
+	p.schedMu.Lock()
+	defer p.schedMu.Unlock()
+
+	entries := make([]ScheduledEntry, 0, len(p.scheduled))
+	for id, e := range p.scheduled {
+		entries = append(entries, ScheduledEntry{
+			TaskID: id,
+			Spec:   e.spec,
+			Next:   e.fireAt,
+			Runs:   e.runs,
+			Missed: e.missed,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Next.Before(entries[j].Next)
+	})
+	return entries
+}
+
+// CancelScheduled 取消指定 TaskID 的定时任务，返回是否找到并取消。
+// 已经入队的执行不受影响，只会阻止后续的触发。
+func (p *WorkerPool[T]) CancelScheduled(taskID string) bool {
+	p.schedMu.Lock()
+	defer p.schedMu.Unlock()
+
+	e, ok := p.scheduled[taskID]
+	if !ok {
+		return false
+	}
+	e.timer.Stop()
+	delete(p.scheduled, taskID)
+	return true
+}
+
+// =============================================================================
+// 内部实现
+// =============================================================================
+
+// addScheduled 登记定时任务并启动定时器。
+//
+// 加锁顺序固定为 schedMu -> mu（与 fire 一致）：Stop 在置位 stopped 之后才清空定时任务，
+// 因此这里要么看到 stopped，要么登记的任务一定会被随后的 cancelAllScheduled 清除。
+func (p *WorkerPool[T]) addScheduled(e *scheduledEntry[T]) error {
+	id := e.task.TaskID()
+	p.schedMu.Lock()
+	defer p.schedMu.Unlock()
+
+	p.mu.Lock()
+	stopped := p.stopped
+	p.mu.Unlock()
+	if stopped {
+		return errors.New("workerpool: pool is stopped")
+	}
+
+	if _, exists := p.scheduled[id]; exists {
+		return fmt.Errorf("workerpool: task %q is already scheduled", id)
+	}
+	p.scheduled[id] = e
+	e.timer = time.AfterFunc(time.Until(e.fireAt), func() { p.fire(id, e) })
+	return nil
+}
+
+// fire 在定时器到期时被调用：按错过策略提交任务，并为周期任务安排下一次触发。
+func (p *WorkerPool[T]) fire(id string, e *scheduledEntry[T]) {
+	p.schedMu.Lock()
+	defer p.schedMu.Unlock()
+
+	// 任务可能在定时器触发的同时被取消或替换
+	if p.scheduled[id] != e {
+		return
+	}
+
+	now := time.Now()
+
+	// ---- 一次性任务：提交后移除 ----
+	if e.schedule == nil {
+		p.submitScheduled(id, e)
+		delete(p.scheduled, id)
+		return
+	}
+
+	// ---- 周期任务：统计本次触发覆盖了多少个计划时间点 ----
+	occurrences := 1
+	next := e.schedule.Next(e.due)
+	for !next.IsZero() && !next.After(now) && occurrences < maxCatchUp {
+		occurrences++
+		next = e.schedule.Next(next)
+	}
+	late := now.Sub(e.fireAt) > e.opts.MisfireThreshold || occurrences > 1
+
+	runs := 1
+	if late {
+		switch e.opts.MissedRun {
+		case MissedRunSkip:
+			runs = 0
+		case MissedRunAll:
+			runs = occurrences
+		}
+		p.opts.Logger("[workerpool] 定时任务 %q 错过计划时间 %s（错过 %d 次），本次补执行 %d 次",
+			id, e.due.Format(time.DateTime), occurrences, runs)
+	}
+	e.missed += int64(occurrences - runs)
+	for i := 0; i < runs; i++ {
+		p.submitScheduled(id, e)
+	}
+
+	// ---- 安排下一次触发 ----
+	if !next.After(now) {
+		next = e.schedule.Next(now)
+	}
+	if next.IsZero() {
+		delete(p.scheduled, id)
+		return
+	}
+	e.due = next
+	e.fireAt = next.Add(jitter(e.opts.Jitter))
+	e.timer.Reset(time.Until(e.fireAt))
+}
+
+// submitScheduled 将定时任务入队，失败时记录日志并计入 Missed。
+// 调用方必须持有 p.schedMu。
+func (p *WorkerPool[T]) submitScheduled(id string, e *scheduledEntry[T]) {
+	if err := p.SubmitWithResult(e.task, e.resultCh); err != nil {
+		e.missed++
+		p.opts.Logger("[workerpool] 定时任务 %q 提交失败: %v", id, err)
+		return
+	}
+	e.runs++
+}
+
+// cancelAllScheduled 停止并清空所有定时任务，在 Stop/StopGraceful 中调用。
+func (p *WorkerPool[T]) cancelAllScheduled() {
+	p.schedMu.Lock()
+	defer p.schedMu.Unlock()
+
+	for id, e := range p.scheduled {
+		e.timer.Stop()
+		delete(p.scheduled, id)
+	}
+}
+
+// jitter 返回 [0, max) 内的随机时长，max <= 0 时返回 0。
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
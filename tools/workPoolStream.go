@@ -0,0 +1,148 @@
+package tools
+
+// 流式收集：以 iter.Seq 逐个产出任务结果，不必等全部完成。
+//
+// 核心特性：
+//   - 边执行边消费：结果一产生即可在 for range 中处理
+//   - 可选有序：Ordered=true 时按任务切片顺序产出，否则按完成顺序产出
+//   - 提交失败可见：队列满或 Pool 已停止的任务以 *SubmitError 作为 Result.Err 产出，不会漏等
+//   - 提前终止：FailFast=true 时遇到第一个错误即取消其余任务（类似 errgroup），
+//     调用方 break 或 ctx 取消时同样会取消其余任务
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// SubmitError 表示任务未能提交到 Pool（队列已满或 Pool 已停止），任务没有被执行。
+type SubmitError struct {
+	TaskID string
+	Err    error // Submit 返回的原始错误
+}
+
+func (e *SubmitError) Error() string {
+	return fmt.Sprintf("workerpool: submit task %q: %v", e.TaskID, e.Err)
+}
+
+// Unwrap 支持 errors.Is / errors.As 访问原始错误。
+func (e *SubmitError) Unwrap() error { return e.Err }
+
+// StreamOptions 配置 Stream 和 Collect 的行为。
+type StreamOptions struct {
+	// Ordered 为 true 时按任务切片顺序产出结果（先完成的结果会等待前面的任务），
+	// 为 false 时按完成顺序产出。
+	Ordered bool
+
+	// FailFast 为 true 时，产出第一个 Err != nil 的结果（含提交失败）后停止迭代，
+	// 并取消其余尚未结束的任务。
+	FailFast bool
+}
+
+// Stream 提交 tasks 并返回逐个产出结果的迭代器。任务在开始迭代时才提交，每次迭代都会重新提交。
+//
+// 迭代提前结束（调用方 break、FailFast 遇到错误或 ctx 被取消）时，其余尚未结束的任务会被取消，
+// 以 ErrTaskCancelled 结束；ctx 被取消时迭代直接结束，不再产出结果。
+//
+// 使用示例：
+//
+//	for r := range pool.Stream(ctx, tasks, StreamOptions{Ordered: true, FailFast: true}) {
+//	    if r.Err != nil {
+//	        return r.Err // 其余任务已被取消
+//	    }
+//	    handle(r.Value)
+//	}
+func (p *WorkerPool[T]) Stream(ctx context.Context, tasks []Task[T], opts StreamOptions) iter.Seq[Result[T]] {
+	return func(yield func(Result[T]) bool) {
+		// 无序模式通过共享 channel 按完成顺序接收，缓冲足够大，迭代提前结束后 Worker 也不会阻塞
+		var ch chan Result[T]
+		if !opts.Ordered {
+			ch = make(chan Result[T], len(tasks))
+		}
+
+		futs := make([]*Future[T], len(tasks))
+		var failed []Result[T] // 提交失败的任务，按提交顺序
+		submitted := 0
+		for i, t := range tasks {
+			f, err := p.submit(t, ch) // 有序模式下 ch 为 nil，只通过 Future 取结果
+			if err != nil {
+				failed = append(failed, Result[T]{TaskID: t.TaskID(), Err: &SubmitError{TaskID: t.TaskID(), Err: err}})
+				continue
+			}
+			futs[i] = f
+			submitted++
+		}
+
+		// 无论以何种方式结束迭代，都取消其余任务（已结束的任务不受影响）
+		defer func() {
+			for _, f := range futs {
+				if f != nil {
+					f.Cancel()
+				}
+			}
+		}()
+
+		emit := func(r Result[T]) bool {
+			if !yield(r) {
+				return false
+			}
+			return !(opts.FailFast && r.Err != nil)
+		}
+
+		if opts.Ordered {
+			next := 0 // failed 中下一个待产出的提交失败结果
+			for _, f := range futs {
+				var r Result[T]
+				if f == nil {
+					r = failed[next]
+					next++
+				} else {
+					var err error
+					if r, err = f.Wait(ctx); err != nil {
+						return
+					}
+				}
+				if !emit(r) {
+					return
+				}
+			}
+			return
+		}
+
+		// 无序模式：提交失败的结果已经确定，先产出
+		for _, r := range failed {
+			if !emit(r) {
+				return
+			}
+		}
+		for range submitted {
+			select {
+			case r := <-ch:
+				if !emit(r) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Collect 以 Stream 的语义执行 tasks 并收集全部结果，返回第一个错误（类似 errgroup.Wait）。
+//
+// FailFast=true 时在第一个错误处停止收集并取消其余任务，返回已收集到的结果（含出错的那个）；
+// 否则收集全部结果，error 为其中第一个产出的错误。ctx 被取消时返回 context.Cause(ctx)。
+func (p *WorkerPool[T]) Collect(ctx context.Context, tasks []Task[T], opts StreamOptions) ([]Result[T], error) {
+	results := make([]Result[T], 0, len(tasks))
+	var firstErr error
+	for r := range p.Stream(ctx, tasks, opts) {
+		results = append(results, r)
+		if r.Err != nil && firstErr == nil {
+			firstErr = r.Err
+		}
+	}
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = context.Cause(ctx)
+	}
+	return results, firstErr
+}
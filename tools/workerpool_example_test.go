@@ -5,11 +5,36 @@ package tools
 //   - 示例2：多种不同结构体任务共用同一个 Pool（union 结果类型）
 //   - 示例3：Fire-and-forget 模式 + 动态扩容 + 优雅停止
 //   - 示例4：超时与重试联动演示
+//   - 示例5：DAG 依赖编排（上游结果传递 + 失败跳过）
+//   - 示例6：多阶段流水线（背压 + 有序输出）
+//   - 示例7：延迟任务与 cron 表达式
+//   - 示例8：Future 等待结果与单独取消任务
+//   - 示例9：导出 Prometheus 指标
+//   - 示例10：空闲 Worker 自动回收与手动缩容
+//   - 示例11：自适应并发控制（AIMD）
+//   - 示例12：令牌桶突发与按 key 限流
+//   - 示例13：任务 panic 隔离与超时后未返回的任务统计
+//   - 示例14：熔断器快速失败与半开恢复
+//   - 示例15：攒批执行（批量写入 + 部分失败）
+//   - 示例16：订阅结构化事件与 slog 日志
+//   - 示例17：流式有序收集与遇错提前终止
+//   - 示例18：暂停/恢复取任务、Drain 排空与限时优雅停止
+//   - 示例19：分布式 Coordinator/RemoteWorker（租约过期后重新分配）
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -156,7 +181,7 @@ func Example_dynamicScaleAndStop() {
 	})
 
 	for i := 1; i <= 50; i++ {
-		_ = pool.Submit(&ComputeTask{n: i})
+		_, _ = pool.Submit(&ComputeTask{n: i})
 	}
 
 	fmt.Println("提交后指标:", pool.Metrics())
@@ -208,3 +233,787 @@ func Example_retryAndTimeout() {
 		}
 	}
 }
+
+// =============================================================================
+// 示例 5：DAG 依赖编排
+// =============================================================================
+
+func Example_dagDependencies() {
+	pool := NewPool[int](Options{Workers: 4})
+	defer pool.StopGraceful()
+
+	dag := NewDAG(pool, DAGSkipDependents)
+	_ = dag.Add(&DAGFunc[int]{ID: "a", Fn: func(_ context.Context, _ map[string]Result[int]) (int, error) {
+		return 2, nil
+	}})
+	_ = dag.Add(&DAGFunc[int]{ID: "b", Fn: func(_ context.Context, _ map[string]Result[int]) (int, error) {
+		return 3, nil
+	}})
+	_ = dag.Add(&DAGFunc[int]{ID: "sum", Deps: []string{"a", "b"}, Fn: func(_ context.Context, up map[string]Result[int]) (int, error) {
+		return up["a"].Value + up["b"].Value, nil
+	}})
+	_ = dag.Add(&DAGFunc[int]{ID: "broken", Fn: func(_ context.Context, _ map[string]Result[int]) (int, error) {
+		return 0, errors.New("上游故障")
+	}})
+	_ = dag.Add(&DAGFunc[int]{ID: "after-broken", Deps: []string{"broken"}, Fn: func(_ context.Context, _ map[string]Result[int]) (int, error) {
+		return 1, nil
+	}})
+
+	results, err := dag.Run(context.Background())
+	if err != nil {
+		fmt.Println("图无效:", err)
+		return
+	}
+
+	ids := make([]string, 0, len(results))
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		r := results[id]
+		switch {
+		case errors.Is(r.Err, ErrDAGSkipped):
+			fmt.Printf("[%s] 已跳过\n", id)
+		case r.Err != nil:
+			fmt.Printf("[%s] 失败: %v\n", id, r.Err)
+		default:
+			fmt.Printf("[%s] = %d\n", id, r.Value)
+		}
+	}
+
+	cyclic := NewDAG(pool, DAGContinue)
+	_ = cyclic.Add(&DAGFunc[int]{ID: "x", Deps: []string{"y"}})
+	_ = cyclic.Add(&DAGFunc[int]{ID: "y", Deps: []string{"x"}})
+	fmt.Println(cyclic.Validate())
+
+	// Output:
+	// [a] = 2
+	// [after-broken] 已跳过
+	// [b] = 3
+	// [broken] 失败: 上游故障
+	// [sum] = 5
+	// workerpool: dependency cycle detected: x -> y -> x
+}
+
+// =============================================================================
+// 示例 6：多阶段流水线
+// =============================================================================
+
+func Example_pipeline() {
+	src := make(chan int)
+	go func() {
+		for i := 1; i <= 5; i++ {
+			src <- i
+		}
+		close(src)
+	}()
+
+	// 阶段 1：模拟耗时不等的抓取，完成顺序与输入顺序不同
+	fetched := AddStage(NewPipeline(context.Background(), src, true), "fetch",
+		Options{Workers: 4, QueueSize: 8},
+		func(ctx context.Context, n int) (string, error) {
+			time.Sleep(time.Duration(6-n) * 10 * time.Millisecond)
+			if n == 3 {
+				return "", errors.New("404")
+			}
+			return strings.Repeat("*", n), nil
+		})
+
+	// 阶段 2：解析，第 3 条数据在上一阶段已失败，不会进入这里
+	parsed := AddStage(fetched, "parse",
+		Options{Workers: 2, QueueSize: 8},
+		func(ctx context.Context, body string) (int, error) {
+			return len(body), nil
+		})
+
+	for _, r := range parsed.Collect() {
+		if r.Err != nil {
+			fmt.Printf("#%d 失败: %v\n", r.Seq, r.Err)
+		} else {
+			fmt.Printf("#%d 长度 %d\n", r.Seq, r.Value)
+		}
+	}
+	parsed.Wait()
+
+	// Output:
+	// #0 长度 1
+	// #1 长度 2
+	// #2 失败: pipeline: stage "fetch" item 2: 404
+	// #3 长度 4
+	// #4 长度 5
+}
+
+// =============================================================================
+// 示例 7：延迟任务与 cron 表达式
+// =============================================================================
+
+func Example_scheduledTasks() {
+	// cron 表达式可独立使用，计算下一次触发时间
+	s, _ := ParseCron("CRON_TZ=UTC 30 9 * * MON-FRI")
+	t := time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC) // 周五 10:00
+	for i := 0; i < 3; i++ {
+		t = s.Next(t)
+		fmt.Println(t.Format("2006-01-02 Mon 15:04"))
+	}
+
+	// "*/2" 同样视为通配：日和周两个字段同时满足（奇数日且为周一），而不是满足其一
+	s2, _ := ParseCron("CRON_TZ=UTC 0 8 */2 * MON")
+	t = time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		t = s2.Next(t)
+		fmt.Println(t.Format("2006-01-02 Mon 15:04"))
+	}
+
+	pool := NewPool[string](Options{Workers: 1})
+	defer pool.StopGraceful()
+
+	ch := make(chan Result[string], 1)
+	_ = pool.SubmitAfter(&FlakeyTask{id: "later"}, 20*time.Millisecond, ch)
+	_ = pool.SubmitAfter(&FlakeyTask{id: "cancelled"}, time.Hour, nil)
+	fmt.Println("登记数:", len(pool.ScheduledEntries()))
+	fmt.Println("取消:", pool.CancelScheduled("cancelled"))
+
+	r := <-ch
+	fmt.Printf("[%s] %s\n", r.TaskID, r.Value)
+	fmt.Println("剩余登记数:", len(pool.ScheduledEntries()))
+
+	// Output:
+	// 2025-01-06 Mon 09:30
+	// 2025-01-07 Tue 09:30
+	// 2025-01-08 Wed 09:30
+	// 2025-01-13 Mon 08:00
+	// 2025-01-27 Mon 08:00
+	// 2025-02-03 Mon 08:00
+	// 登记数: 2
+	// 取消: true
+	// [later] 第 1 次尝试成功
+	// 剩余登记数: 0
+}
+
+// =============================================================================
+// 示例 8：Future 等待结果与单独取消任务
+// =============================================================================
+
+// SleepTask 睡眠指定时长后返回，期间响应 ctx 取消。
+type SleepTask struct {
+	id string
+	d  time.Duration
+}
+
+func (s *SleepTask) TaskID() string { return s.id }
+func (s *SleepTask) Run(ctx context.Context) (string, error) {
+	select {
+	case <-time.After(s.d):
+		return s.id + " 完成", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func Example_futureCancel() {
+	pool := NewPool[string](Options{Workers: 1})
+	defer pool.StopGraceful()
+
+	slow, _ := pool.Submit(&SleepTask{id: "slow", d: time.Hour})
+	quick, _ := pool.Submit(&SleepTask{id: "quick", d: 10 * time.Millisecond})
+	queued, _ := pool.Submit(&SleepTask{id: "queued", d: 10 * time.Millisecond})
+
+	for slow.Status() != TaskRunning {
+		time.Sleep(time.Millisecond)
+	}
+	fmt.Println("slow:", slow.Status(), "quick:", quick.Status())
+
+	// 取消正在执行的任务和仍在排队的任务，互不影响
+	slow.Cancel()
+	fmt.Println("按 ID 取消:", pool.Cancel("queued"))
+
+	for _, f := range []*Future[string]{slow, quick, queued} {
+		r, _ := f.Wait(context.Background())
+		fmt.Printf("[%s] value=%q cancelled=%v\n", r.TaskID, r.Value, errors.Is(r.Err, ErrTaskCancelled))
+	}
+	fmt.Println("cancelled:", pool.Metrics().Cancelled)
+
+	// Output:
+	// slow: running quick: queued
+	// 按 ID 取消: 1
+	// [slow] value="" cancelled=true
+	// [quick] value="quick 完成" cancelled=false
+	// [queued] value="" cancelled=true
+	// cancelled: 2
+}
+
+// =============================================================================
+// 示例 9：导出 Prometheus 指标
+// =============================================================================
+
+func Example_prometheusMetrics() {
+	pool := NewPool[string](Options{
+		Name:       "crawler",
+		Workers:    2,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	})
+	defer pool.StopGraceful()
+
+	pool.SubmitAndCollect([]Task[string]{
+		&FlakeyTask{id: "ok", failFor: 0},
+		&FlakeyTask{id: "retry-once", failFor: 1},
+	})
+
+	// 通常是 http.Handle("/metrics", pool.MetricsHandler())，这里用 httptest 演示输出
+	rec := httptest.NewRecorder()
+	pool.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, line := range strings.Split(string(body), "\n") {
+		if strings.HasPrefix(line, "workerpool_tasks_succeeded_total") ||
+			strings.HasPrefix(line, "workerpool_tasks_retried_total") ||
+			strings.HasPrefix(line, "workerpool_task_attempts_bucket") {
+			fmt.Println(line)
+		}
+	}
+
+	// Output:
+	// workerpool_tasks_succeeded_total{pool="crawler"} 2
+	// workerpool_tasks_retried_total{pool="crawler"} 1
+	// workerpool_task_attempts_bucket{pool="crawler",le="1"} 1
+	// workerpool_task_attempts_bucket{pool="crawler",le="2"} 2
+	// workerpool_task_attempts_bucket{pool="crawler",le="3"} 2
+	// workerpool_task_attempts_bucket{pool="crawler",le="5"} 2
+	// workerpool_task_attempts_bucket{pool="crawler",le="10"} 2
+	// workerpool_task_attempts_bucket{pool="crawler",le="+Inf"} 2
+}
+
+// =============================================================================
+// 示例 10：空闲 Worker 自动回收与手动缩容
+// =============================================================================
+
+func Example_idleScaleDown() {
+	pool := NewPool[int](Options{
+		Workers:     2,
+		MaxWorkers:  8,
+		IdleTimeout: 50 * time.Millisecond,
+		Logger:      func(string, ...any) {},
+	})
+	defer pool.StopGraceful()
+
+	// 手动扩容到 6，再缩容到 3：空闲的 Worker 立即退出
+	_ = pool.Scale(6)
+	fmt.Println("扩容后:", pool.Metrics().Workers)
+	_ = pool.Scale(3)
+	time.Sleep(20 * time.Millisecond)
+	fmt.Println("缩容后:", pool.Metrics().Workers)
+
+	// Scale(n) 同时设定了下限，空闲回收不会低于 3
+	time.Sleep(200 * time.Millisecond)
+	fmt.Println("空闲一段时间后:", pool.Metrics().Workers)
+
+	// Output:
+	// 扩容后: 6
+	// 缩容后: 3
+	// 空闲一段时间后: 3
+}
+
+// =============================================================================
+// 示例 11：自适应并发控制（AIMD）
+// =============================================================================
+
+// SlowBackendTask 模拟一个并发超过 4 后明显变慢的下游服务。
+type SlowBackendTask struct {
+	id       int
+	inflight *atomic.Int64
+}
+
+func (t *SlowBackendTask) TaskID() string { return fmt.Sprintf("req-%d", t.id) }
+func (t *SlowBackendTask) Run(ctx context.Context) (int, error) {
+	n := t.inflight.Add(1)
+	defer t.inflight.Add(-1)
+
+	delay := 2 * time.Millisecond
+	if n > 4 {
+		delay *= time.Duration(n - 3)
+	}
+	select {
+	case <-time.After(delay):
+		return t.id, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func Example_adaptiveConcurrency() {
+	pool := NewPool[int](Options{
+		Workers:    2,
+		MaxWorkers: 16,
+		Adaptive: &AdaptiveOptions{
+			Interval:      20 * time.Millisecond,
+			LatencyTarget: 5 * time.Millisecond, // 平均耗时超过 5ms 即收缩并发
+		},
+		Logger: func(string, ...any) {},
+	})
+	defer pool.StopGraceful()
+
+	var inflight atomic.Int64
+	tasks := make([]Task[int], 500)
+	for i := range tasks {
+		tasks[i] = &SlowBackendTask{id: i, inflight: &inflight}
+	}
+
+	// 后台采样并发上限的峰值
+	var peak atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+				peak.Store(max(peak.Load(), pool.Metrics().ConcurrencyLimit))
+			}
+		}
+	}()
+	results := pool.SubmitAndCollect(tasks)
+	close(done)
+
+	limit := pool.Metrics().ConcurrencyLimit
+	fmt.Println("完成任务数:", len(results))
+	fmt.Println("并发上限始终在 [Workers, MaxWorkers] 内:", limit >= 2 && limit <= 16 && peak.Load() <= 16)
+
+	// Output:
+	// 完成任务数: 500
+	// 并发上限始终在 [Workers, MaxWorkers] 内: true
+}
+
+// =============================================================================
+// 示例 12：令牌桶突发与按 key 限流
+// =============================================================================
+
+// HostTask 按目标主机分组限流，实现了 Keyed 接口。
+type HostTask struct {
+	id    string
+	host  string
+	delay time.Duration
+}
+
+func (t *HostTask) TaskID() string  { return t.id }
+func (t *HostTask) TaskKey() string { return t.host }
+func (t *HostTask) Run(ctx context.Context) (string, error) {
+	select {
+	case <-time.After(t.delay):
+		return t.host, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func Example_perKeyRateLimit() {
+	// 令牌桶：容量 3，空闲后可瞬间取走 3 个令牌，之后按速率补充
+	bucket := NewTokenBucket(10, 3)
+	fmt.Println("突发:", bucket.Allow(), bucket.Allow(), bucket.Allow(), bucket.Allow())
+
+	// 每个主机最多同时执行 1 个任务：慢主机的任务排队，不会占住其他 Worker
+	pool := NewPool[string](Options{Workers: 4, KeyConcurrency: 1})
+	defer pool.StopGraceful()
+
+	var tasks []Task[string]
+	for i := 0; i < 4; i++ {
+		tasks = append(tasks, &HostTask{id: fmt.Sprintf("slow-%d", i), host: "slow.example", delay: 30 * time.Millisecond})
+	}
+	for i := 0; i < 4; i++ {
+		tasks = append(tasks, &HostTask{id: fmt.Sprintf("fast-%d", i), host: "fast.example", delay: time.Millisecond})
+	}
+
+	var order []string
+	for _, r := range pool.SubmitAndCollect(tasks) {
+		order = append(order, r.Value)
+	}
+	fmt.Println("最先完成:", order[0])
+	fmt.Println("最后完成:", order[len(order)-1])
+
+	// Output:
+	// 突发: true true true false
+	// 最先完成: fast.example
+	// 最后完成: slow.example
+}
+
+// =============================================================================
+// 示例 13：任务 panic 隔离与超时后未返回的任务统计
+// =============================================================================
+
+// PanicTask 在 Run 中触发 panic。
+type PanicTask struct{ id string }
+
+func (t *PanicTask) TaskID() string { return t.id }
+func (t *PanicTask) Run(_ context.Context) (string, error) {
+	var m map[string]int
+	m["boom"]++ // 向 nil map 写入，触发 panic
+	return "unreachable", nil
+}
+
+// StubbornTask 不监听 ctx，超时后仍会继续运行一段时间。
+type StubbornTask struct{ id string }
+
+func (t *StubbornTask) TaskID() string { return t.id }
+func (t *StubbornTask) Run(_ context.Context) (string, error) {
+	time.Sleep(100 * time.Millisecond)
+	return "late", nil
+}
+
+func Example_panicRecovery() {
+	pool := NewPool[string](Options{
+		Workers:     2,
+		MaxRetries:  2,
+		TaskTimeout: 20 * time.Millisecond,
+		Logger:      func(string, ...any) {},
+	})
+	defer pool.StopGraceful()
+
+	results := pool.SubmitAndCollect([]Task[string]{&PanicTask{id: "panic"}})
+	var pe *PanicError
+	if errors.As(results[0].Err, &pe) {
+		fmt.Printf("[%s] panic 已捕获: %v（尝试 %d 次，堆栈非空: %v）\n",
+			pe.TaskID, pe.Value, results[0].Attempts, len(pe.Stack) > 0)
+	}
+
+	// Worker 没有崩溃，继续处理后续任务
+	r := pool.SubmitAndCollect([]Task[string]{&FlakeyTask{id: "after-panic"}})[0]
+	fmt.Printf("[%s] %s\n", r.TaskID, r.Value)
+
+	// 不监听 ctx 的任务超时后，其 goroutine 仍在运行，计入 Abandoned（超时会重试，共 3 次执行）
+	pool.SubmitAndCollect([]Task[string]{&StubbornTask{id: "stubborn"}})
+	fmt.Println("超时后仍在运行:", pool.Metrics().Abandoned)
+	time.Sleep(200 * time.Millisecond)
+	fmt.Println("最终返回后:", pool.Metrics().Abandoned, "panicked:", pool.Metrics().Panicked)
+
+	// Output:
+	// [panic] panic 已捕获: assignment to entry in nil map（尝试 1 次，堆栈非空: true）
+	// [after-panic] 第 1 次尝试成功
+	// 超时后仍在运行: 3
+	// 最终返回后: 0 panicked: 1
+}
+
+// =============================================================================
+// 示例 14：熔断器快速失败与半开恢复
+// =============================================================================
+
+// DownstreamTask 模拟调用一个可能宕机的下游服务。
+type DownstreamTask struct {
+	id   string
+	down *atomic.Bool
+}
+
+func (t *DownstreamTask) TaskID() string { return t.id }
+func (t *DownstreamTask) Run(_ context.Context) (string, error) {
+	if t.down.Load() {
+		return "", errors.New("connection refused")
+	}
+	return "ok", nil
+}
+
+func Example_circuitBreaker() {
+	var down atomic.Bool
+	down.Store(true)
+
+	pool := NewPool[string](Options{
+		Workers: 1,
+		Breaker: &BreakerOptions{FailureThreshold: 3, CoolDown: 50 * time.Millisecond},
+		Logger:  func(string, ...any) {},
+	})
+	defer pool.StopGraceful()
+
+	// 下游宕机：前 3 次真实失败后熔断器断开，后续任务不再调用下游
+	for i := 1; i <= 5; i++ {
+		r := pool.SubmitAndCollect([]Task[string]{&DownstreamTask{id: fmt.Sprintf("call-%d", i), down: &down}})[0]
+		fmt.Printf("[%s] 熔断拒绝: %v\n", r.TaskID, errors.Is(r.Err, ErrBreakerOpen))
+	}
+	fmt.Println("状态:", pool.BreakerState(""), "拒绝次数:", pool.Metrics().BreakerRejected)
+
+	// 下游恢复，冷却期过后半开试探成功，熔断器闭合
+	down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	fmt.Println("冷却后:", pool.BreakerState(""))
+	r := pool.SubmitAndCollect([]Task[string]{&DownstreamTask{id: "probe", down: &down}})[0]
+	fmt.Printf("[%s] %s，状态: %s\n", r.TaskID, r.Value, pool.BreakerState(""))
+
+	// Output:
+	// [call-1] 熔断拒绝: false
+	// [call-2] 熔断拒绝: false
+	// [call-3] 熔断拒绝: false
+	// [call-4] 熔断拒绝: true
+	// [call-5] 熔断拒绝: true
+	// 状态: open 拒绝次数: 2
+	// 冷却后: half-open
+	// [probe] ok，状态: closed
+}
+
+// =============================================================================
+// 示例 15：攒批执行（批量写入 + 部分失败）
+// =============================================================================
+
+func Example_batchPool() {
+	// 模拟批量插入：返回每行的 ID，负数行插入失败
+	var batches atomic.Int64
+	insert := BatchFunc[int, string](func(_ context.Context, rows []int) ([]string, error) {
+		batches.Add(1)
+		ids := make([]string, len(rows))
+		errs := make(BatchErrors, len(rows))
+		failed := false
+		for i, v := range rows {
+			if v < 0 {
+				errs[i] = fmt.Errorf("invalid row %d", v)
+				failed = true
+				continue
+			}
+			ids[i] = fmt.Sprintf("id-%d", v)
+		}
+		if failed {
+			return ids, errs
+		}
+		return ids, nil
+	})
+
+	bp := NewBatchPool[int, string](insert, BatchOptions{
+		Options:      Options{Workers: 1},
+		MaxBatchSize: 4,
+		MaxWait:      20 * time.Millisecond,
+	})
+
+	rows := []int{1, 2, -3, 4, 5, 6}
+	resCh := make(chan Result[string], len(rows))
+	for _, v := range rows {
+		_ = bp.Submit(fmt.Sprintf("row%d", v), v, resCh)
+	}
+	bp.StopGraceful() // 前 4 条攒满发车，剩余 2 条在停止时作为最后一批发出
+
+	results := make([]Result[string], 0, len(rows))
+	for range rows {
+		results = append(results, <-resCh)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].TaskID < results[j].TaskID })
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("[%s] 失败: %v\n", r.TaskID, r.Err)
+		} else {
+			fmt.Printf("[%s] %s\n", r.TaskID, r.Value)
+		}
+	}
+	fmt.Println("批次数:", batches.Load())
+
+	// Output:
+	// [row-3] 失败: invalid row -3
+	// [row1] id-1
+	// [row2] id-2
+	// [row4] id-4
+	// [row5] id-5
+	// [row6] id-6
+	// 批次数: 2
+}
+
+// =============================================================================
+// 示例 16：订阅结构化事件与 slog 日志
+// =============================================================================
+
+func Example_lifecycleEvents() {
+	// slog 输出去掉时间字段，便于示例比对；生产环境直接传入 slog.Default() 等即可
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" || a.Key == "delay" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	pool := NewPool[string](Options{
+		Name:       "events",
+		Workers:    1,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+		Slog:       slog.New(handler),
+	})
+	events, cancel := pool.Events(64)
+	defer cancel()
+
+	pool.SubmitAndCollect([]Task[string]{&FlakeyTask{id: "flaky", failFor: 1}})
+	pool.StopGraceful()
+
+	for e := range events {
+		fmt.Printf("%s %s attempt=%d\n", e.Type, e.TaskID, e.Attempt)
+	}
+
+	// Output:
+	// level=WARN msg="workerpool retrying" pool=events task_id=flaky attempt=1 error="临时错误（第 1 次尝试）"
+	// queued flaky attempt=0
+	// started flaky attempt=1
+	// retrying flaky attempt=1
+	// succeeded flaky attempt=2
+	// stopped  attempt=0
+}
+
+// =============================================================================
+// 示例 17：流式有序收集与遇错提前终止
+// =============================================================================
+
+func Example_streamResults() {
+	pool := NewPool[string](Options{Workers: 4, QueueSize: 5, Logger: func(string, ...any) {}})
+	defer pool.StopGraceful()
+
+	// 耗时倒序的任务：完成顺序与提交顺序相反，Ordered 模式仍按提交顺序产出
+	var tasks []Task[string]
+	for i := 0; i < 4; i++ {
+		tasks = append(tasks, &HostTask{id: fmt.Sprintf("t%d", i), host: fmt.Sprintf("h%d", i), delay: time.Duration(4-i) * 10 * time.Millisecond})
+	}
+	for r := range pool.Stream(context.Background(), tasks, StreamOptions{Ordered: true}) {
+		fmt.Println("有序:", r.TaskID, r.Value)
+	}
+
+	// FailFast：第一个错误出现后立即停止，其余任务被取消
+	failing := []Task[string]{
+		&FlakeyTask{id: "bad", failFor: 1},
+		&SleepTask{id: "slow-1", d: time.Second},
+		&SleepTask{id: "slow-2", d: time.Second},
+	}
+	start := time.Now()
+	results, err := pool.Collect(context.Background(), failing, StreamOptions{Ordered: true, FailFast: true})
+	fmt.Println("收集到:", len(results), "错误:", err, "提前返回:", time.Since(start) < 500*time.Millisecond)
+
+	// 队列容量不足时，提交失败的任务以 *SubmitError 返回，不再无限等待
+	var many []Task[string]
+	for i := 0; i < 12; i++ {
+		many = append(many, &SleepTask{id: fmt.Sprintf("s%d", i), d: 20 * time.Millisecond})
+	}
+	rejected := 0
+	all := pool.SubmitAndCollect(many)
+	for _, r := range all {
+		var se *SubmitError
+		if errors.As(r.Err, &se) {
+			rejected++
+		}
+	}
+	fmt.Println("返回结果数:", len(all), "有提交失败:", rejected > 0)
+
+	// Output:
+	// 有序: t0 h0
+	// 有序: t1 h1
+	// 有序: t2 h2
+	// 有序: t3 h3
+	// 收集到: 1 错误: 临时错误（第 1 次尝试） 提前返回: true
+	// 返回结果数: 12 有提交失败: true
+}
+
+// =============================================================================
+// 示例18：暂停/恢复取任务、Drain 排空与限时优雅停止
+// =============================================================================
+
+func Example_pauseResumeDrain() {
+	pool := NewPool[string](Options{Workers: 2, QueueSize: 10, Logger: func(string, ...any) {}})
+
+	// 暂停期间提交的任务留在队列中，不会被执行
+	pool.Pause()
+	for i := range 4 {
+		_, _ = pool.Submit(&SleepTask{id: fmt.Sprintf("job-%d", i), d: 10 * time.Millisecond})
+	}
+	time.Sleep(50 * time.Millisecond)
+	m := pool.Metrics()
+	fmt.Println("暂停中:", pool.Paused(), "队列积压:", m.QueueDepth, "已完成:", m.Succeeded)
+
+	// 暂停时 Drain 不会结束，到期返回 ctx 的错误
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	fmt.Println("暂停时 Drain:", pool.Drain(ctx))
+	cancel()
+
+	// 恢复后 Drain 等待全部任务结束，Pool 仍可继续使用
+	pool.Resume()
+	fmt.Println("恢复后 Drain:", pool.Drain(context.Background()), "已完成:", pool.Metrics().Succeeded)
+
+	// 限时优雅停止：慢任务超过截止时间后退化为 Stop()，剩余任务被取消
+	_, _ = pool.Submit(&SleepTask{id: "slow", d: time.Second})
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := pool.StopGracefulContext(ctx)
+	fmt.Println("优雅停止:", err, "提前返回:", time.Since(start) < 500*time.Millisecond)
+
+	// Output:
+	// 暂停中: true 队列积压: 4 已完成: 0
+	// 暂停时 Drain: context deadline exceeded
+	// 恢复后 Drain: <nil> 已完成: 4
+	// 优雅停止: context deadline exceeded 提前返回: true
+}
+
+// =============================================================================
+// 示例19：分布式 Coordinator/RemoteWorker（租约过期后重新分配）
+// =============================================================================
+
+// SquareTask 在远程 Worker 上计算平方。需要跨进程传输的字段必须导出。
+type SquareTask struct {
+	ID string `json:"id"`
+	N  int    `json:"n"`
+}
+
+func (t *SquareTask) TaskID() string { return t.ID }
+func (t *SquareTask) Run(ctx context.Context) (int, error) {
+	select {
+	case <-time.After(10 * time.Millisecond):
+		return t.N * t.N, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func Example_distributedWorkers() {
+	quiet := func(string, ...any) {}
+
+	// Coordinator 与 RemoteWorker 使用同一个 TaskRegistry（实际部署时在各自进程中注册）
+	reg := NewTaskRegistry[int]()
+	reg.Register("square", func() Task[int] { return &SquareTask{} })
+
+	coord := NewCoordinator[int](reg, CoordinatorOptions{VisibilityTimeout: 200 * time.Millisecond, Logger: quiet})
+	srv := httptest.NewServer(coord)
+	defer srv.Close()
+
+	var futs []*Future[int]
+	for i := 1; i <= 6; i++ {
+		f, _ := coord.Submit(&SquareTask{ID: fmt.Sprintf("sq-%d", i), N: i})
+		futs = append(futs, f)
+	}
+
+	// 模拟一个租到任务后崩溃的 Worker：租约过期后 sq-1 会被重新分配
+	resp, err := http.Post(srv.URL+"/lease", "application/json", strings.NewReader(`{"worker":"crashed","max":1}`))
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	// 启动两个远程 Worker（实际部署时是独立进程）
+	var wg sync.WaitGroup
+	for i := range 2 {
+		w := NewRemoteWorker[int](srv.URL, reg, RemoteWorkerOptions{
+			Options:  Options{Workers: 2, Logger: quiet},
+			ID:       fmt.Sprintf("worker-%d", i),
+			PollWait: 50 * time.Millisecond,
+		})
+		wg.Go(func() { _ = w.Run(context.Background()) })
+	}
+
+	sum := 0
+	for _, f := range futs {
+		r, _ := f.Wait(context.Background())
+		sum += r.Value
+		if r.TaskID == "sq-1" {
+			fmt.Println("sq-1 执行次数:", r.Attempts)
+		}
+	}
+	fmt.Println("平方和:", sum)
+
+	// 优雅停止后 RemoteWorker.Run 返回
+	coord.StopGraceful()
+	wg.Wait()
+	m := coord.Metrics()
+	fmt.Println("成功:", m.Succeeded, "租约过期重新入队:", m.Retried)
+
+	// Output:
+	// sq-1 执行次数: 2
+	// 平方和: 91
+	// 成功: 6 租约过期重新入队: 1
+}